@@ -5,31 +5,69 @@
 package main
 
 import (
-	"log"
+	"flag"
+	"fmt"
 	"os"
 
 	"shofar/internal/app"
 	"shofar/internal/hotkey"
+	"shofar/internal/logx"
 )
 
 // Version устанавливается при сборке через -ldflags.
 var Version = "dev"
 
 func main() {
-	log.SetFlags(log.Ltime | log.Lshortfile)
-	log.Printf("Shofar %s запускается...", Version)
+	transcribe := flag.String("transcribe", "", "распознать WAV файл, вывести текст и выйти (без трея)")
+	useLLM := flag.Bool("llm", false, "с -transcribe: исправить результат через LLM")
+	bench := flag.String("bench", "", "замерить скорость модели с этим ID на встроенном образце речи и выйти")
+	benchRuns := flag.Int("bench-runs", 5, "с -bench: число прогонов распознавания")
+	diagnose := flag.Bool("diagnose", false, "вывести выбранные программы ввода текста и буфера обмена и выйти")
+	safe := flag.Bool("safe", false, "запустить без загрузки моделей (если модель роняет приложение)")
+	flag.Parse()
+
+	logx.Init()
+
+	if *diagnose {
+		if err := app.Diagnose(os.Stdout); err != nil {
+			os.Exit(1)
+		}
+		return
+	}
+
+	if *bench != "" {
+		if err := app.Benchmark(os.Stdout, *bench, *benchRuns); err != nil {
+			logx.Error("Ошибка замера", "err", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	if *transcribe != "" {
+		text, err := app.TranscribeFile(*transcribe, *useLLM)
+		if err != nil {
+			logx.Error("Ошибка распознавания", "err", err)
+			os.Exit(1)
+		}
+		fmt.Println(text)
+		return
+	}
+
+	logx.Info("Shofar запускается", "version", Version)
 
 	// Запускаем в главном потоке (требование для macOS и некоторых GUI)
-	hotkey.RunOnMainThread(run)
+	hotkey.RunOnMainThread(func() {
+		run(app.Options{SafeMode: *safe})
+	})
 }
 
-func run() {
-	application, err := app.New()
+func run(opts app.Options) {
+	application, err := app.New(opts)
 	if err != nil {
-		log.Printf("Ошибка инициализации: %v", err)
+		logx.Error("Ошибка инициализации", "err", err)
 		os.Exit(1)
 	}
 
-	log.Println("Приложение запущено. Нажмите Ctrl+Shift+Space для записи.")
+	logx.Info("Приложение запущено. Нажмите Ctrl+Shift+Space для записи.")
 	application.Run()
 }
@@ -19,3 +19,69 @@ var IconRecording []byte
 //
 //go:embed icon_processing.png
 var IconProcessing []byte
+
+// IconPaused - иконка при отключённых горячих клавишах (приглушённая, со знаком паузы).
+//
+//go:embed icon_paused.png
+var IconPaused []byte
+
+// IconLoading - иконка во время загрузки модели (приглушённая, с точками).
+//
+//go:embed icon_loading.png
+var IconLoading []byte
+
+// IconError - иконка, когда модель не загрузилась (с красным знаком "!").
+//
+//go:embed icon_error.png
+var IconError []byte
+
+// IconIdleTemplate - монохромная иконка ожидания (template для строки меню macOS).
+//
+//go:embed icon_idle_template.png
+var IconIdleTemplate []byte
+
+// IconRecordingTemplate - монохромная иконка записи: микрофон в кольце.
+//
+//go:embed icon_recording_template.png
+var IconRecordingTemplate []byte
+
+// IconProcessingTemplate - монохромная иконка обработки: контур микрофона.
+//
+//go:embed icon_processing_template.png
+var IconProcessingTemplate []byte
+
+// IconPausedTemplate - монохромная иконка паузы горячих клавиш.
+//
+//go:embed icon_paused_template.png
+var IconPausedTemplate []byte
+
+// IconLoadingTemplate - монохромная иконка загрузки модели.
+//
+//go:embed icon_loading_template.png
+var IconLoadingTemplate []byte
+
+// IconErrorTemplate - монохромная иконка ошибки загрузки модели.
+//
+//go:embed icon_error_template.png
+var IconErrorTemplate []byte
+
+// SoundStart - звук начала записи.
+//
+//go:embed sound_start.wav
+var SoundStart []byte
+
+// SoundStop - звук остановки записи.
+//
+//go:embed sound_stop.wav
+var SoundStop []byte
+
+// SoundDone - звук успешного распознавания.
+//
+//go:embed sound_done.wav
+var SoundDone []byte
+
+// BenchSample - запись речи для замера скорости моделей (shofar -bench):
+// 11 секунд английской речи, 16 кГц моно. Образец из whisper.cpp (jfk.wav).
+//
+//go:embed bench_sample.wav
+var BenchSample []byte
@@ -4,18 +4,20 @@ go 1.23.8
 
 require (
 	gioui.org v0.9.0
+	github.com/alphacep/vosk-api/go v0.3.50
 	github.com/gen2brain/beeep v0.0.0-20240516210008-9c006672e7f4
 	github.com/getlantern/systray v1.2.2
 	github.com/ggerganov/whisper.cpp/bindings/go v0.0.0-00010101000000-000000000000
+	github.com/godbus/dbus/v5 v5.1.0
 	github.com/gordonklaus/portaudio v0.0.0-20230709114228-aafa478834f5
 	github.com/ncruces/zenity v0.10.14
 	golang.design/x/hotkey v0.4.1
+	golang.org/x/sys v0.33.0
 )
 
 require (
 	gioui.org/shader v1.0.8 // indirect
 	github.com/akavel/rsrc v0.10.2 // indirect
-	github.com/alphacep/vosk-api/go v0.3.50 // indirect
 	github.com/dchest/jsmin v0.0.0-20220218165748-59f39799265f // indirect
 	github.com/getlantern/context v0.0.0-20190109183933-c447772a6520 // indirect
 	github.com/getlantern/errors v0.0.0-20190325191628-abdb3e3e36f7 // indirect
@@ -26,7 +28,6 @@ require (
 	github.com/go-stack/stack v1.8.0 // indirect
 	github.com/go-text/typesetting v0.3.0 // indirect
 	github.com/go-toast/toast v0.0.0-20190211030409-01e6764cf0a4 // indirect
-	github.com/godbus/dbus/v5 v5.1.0 // indirect
 	github.com/josephspurrier/goversioninfo v1.4.1 // indirect
 	github.com/nu7hatch/gouuid v0.0.0-20131221200532-179d4d0c4d8d // indirect
 	github.com/oxtoacart/bpool v0.0.0-20190530202638-03653db5a59c // indirect
@@ -35,7 +36,6 @@ require (
 	golang.design/x/mainthread v0.3.0 // indirect
 	golang.org/x/exp/shiny v0.0.0-20250408133849-7e4ce0ab07d0 // indirect
 	golang.org/x/image v0.26.0 // indirect
-	golang.org/x/sys v0.33.0 // indirect
 	golang.org/x/text v0.24.0 // indirect
 )
 
@@ -3,68 +3,118 @@ package app
 
 import (
 	"context"
-	"log"
-	"os"
+	"errors"
+	"fmt"
+	"image"
 	"os/exec"
 	"strings"
 	"sync"
 	"time"
+	"unicode"
 
+	"shofar/embedded"
 	"shofar/internal/audio"
+	"shofar/internal/autostart"
 	"shofar/internal/config"
+	"shofar/internal/control"
+	"shofar/internal/dialog"
+	"shofar/internal/export"
 	"shofar/internal/hotkey"
 	"shofar/internal/i18n"
 	"shofar/internal/input"
 	"shofar/internal/llm"
+	"shofar/internal/logx"
 	"shofar/internal/models"
 	"shofar/internal/notify"
 	"shofar/internal/settings"
 	"shofar/internal/speech"
 	"shofar/internal/startup"
+	"shofar/internal/stats"
+	"shofar/internal/textproc"
+	"shofar/internal/theme"
+	"shofar/internal/tools"
 	"shofar/internal/tray"
 	"shofar/internal/waveform"
 )
 
-const (
-	// MinRecordingDuration - минимальная длительность записи для распознавания
-	MinRecordingDuration = 500 * time.Millisecond
-)
-
 // App представляет главное приложение.
 type App struct {
 	mu             sync.Mutex
+	modelMu        sync.Mutex // не даёт двум сменам модели идти одновременно
 	config         *config.Config
 	recorder       *audio.Recorder
 	modelManager   *models.Manager
 	speechFactory  *speech.Factory
 	llmModel       *llm.LlamaModel
 	llmModelID     string // ID текущей загруженной LLM модели
+	llmCtxSize     int    // размер контекста, с которым загружена LLM модель
 	typer          input.Typer
 	notifier       *notify.Notifier
 	tray           *tray.Tray
 	hotkey         *hotkey.Handler
+	cancelHotkey   *hotkey.Handler // отмена записи без распознавания
+	repeatHotkey   *hotkey.Handler // повторная вставка последнего текста
 	waveformWin    *waveform.Window
 	settingsWin    *settings.Window
 	startupWin     *startup.Window
+	controlServer  *control.Server    // nil если сервер управления выключен
+	cancelWork     context.CancelFunc // прерывает распознавание текущей сессии, nil вне обработки
 	recordingStart time.Time
-	processing     bool // защита от множественных событий
+	state          recordState     // этап записи, защищает от гонок при частых нажатиях
+	session        uint64          // номер текущей записи, растёт при каждом старте
+	paused         bool            // горячие клавиши отключены из трея
+	pauseHeld      bool            // на паузе клавиши записи оставлены до конца идущей записи
+	pauseMu        sync.Mutex      // упорядочивает регистрацию клавиш при паузе
+	langWarned     map[string]bool // модель+язык, о несовпадении которых уже предупреждали
+	dictationStop  chan struct{}   // nil если режим диктовки не активен
+	dictationDone  chan struct{}   // закрывается по завершении dictationLoop
+	onboarding     bool            // первый запуск: ни одной модели распознавания не скачано
+	safeMode       bool            // модели при запуске не загружаются
+	recordQueued   bool            // запись запрошена во время загрузки модели и начнётся после неё
+	crashedModel   string          // модель, на загрузке которой упал прошлый запуск
+	lastSamples    []float32       // последняя запись, хранится только при debug_keep_audio
+	history        []export.Entry  // распознанное за сессию, для экспорта
+	delivered      string          // последний выведенный текст, для повтора
+	stats          *stats.Store    // статистика диктовки, nil если выключена
+	closing        bool            // вызван Close: новые записи не начинаются
+	inflight       sync.WaitGroup  // обработка сессий, использующая распознаватель и LLM
+}
+
+// shutdownTimeout - сколько Close ждёт завершения идущего распознавания.
+const shutdownTimeout = 5 * time.Second
+
+// Options - параметры запуска приложения.
+type Options struct {
+	// SafeMode запускает трей и настройки без загрузки моделей, чтобы можно
+	// было заменить модель, которая роняет приложение при загрузке.
+	SafeMode bool
 }
 
 // New создаёт новое приложение.
-func New() (*App, error) {
+func New(opts Options) (*App, error) {
 	cfg := config.New()
+	logx.SetLevel(cfg.LogLevel())
 
 	// Инициализируем язык интерфейса из конфига
 	if uiLang := cfg.UILanguage(); uiLang != "" {
 		i18n.SetLanguage(i18n.Language(uiLang))
 	}
+	theme.Set(cfg.Theme())
 
+	// Без микрофона приложение всё равно запускается: запись станет
+	// доступна после успешной переинициализации (см. onHotkeyPress)
 	recorder, err := audio.New()
 	if err != nil {
-		return nil, err
+		logx.Error("Ошибка инициализации аудио", "err", err)
 	}
+	recorder.SetPadding(time.Duration(cfg.SilencePadMs()) * time.Millisecond)
+	recorder.SetSampleRate(cfg.InputSampleRate())
 
-	typer, err := input.New()
+	typer, err := input.New(input.Options{
+		ReplaceSelection: cfg.ReplaceSelection(),
+		RestoreFocus:     cfg.RestoreFocus(),
+		TargetClass:      cfg.TargetWindowClass(),
+	})
 	if err != nil {
 		recorder.Close()
 		return nil, err
@@ -76,11 +126,22 @@ func New() (*App, error) {
 		recorder.Close()
 		return nil, err
 	}
+	if err := modelManager.SetProxy(cfg.ProxyURL()); err != nil {
+		logx.Error("Ошибка настройки прокси", "err", err)
+	}
+	if err := modelManager.LoadCustom(cfg.CustomModelsPath()); err != nil {
+		logx.Error("Ошибка загрузки пользовательских моделей", "err", err)
+	}
 
 	// Создаём фабрику распознавателей
 	speechFactory := speech.NewFactory(modelManager)
+	speechFactory.SetThreads(cfg.Threads())
+	speechFactory.SetPrompt(cfg.WhisperPrompt())
+	speechFactory.SetWhisperParams(speech.WhisperPreset(cfg.RecognitionPreset()).Params())
 
 	notifier := notify.New(cfg.NotificationsEnabled())
+	notifier.SetStyle(notify.Style(cfg.NotificationStyle()))
+	notifier.SetMaxChars(cfg.NotifyMaxChars())
 
 	app := &App{
 		config:        cfg,
@@ -89,18 +150,47 @@ func New() (*App, error) {
 		speechFactory: speechFactory,
 		typer:         typer,
 		notifier:      notifier,
+		safeMode:      opts.SafeMode,
+	}
+
+	// Грамматика загружается до модели: модель Vosk создаётся уже с ней
+	app.applyVoskGrammar(cfg.VoskGrammarFile())
+
+	// Прошлый запуск упал во время загрузки модели - не повторяем её
+	if modelID, crashed := crashedLoad(); crashed {
+		logx.Warn("Прошлый запуск завершился сбоем при загрузке модели, безопасный режим", "model", modelID)
+		app.safeMode = true
+		app.crashedModel = modelID
+	}
+
+	// Первый запуск определяем по скачанным моделям: флаг в конфиге
+	// не даёт напоминать об этом после установки первой модели
+	if !cfg.Onboarded() {
+		if hasSpeechModel(modelManager) {
+			cfg.SetOnboarded()
+		} else {
+			app.onboarding = true
+		}
 	}
 
 	// Создаём окно визуализации (recorder реализует SampleProvider)
 	app.waveformWin = waveform.New(recorder, waveform.DefaultConfig())
+	app.waveformWin.SetSize(waveform.Size(cfg.WaveformSize()))
+	app.waveformWin.OnSizeChange(func(size waveform.Size) {
+		app.config.SetWaveformSize(config.WaveformSize(size))
+	})
 
 	// Callback для вставки текста (Enter или кнопка "Вставить")
 	app.waveformWin.OnInsert(func(text string) {
-		// Даём время на закрытие окна и переключение фокуса
-		time.Sleep(150 * time.Millisecond)
-		if err := app.typer.Type(text); err != nil {
-			log.Printf("Ошибка ввода текста: %v", err)
-			app.notifier.Error(i18n.T("error_input") + ": " + err.Error())
+		// Переводы строк редактора обрабатываются по настройке в момент вставки
+		text = app.config.NewlineHandling().Apply(text)
+		if app.config.OutputTarget() == config.OutputActiveWindow {
+			// Даём время на закрытие окна и переключение фокуса
+			time.Sleep(time.Duration(app.config.InsertDelayMs()) * time.Millisecond)
+		}
+		if err := app.deliver(text); err != nil {
+			logx.Error("Ошибка вывода текста", "err", err)
+			app.notifier.Error(err.Error())
 		} else {
 			app.notifier.Success(text)
 		}
@@ -109,55 +199,102 @@ func New() (*App, error) {
 
 	// Callback для копирования в буфер обмена
 	app.waveformWin.OnCopy(func(text string) {
+		text = app.config.NewlineHandling().Apply(text)
 		if err := copyToClipboard(text); err != nil {
-			log.Printf("Ошибка копирования в буфер: %v", err)
-			app.notifier.Error(i18n.T("error_clipboard"))
+			logx.Error("Ошибка копирования в буфер", "err", err)
+			app.notifier.Error(i18n.T("error_clipboard") + ": " + shortError(missingToolError(err)))
 		} else {
 			app.notifier.Success(text)
 		}
 		app.tray.SetState(tray.StateIdle)
 	})
 
+	// Восстанавливаем сохранённую позицию окна и запоминаем новую при закрытии
+	if pos, ok := cfg.WaveformPosition(); ok {
+		app.waveformWin.SetPosition(image.Pt(pos.X, pos.Y))
+	}
+	app.waveformWin.OnPositionChange(func(pos image.Point) {
+		app.config.SetWaveformPosition(config.WindowPosition{X: pos.X, Y: pos.Y})
+	})
+
+	// Ctrl+Backspace в окне результата: отбросить результат и записать заново
+	app.waveformWin.OnRedo(app.redoRecording)
+
 	// Callback для отмены (ESC или кнопка закрытия)
 	app.waveformWin.OnCancel(func() {
-		// Останавливаем запись если она идёт
-		if app.recorder.IsRecording() {
-			app.recorder.Stop()
+		// Останавливаем запись если она идёт, иначе прерываем распознавание
+		if !app.cancelRecording() {
+			app.cancelProcessing()
 		}
+		app.cancelQueuedRecording()
+		app.stopDictationLoop()
 		app.tray.SetState(tray.StateIdle)
-		app.mu.Lock()
-		app.processing = false
-		app.mu.Unlock()
 	})
 
 	// Создаём обработчик горячих клавиш
 	app.hotkey = hotkey.New(app.onHotkeyPress, app.onHotkeyRelease)
+	app.hotkey.SetDebounce(time.Duration(cfg.HotkeyDebounceMs())*time.Millisecond, cfg.HotkeyDetectRepeat())
+	app.hotkey.SetFollowupTimeout(time.Duration(cfg.HotkeyFollowupTimeoutMs()) * time.Millisecond)
+	app.cancelHotkey = hotkey.New(app.onCancelHotkeyPress, nil)
+	app.repeatHotkey = hotkey.New(app.onRepeatHotkeyPress, nil)
 
 	// Создаём окно настроек
 	app.settingsWin = settings.New(modelManager, cfg)
-	app.settingsWin.OnApply(func(modelID string) {
-		if err := app.speechFactory.Swap(modelID); err != nil {
-			log.Printf("Ошибка смены модели: %v", err)
-			app.notifier.Error(i18n.T("error_model_load"))
+	app.settingsWin.SetMicTester(recorder)
+	// При ошибке окно настроек покажет причину и предложит скачать модель заново
+	app.settingsWin.OnApply(app.SetActiveModel)
+	app.settingsWin.OnOnboarded(app.finishOnboarding)
+	// Клавиша перерегистрируется по callback конфига: и из настроек,
+	// и при перечитывании файла
+	app.settingsWin.OnHotkeyChange(app.config.SetHotkey)
+	cfg.OnHotkeyChange(func(hk config.HotkeyConfig) {
+		// На паузе клавиша зарегистрируется при её снятии
+		if app.isPaused() {
 			return
 		}
-		app.config.SetModelID(modelID)
-		app.notifier.Info(i18n.T("success_model_loaded"))
-	})
-	app.settingsWin.OnHotkeyChange(func(hk config.HotkeyConfig) {
-		app.config.SetHotkey(hk)
 		// Перерегистрируем горячую клавишу
 		if err := app.hotkey.Register(hk); err != nil {
-			log.Printf("Ошибка регистрации горячей клавиши: %v", err)
+			logx.Error("Ошибка регистрации горячей клавиши", "err", err)
 			app.notifier.Error(i18n.T("error_hotkey_register"))
 		}
 	})
+	app.settingsWin.OnCancelHotkeyChange(func(hk config.HotkeyConfig) {
+		app.config.SetCancelHotkey(hk)
+		if app.isPaused() {
+			return
+		}
+		if err := app.cancelHotkey.Register(hk); err != nil {
+			logx.Error("Ошибка регистрации клавиши отмены", "err", err)
+			app.notifier.Error(i18n.T("error_hotkey_register"))
+		}
+	})
+	app.settingsWin.OnThreadsChange(func(threads int) {
+		// LLM подхватит новое значение при следующей загрузке модели
+		app.speechFactory.SetThreads(threads)
+	})
+	app.settingsWin.OnPromptChange(app.speechFactory.SetPrompt)
+	app.settingsWin.OnGrammarChange(app.applyVoskGrammar)
+	app.settingsWin.OnRecognitionPresetChange(func(preset config.RecognitionPreset) {
+		app.speechFactory.SetWhisperParams(speech.WhisperPreset(preset).Params())
+	})
+	app.settingsWin.OnLLMSamplerChange(func(sampler config.LLMSampler) {
+		app.mu.Lock()
+		model := app.llmModel
+		app.mu.Unlock()
+		if model != nil {
+			model.SetSampler(llm.Sampler(sampler))
+		}
+	})
+	app.settingsWin.OnNotifyStyleChange(func(style config.NotificationStyle) {
+		app.notifier.SetStyle(notify.Style(style))
+	})
 	app.settingsWin.OnLLMChange(func(enabled bool, modelID string) {
 		if enabled {
 			// Проверяем нужно ли загрузить новую модель или сменить текущую
 			app.mu.Lock()
 			needLoad := app.llmModel == nil
-			needSwap := app.llmModel != nil && app.llmModelID != modelID
+			needSwap := app.llmModel != nil &&
+				(app.llmModelID != modelID || app.llmCtxSize != app.config.LLMContextSize())
 			app.mu.Unlock()
 
 			if needSwap {
@@ -188,23 +325,59 @@ func New() (*App, error) {
 	})
 
 	// Создаём системный трей с обработчиками
-	app.tray = tray.New(tray.Callbacks{
+	callbacks := tray.Callbacks{
 		OnNotificationsToggle: func() bool {
 			enabled := app.config.ToggleNotifications()
 			app.notifier.SetEnabled(enabled)
 			return enabled
 		},
+		OnPauseToggle:     app.togglePause,
+		OnModelSelect:     app.switchModel,
+		OnAutostartToggle: app.toggleAutostart,
 		OnSettingsClick: func() {
 			app.settingsWin.Show()
 		},
+		OnResetPosition: func() {
+			app.config.ResetWaveformPosition()
+			app.waveformWin.ResetPosition()
+		},
+		OnExport:       app.exportHistory,
+		OnReloadConfig: app.reloadConfig,
+		OnOpenLog: func() {
+			if err := logx.Open(); err != nil {
+				logx.Error("Ошибка открытия лога", "err", err)
+				app.notifier.Error(i18n.T("error_open_log") + ": " + shortError(err))
+			}
+		},
 		OnQuit: func() {
 			app.Close()
 		},
-	})
+	}
+	// Статистика и её пункт в трее - только если включены в настройках
+	if cfg.CollectStats() {
+		app.stats = stats.Open(stats.DefaultPath())
+		callbacks.OnStats = func() {
+			// Диалог блокирует - не задерживаем обработку меню
+			go app.showStats()
+		}
+	}
+	// Пункт сохранения записи появляется только в режиме отладки
+	if cfg.DebugKeepAudio() {
+		callbacks.OnSaveRecording = func() {
+			// Диалог блокирует - не задерживаем обработку меню
+			go app.saveLastRecording()
+		}
+	}
+	app.tray = tray.New(callbacks)
+	app.tray.SetIconStyle(tray.IconStyle(cfg.TrayIconStyle()))
 
 	// Callback для смены языка UI - обновляем трей
 	app.settingsWin.OnUILangChange(func(lang i18n.Language) {
 		app.tray.RefreshUI()
+		app.refreshStats()
+	})
+	app.settingsWin.OnThemeChange(func(name string) {
+		app.waveformWin.SetPalette(theme.Current())
 	})
 
 	return app, nil
@@ -216,19 +389,291 @@ func (a *App) Run() {
 		// Регистрируем горячую клавишу после инициализации трея
 		hk := a.config.Hotkey()
 		if err := a.hotkey.Register(hk); err != nil {
-			log.Printf("Ошибка регистрации горячей клавиши: %v", err)
+			logx.Error("Ошибка регистрации горячей клавиши", "err", err)
+		}
+		if err := a.cancelHotkey.Register(a.config.CancelHotkey()); err != nil {
+			logx.Error("Ошибка регистрации клавиши отмены", "err", err)
+		}
+		if err := a.registerRepeatHotkey(); err != nil {
+			logx.Error("Ошибка регистрации клавиши повтора", "err", err)
+		}
+
+		if !a.recorder.Available() {
+			a.notifier.Error(i18n.T("error_mic_unavailable"))
+		}
+
+		a.tray.SetAutostart(autostart.IsEnabled())
+		a.refreshStats()
+		a.checkTools()
+
+		if a.config.ControlServerEnabled() {
+			a.startControlServer()
+		}
+
+		// В безопасном режиме модели не загружаются: открываем настройки,
+		// чтобы пользователь удалил или сменил модель
+		if a.safeMode {
+			a.startSafeMode()
+			return
+		}
+
+		// Без моделей распознавания сразу предлагаем скачать модель,
+		// подобранную под этот компьютер
+		if a.onboarding {
+			sys := models.CurrentSysInfo()
+			modelID := models.Recommend(sys)
+			logx.Info("Рекомендуемая модель", "model", modelID, "cores", sys.Cores,
+				"available_mb", sys.AvailableMB, "mflops", int(sys.MFlops))
+			a.settingsWin.ShowWithOnboarding(modelID)
+			return
 		}
 
 		// Ленивая загрузка распознавателя в фоне
 		go a.loadRecognizer()
+
+		if a.config.CheckModelUpdates() {
+			go a.checkModelUpdates()
+		}
 	})
 }
 
+// finishOnboarding загружает первую модель, скачанную при первом запуске:
+// при старте загружать было нечего, и без этого первое нажатие горячей
+// клавиши сообщило бы, что модель не загружена.
+func (a *App) finishOnboarding(modelID string) {
+	logx.Info("Первая модель скачана", "model", modelID)
+	a.mu.Lock()
+	a.onboarding = false
+	a.mu.Unlock()
+	go a.loadRecognizer()
+}
+
+// startSafeMode сообщает о безопасном режиме и открывает настройки.
+func (a *App) startSafeMode() {
+	msg := i18n.T("notify_safe_mode")
+	if a.crashedModel != "" {
+		name := a.crashedModel
+		if info, ok := models.GetModel(a.crashedModel); ok {
+			name = info.Name
+		}
+		msg = i18n.T("notify_safe_mode_crash") + ": " + name
+	}
+	a.notifier.Info(msg)
+	a.settingsWin.Show()
+}
+
+// maxErrorLen - максимальная длина текста ошибки в уведомлении (в символах).
+const maxErrorLen = 120
+
+// shortError возвращает текст ошибки, обрезанный для уведомления.
+func shortError(err error) string {
+	msg := []rune(err.Error())
+	if len(msg) <= maxErrorLen {
+		return string(msg)
+	}
+	return string(msg[:maxErrorLen]) + "..."
+}
+
+// reloadConfig перечитывает config.json и применяет настройки, которые
+// компоненты получают только при запуске. Модели не перезагружаются:
+// для этого есть окно настроек.
+func (a *App) reloadConfig() {
+	cancelHotkey := a.config.CancelHotkey().String()
+	repeatHotkey := a.config.RepeatHotkey().String()
+	inputRate := a.config.InputSampleRate()
+	if err := a.config.Reload(); err != nil {
+		logx.Error("Ошибка перечитывания настроек", "err", err)
+		a.notifier.Error(i18n.T("error_config_reload") + ": " + shortError(err))
+		return
+	}
+	cfg := a.config
+
+	logx.SetLevel(cfg.LogLevel())
+	if uiLang := cfg.UILanguage(); uiLang != "" {
+		i18n.SetLanguage(i18n.Language(uiLang))
+	}
+	theme.Set(cfg.Theme())
+	a.waveformWin.SetPalette(theme.Current())
+	a.tray.RefreshUI()
+	a.refreshStats()
+	a.tray.SetIconStyle(tray.IconStyle(cfg.TrayIconStyle()))
+
+	a.recorder.SetPadding(time.Duration(cfg.SilencePadMs()) * time.Millisecond)
+	a.recorder.SetSampleRate(cfg.InputSampleRate())
+	// Частоту подбирают под новое устройство: PortAudio узнает о нём
+	// только после перезапуска
+	if cfg.InputSampleRate() != inputRate {
+		if err := a.recorder.Reinitialize(); err != nil {
+			logx.Warn("Микрофон недоступен", "err", err)
+		}
+	}
+	a.speechFactory.SetThreads(cfg.Threads())
+	a.speechFactory.SetPrompt(cfg.WhisperPrompt())
+	a.speechFactory.SetWhisperParams(speech.WhisperPreset(cfg.RecognitionPreset()).Params())
+	a.applyVoskGrammar(cfg.VoskGrammarFile())
+
+	a.notifier.SetEnabled(cfg.NotificationsEnabled())
+	a.notifier.SetStyle(notify.Style(cfg.NotificationStyle()))
+	a.notifier.SetMaxChars(cfg.NotifyMaxChars())
+	a.tray.SetNotifications(cfg.NotificationsEnabled())
+
+	// Интервал подавления дребезга применяется при регистрации клавиши;
+	// основная клавиша уже перерегистрирована, если сменилась
+	a.hotkey.SetDebounce(time.Duration(cfg.HotkeyDebounceMs())*time.Millisecond, cfg.HotkeyDetectRepeat())
+	a.hotkey.SetFollowupTimeout(time.Duration(cfg.HotkeyFollowupTimeoutMs()) * time.Millisecond)
+	if hk := cfg.CancelHotkey(); hk.String() != cancelHotkey && !a.isPaused() {
+		if err := a.cancelHotkey.Register(hk); err != nil {
+			logx.Error("Ошибка регистрации клавиши отмены", "err", err)
+			a.notifier.Error(i18n.T("error_hotkey_register"))
+		}
+	}
+	if cfg.RepeatHotkey().String() != repeatHotkey && !a.isPaused() {
+		if err := a.registerRepeatHotkey(); err != nil {
+			logx.Error("Ошибка регистрации клавиши повтора", "err", err)
+			a.notifier.Error(i18n.T("error_hotkey_register"))
+		}
+	}
+
+	logx.Info("Настройки перечитаны")
+	a.notifier.Info(i18n.T("notify_config_reloaded"))
+}
+
+// toggleAutostart включает или выключает запуск при входе в систему.
+// Возвращает фактическое состояние после переключения.
+func (a *App) toggleAutostart() bool {
+	var err error
+	if autostart.IsEnabled() {
+		err = autostart.Disable()
+	} else {
+		err = autostart.Enable()
+	}
+	if err != nil {
+		logx.Error("Ошибка изменения автозапуска", "err", err)
+		a.notifier.Error(i18n.T("error_autostart") + ": " + shortError(err))
+	}
+	return autostart.IsEnabled()
+}
+
+// refreshTrayModels обновляет подменю моделей в трее: скачанные модели
+// распознавания с отметкой текущей.
+func (a *App) refreshTrayModels() {
+	var items []tray.ModelItem
+	for _, m := range a.modelManager.ListDownloaded() {
+		if m.Engine == models.EngineLLM {
+			continue
+		}
+		items = append(items, tray.ModelItem{ID: m.ID, Name: models.EngineName(m.Engine) + " " + m.Name})
+	}
+	a.tray.SetModels(items, a.speechFactory.CurrentModelID())
+}
+
+// switchModel переключает модель распознавания из меню трея,
+// минуя окно настроек.
+func (a *App) switchModel(modelID string) {
+	// Ошибку SetActiveModel уже показала уведомлением
+	a.SetActiveModel(modelID)
+}
+
+// SetActiveModel делает modelID текущей моделью распознавания: проверяет,
+// что модель известна и скачана, загружает её вместо текущей, сохраняет
+// в настройках и сообщает уведомлением. Единая точка смены модели для окна
+// настроек, меню трея и сервера управления. Если модель уже загружена,
+// ничего не делает. Во время записи и распознавания модель не меняется
+// (errBusy). Ошибка загрузки показывается уведомлением и возвращается.
+// Блокирует на время загрузки модели.
+func (a *App) SetActiveModel(modelID string) error {
+	a.modelMu.Lock()
+	defer a.modelMu.Unlock()
+	// Трей сам переключает галочку, а модель могли только что скачать -
+	// подменю трея обновляем в любом случае
+	defer a.refreshTrayModels()
+
+	info, ok := models.GetModel(modelID)
+	if !ok {
+		return fmt.Errorf("неизвестная модель %q", modelID)
+	}
+	if info.Engine == models.EngineLLM {
+		return fmt.Errorf("модель %q не распознаёт речь", modelID)
+	}
+	if !a.modelManager.IsDownloaded(info) {
+		return fmt.Errorf("модель %q не скачана", modelID)
+	}
+	if modelID == a.speechFactory.CurrentModelID() && a.speechFactory.IsLoaded() {
+		return nil
+	}
+	// Замена закрывает текущий распознаватель, а запись уже взяла его
+	// для распознавания
+	if a.currentState() != stateIdle {
+		a.notifier.Error(i18n.T("error_model_busy"))
+		return errBusy
+	}
+	// Прежняя модель выгружается только после загрузки новой, поэтому
+	// в пике в памяти обе: прежняя уже вычтена из свободной памяти,
+	// и новой должно хватить оставшегося
+	if !a.confirmMemory(info) {
+		return fmt.Errorf("модели %q не хватит памяти", modelID)
+	}
+
+	if err := a.speechFactory.Swap(modelID); err != nil {
+		logx.Error("Ошибка смены модели", "model", modelID, "err", err)
+		a.notifier.Error(i18n.T("error_model_load") + ": " + shortError(err))
+		return err
+	}
+	a.config.SetEngine(string(info.Engine))
+	a.config.SetModelID(modelID)
+	// Снимаем ошибку загрузки прежней модели с иконки трея
+	if a.currentState() == stateIdle {
+		a.tray.SetState(tray.StateIdle)
+	}
+	a.notifier.Info(i18n.T("success_model_loaded") + ": " + info.Name)
+	return nil
+}
+
+// applyVoskGrammar ограничивает распознавание Vosk фразами из JSON файла
+// path ("" - без ограничений). Некорректный файл не применяется: Vosk
+// распознаёт без ограничений, а причина показывается уведомлением.
+func (a *App) applyVoskGrammar(path string) {
+	var phrases []string
+	if path != "" {
+		var err error
+		phrases, err = speech.LoadGrammar(path)
+		if err != nil {
+			logx.Error("Ошибка загрузки грамматики Vosk", "path", path, "err", err)
+			a.notifier.Error(i18n.T("error_vosk_grammar") + ": " + shortError(err))
+		}
+	}
+	if err := a.speechFactory.SetVoskGrammar(phrases); err != nil {
+		logx.Error("Ошибка применения грамматики Vosk", "err", err)
+		a.notifier.Error(i18n.T("error_vosk_grammar") + ": " + shortError(err))
+	}
+}
+
+// hasSpeechModel возвращает true если скачана хотя бы одна модель распознавания.
+func hasSpeechModel(manager *models.Manager) bool {
+	for _, m := range manager.ListDownloaded() {
+		if m.Engine != models.EngineLLM {
+			return true
+		}
+	}
+	return false
+}
+
 func (a *App) loadRecognizer() {
+	// Иконка трея показывает загрузку, а после неё - готовность или ошибку.
+	// Отложенная запись могла начаться до конца загрузки LLM - тогда
+	// иконку записи не трогаем
+	trayState := tray.StateIdle
+	defer func() {
+		if a.currentState() == stateIdle {
+			a.tray.SetState(trayState)
+		}
+	}()
+
 	// Определяем какую модель загружать
 	modelID := a.config.ModelID()
 	if modelID == "" {
-		modelID = models.DefaultModelID()
+		engine := models.PreferredEngine(a.config.Engine(), "")
+		modelID = models.DefaultModelIDForEngine(engine)
 	}
 
 	info, ok := models.GetModel(modelID)
@@ -240,23 +685,57 @@ func (a *App) loadRecognizer() {
 	// Проверяем скачана ли модель
 	if !a.modelManager.IsDownloaded(info) {
 		a.notifier.Info(i18n.T("error_model_not_downloaded"))
+		a.runQueuedRecording()
 		return
 	}
 
 	// Показываем окно загрузки
-	a.startupWin = startup.New()
-	a.startupWin.SetStatus(i18n.T("startup_loading"), info.Name)
+	a.tray.SetState(tray.StateLoading)
+	a.startupWindow().SetStatus(i18n.T("startup_loading"), info.Name)
 	a.startupWin.Show()
 
-	// Загружаем модель
-	if err := a.speechFactory.Load(modelID); err != nil {
-		log.Printf("Ошибка загрузки модели: %v", err)
+	// Модель, которой не хватит памяти, система завершит вместе с приложением
+	if !a.confirmMemory(info) {
+		a.startupWin.Hide()
+		a.runQueuedRecording()
+		return
+	}
+
+	// Недокачанный файл старых версий может уронить движок при загрузке,
+	// поэтому сначала проверяем его и предлагаем скачать заново
+	if err := a.modelManager.Verify(info); err != nil {
+		logx.Error("Модель не прошла проверку", "model", modelID, "err", err)
+		trayState = tray.StateError
 		a.startupWin.Hide()
-		a.notifier.Error(i18n.T("error_model_load"))
+		a.notifier.Error(i18n.T("error_model_corrupt"))
+		a.settingsWin.ShowModelError(modelID, err)
+		a.runQueuedRecording()
+		return
+	}
+
+	// Загружаем модель. Метка на время загрузки: если движок уронит
+	// процесс, следующий запуск пройдёт в безопасном режиме
+	beginLoad(modelID)
+	err := a.speechFactory.Load(modelID)
+	endLoad()
+	if err != nil {
+		logx.Error("Ошибка загрузки модели", "err", err)
+		trayState = tray.StateError
+		a.startupWin.Hide()
+		a.notifier.Error(i18n.T("error_model_load") + ": " + shortError(err))
+		// Скачанный файл не загрузился - скорее всего он повреждён,
+		// открываем настройки на этой модели с предложением скачать её заново
+		a.settingsWin.ShowModelError(modelID, err)
+		a.runQueuedRecording()
 		return
 	}
 
 	a.config.SetModelID(modelID)
+	a.refreshTrayModels()
+
+	// Нажатие во время загрузки отложило запись - начинаем её, как только
+	// готова модель распознавания, не дожидаясь загрузки LLM
+	a.runQueuedRecording()
 
 	// Загружаем LLM модель если коррекция включена
 	if a.config.LLMEnabled() {
@@ -268,6 +747,18 @@ func (a *App) loadRecognizer() {
 	a.notifier.Info(i18n.T("notify_ready"))
 }
 
+// startupWindow возвращает окно загрузки, создавая его при первом обращении.
+// Окно переиспользуется, чтобы повторная загрузка не оставила
+// предыдущее окно висеть без владельца.
+func (a *App) startupWindow() *startup.Window {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.startupWin == nil {
+		a.startupWin = startup.New()
+	}
+	return a.startupWin
+}
+
 func (a *App) loadLLMModel() {
 	a.loadLLMModelInternal(false)
 }
@@ -294,52 +785,124 @@ func (a *App) loadLLMModelInternal(updateStatus bool) {
 		return
 	}
 
-	// Обновляем статус в окне загрузки
-	if updateStatus && a.startupWin != nil {
-		a.startupWin.SetStatus(i18n.T("startup_loading_llm"), info.Name)
+	if !a.confirmMemory(info) {
+		return
+	}
+
+	// Большая модель грузится долго: показываем прогресс в окне загрузки.
+	// При запуске окно уже открыто, иначе оно открывается только на время
+	// загрузки LLM
+	win := a.startupWindow()
+	win.SetStatus(i18n.T("startup_loading_llm"), info.Name)
+	if !updateStatus {
+		win.Show()
+		defer win.Hide()
 	}
 
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	win.SetProgress(0)
+	win.SetCancel(cancel)
+	defer func() {
+		win.SetCancel(nil)
+		win.SetProgress(-1)
+	}()
+
 	modelPath := a.modelManager.GetModelPath(info)
-	model, err := llm.NewLlamaModel(modelPath, 2048)
+	ctxSize := a.config.LLMContextSize()
+	beginLoad(modelID)
+	model, err := llm.NewLlamaModelCtx(ctx, modelPath, ctxSize, a.config.Threads(), a.config.LLMGPULayers(), win.SetProgress)
+	endLoad()
+	if errors.Is(err, context.Canceled) {
+		logx.Info("Загрузка LLM модели отменена", "model", modelID)
+		return
+	}
 	if err != nil {
-		log.Printf("Ошибка загрузки LLM модели: %v", err)
+		logx.Error("Ошибка загрузки LLM модели", "err", err)
 		if !updateStatus {
 			a.notifier.Error(i18n.T("error_llm_load"))
 		}
 		return
 	}
+	model.SetSampler(llm.Sampler(a.config.LLMSampler()))
 
 	a.mu.Lock()
+	// Модель загрузилась уже после Close - освобождать её больше некому
+	if a.closing {
+		a.mu.Unlock()
+		model.Close()
+		return
+	}
 	// Закрываем старую модель если была
 	if a.llmModel != nil {
 		a.llmModel.Close()
 	}
 	a.llmModel = model
 	a.llmModelID = modelID
+	a.llmCtxSize = ctxSize
 	a.mu.Unlock()
 }
 
 func (a *App) onHotkeyPress() {
+	// На паузе клавиша остаётся только для остановки идущей записи
 	a.mu.Lock()
-
-	// Toggle режим: если идёт запись - останавливаем
-	if a.recorder.IsRecording() {
-		a.mu.Unlock()
-		a.stopRecording()
+	ignore := a.paused && a.state != stateRecording
+	a.mu.Unlock()
+	if ignore {
 		return
 	}
+	a.toggleRecording(true)
+}
+
+// redoRecording отбрасывает показанный результат и сразу начинает новую
+// запись в том же окне. Фокус не сохраняется заново: сейчас активно окно
+// результата, а вставлять нужно туда, где пользователь печатал до записи.
+func (a *App) redoRecording() {
+	logx.Debug("Результат отброшен, повторная запись")
+	a.toggleRecording(false)
+}
 
-	if a.processing {
+// toggleRecording начинает запись или останавливает идущую.
+// saveFocus запоминает активное окно как цель ввода.
+func (a *App) toggleRecording(saveFocus bool) {
+	a.mu.Lock()
+
+	switch a.decidePress(time.Now()) {
+	case pressIgnore:
+		a.mu.Unlock()
+		return
+	case pressStop:
 		a.mu.Unlock()
+		a.stopRecording()
 		return
 	}
 
-	// Проверяем что модель загружена
+	// Проверяем что модель загружена. Если она ещё загружается,
+	// запись откладывается до конца загрузки
 	if !a.speechFactory.IsLoaded() {
+		if a.speechFactory.IsLoading() {
+			a.queueRecording(saveFocus)
+			return
+		}
 		a.mu.Unlock()
 		a.notifier.Error(i18n.T("error_model_loading"))
 		return
 	}
+
+	// Микрофон мог появиться после запуска - пробуем переинициализировать
+	if !a.recorder.Available() {
+		if err := a.recorder.Reinitialize(); err != nil {
+			logx.Warn("Микрофон недоступен", "err", err)
+			a.mu.Unlock()
+			a.notifier.Error(i18n.T("error_mic_unavailable"))
+			return
+		}
+	}
+	// Окно, в котором пользователь печатал, до появления окна записи
+	if saveFocus {
+		a.typer.SaveFocus()
+	}
+
 	a.recordingStart = time.Now()
 	a.tray.SetState(tray.StateRecording)
 	a.notifier.Recording()
@@ -347,51 +910,289 @@ func (a *App) onHotkeyPress() {
 	// Очищаем предыдущий результат
 	a.waveformWin.ClearResult()
 
+	// Сигнал играет одновременно с записью, а его длительность
+	// отбрасывается с начала записи, чтобы сигнал не попал в неё
+	a.recorder.SetLeadSkip(a.startCueSkip())
+	a.playCue(embedded.SoundStart)
+
 	if err := a.recorder.Start(); err != nil {
-		log.Printf("Ошибка начала записи: %v", err)
+		logx.Error("Ошибка начала записи", "err", err)
 		a.notifier.Error(i18n.T("error_recording") + ": " + err.Error())
 		a.tray.SetState(tray.StateIdle)
 		a.mu.Unlock()
 		return
 	}
 
+	a.beginSession()
+
 	// Показываем окно визуализации
 	a.waveformWin.SetStartTime(a.recordingStart)
+	a.waveformWin.SetClickThrough(a.config.WaveformClickThrough())
+	a.waveformWin.SetNoStealFocus(a.config.NoStealFocus())
+	a.waveformWin.SetSelectAll(a.config.ResultSelectAll())
+	showWindow := a.config.ShowWaveform()
+	if showWindow {
+		a.waveformWin.Show()
+	}
+
+	// В режиме диктовки фразы распознаются и вставляются по ходу записи
+	if a.config.DictationMode() {
+		a.dictationStop = make(chan struct{})
+		a.dictationDone = make(chan struct{})
+		go a.dictationLoop(a.dictationStop, a.dictationDone)
+	} else if a.config.PartialResults() && showWindow {
+		// Промежуточный текст показывается только в окне записи
+		a.startPartials(a.session)
+	}
+
+	a.mu.Unlock()
+}
+
+// queueRecording откладывает запись до окончания загрузки модели и
+// показывает ожидание в окне записи. Повторное нажатие снимает отложенную
+// запись. Вызывается под a.mu, мьютекс освобождает.
+func (a *App) queueRecording(saveFocus bool) {
+	if a.recordQueued {
+		a.recordQueued = false
+		a.mu.Unlock()
+		logx.Debug("Отложенная запись отменена")
+		a.waveformWin.Hide()
+		return
+	}
+	a.recordQueued = true
+	if saveFocus {
+		a.typer.SaveFocus()
+	}
+	a.mu.Unlock()
+
+	logx.Debug("Модель загружается, запись отложена")
+	if !a.config.ShowWaveform() {
+		return
+	}
+	a.waveformWin.ClearResult()
+	a.waveformWin.SetNoStealFocus(a.config.NoStealFocus())
 	a.waveformWin.Show()
+	a.waveformWin.SetState(waveform.StateModelLoading)
+}
 
+// runQueuedRecording начинает отложенную запись после загрузки модели.
+// Если модель не загрузилась, окно ожидания закрывается.
+func (a *App) runQueuedRecording() {
+	a.mu.Lock()
+	queued := a.recordQueued
+	a.recordQueued = false
 	a.mu.Unlock()
+	if !queued {
+		return
+	}
+
+	if !a.speechFactory.IsLoaded() {
+		a.waveformWin.Hide()
+		return
+	}
+	// Фокус сохранён при нажатии: сейчас активно окно ожидания
+	a.toggleRecording(false)
 }
 
 func (a *App) onHotkeyRelease() {
 	// В toggle режиме игнорируем keyup события
 }
 
+// togglePause временно отключает горячие клавиши (например, на время игры
+// с тем же сочетанием) или регистрирует их снова. Идущая запись не прерывается:
+// клавиши записи и отмены снимаются только после её окончания, чтобы ими
+// можно было её остановить.
+func (a *App) togglePause() bool {
+	a.pauseMu.Lock()
+	defer a.pauseMu.Unlock()
+
+	a.mu.Lock()
+	a.paused = !a.paused
+	paused := a.paused
+	a.pauseHeld = paused && a.state == stateRecording
+	held := a.pauseHeld
+	a.mu.Unlock()
+
+	if paused {
+		if !held {
+			a.hotkey.Unregister()
+			a.cancelHotkey.Unregister()
+		}
+		a.repeatHotkey.Unregister()
+		return true
+	}
+
+	if err := a.hotkey.Register(a.config.Hotkey()); err != nil {
+		logx.Error("Ошибка регистрации горячей клавиши", "err", err)
+		a.notifier.Error(i18n.T("error_hotkey_register"))
+	}
+	if err := a.cancelHotkey.Register(a.config.CancelHotkey()); err != nil {
+		logx.Error("Ошибка регистрации клавиши отмены", "err", err)
+	}
+	if err := a.registerRepeatHotkey(); err != nil {
+		logx.Error("Ошибка регистрации клавиши повтора", "err", err)
+	}
+	return false
+}
+
+// releasePausedHotkeys снимает клавиши записи, оставленные на паузе ради
+// идущей записи. Вызывается под a.mu, когда запись закончилась.
+func (a *App) releasePausedHotkeys() {
+	if !a.pauseHeld {
+		return
+	}
+	a.pauseHeld = false
+	// Снимаем в горутине: запись могла остановить сама клавиша, а её
+	// обработчик не должен ждать собственного снятия
+	go func() {
+		a.pauseMu.Lock()
+		defer a.pauseMu.Unlock()
+		a.mu.Lock()
+		paused := a.paused
+		a.mu.Unlock()
+		if paused {
+			a.hotkey.Unregister()
+			a.cancelHotkey.Unregister()
+		}
+	}()
+}
+
+func (a *App) isPaused() bool {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.paused
+}
+
+// startControlServer запускает локальный HTTP сервер управления записью.
+func (a *App) startControlServer() {
+	token, err := a.config.ControlToken()
+	if err != nil {
+		logx.Error("Ошибка генерации токена сервера управления", "err", err)
+		return
+	}
+
+	server := control.New(a.config.ControlServerPort(), token, control.Callbacks{
+		OnStart: func() error {
+			if a.currentState() == stateRecording {
+				return nil
+			}
+			a.onHotkeyPress()
+			if a.currentState() != stateRecording {
+				return errors.New("запись не началась")
+			}
+			return nil
+		},
+		OnStop: func() error {
+			a.stopRecording()
+			return nil
+		},
+		OnModel: a.SetActiveModel,
+		Status:  a.controlStatus,
+	})
+	if err := server.Start(); err != nil {
+		logx.Error("Ошибка запуска сервера управления", "err", err)
+		return
+	}
+
+	a.mu.Lock()
+	a.controlServer = server
+	a.mu.Unlock()
+}
+
+// controlStatus возвращает состояние приложения для сервера управления.
+func (a *App) controlStatus() control.Status {
+	return control.Status{
+		State: a.currentState().String(),
+		Model: a.speechFactory.CurrentModelID(),
+	}
+}
+
+// onCancelHotkeyPress прерывает запись без распознавания.
+func (a *App) onCancelHotkeyPress() {
+	if !a.cancelRecording() {
+		return
+	}
+	a.stopDictationLoop()
+
+	a.waveformWin.Hide()
+	a.tray.SetState(tray.StateIdle)
+}
+
+// warnLanguageMismatch однократно предупреждает, если текущая модель
+// не поддерживает язык распознавания. Распознавание не блокируется.
+func (a *App) warnLanguageMismatch() {
+	info, ok := models.GetModel(a.speechFactory.CurrentModelID())
+	if !ok {
+		return
+	}
+	lang := a.config.Language()
+	if info.SupportsLanguage(lang) {
+		return
+	}
+
+	a.mu.Lock()
+	key := info.ID + ":" + lang
+	if a.langWarned[key] {
+		a.mu.Unlock()
+		return
+	}
+	if a.langWarned == nil {
+		a.langWarned = make(map[string]bool)
+	}
+	a.langWarned[key] = true
+	a.mu.Unlock()
+
+	logx.Warn("Модель не поддерживает язык", "model", info.ID, "lang", lang)
+	a.notifier.Info(i18n.T("warning_language_mismatch") + ": " + info.Name + " (" + lang + ")")
+}
+
 func (a *App) stopRecording() {
 	a.mu.Lock()
 
-	if !a.recorder.IsRecording() || a.processing {
+	if a.state != stateRecording {
 		a.mu.Unlock()
 		return
 	}
 
-	a.processing = true
-	elapsed := time.Since(a.recordingStart)
+	ctx, session := a.startProcessing()
+	start := a.recordingStart
+	elapsed := time.Since(start)
 	recognizer := a.speechFactory.Current()
+	dictationStop, dictationDone := a.dictationStop, a.dictationDone
+	a.dictationStop, a.dictationDone = nil, nil
 	a.mu.Unlock()
 
+	if dictationStop != nil {
+		a.finishDictation(ctx, dictationStop, dictationDone, session)
+		return
+	}
+
+	a.warnLanguageMismatch()
+
 	// Переключаем окно в режим распознавания речи
 	a.waveformWin.SetState(waveform.StateSpeechProcess)
 
 	// Теперь безопасно останавливаем запись
 	samples := a.recorder.Stop()
+	// Сигнал остановки - после закрытия микрофона, чтобы не попасть в запись
+	a.playCue(embedded.SoundStop)
+	a.keepSamples(samples)
+	muted := audio.IsMuted(samples)
+	// Шум оценивается по паузам в записи, поэтому до обрезки тишины
+	if a.config.Denoise() {
+		samples = audio.Denoise(samples)
+	}
+	if a.config.TrimSilence() {
+		samples = audio.TrimSilence(samples, audio.SilenceThreshold)
+	}
+	// После обрезки запись может стать короче минимума для Whisper
+	samples = a.padSilence(samples)
 
 	// Проверяем минимальную длительность записи
-	if elapsed < MinRecordingDuration {
+	if elapsed < a.minRecordingDuration() {
 		a.waveformWin.Hide()
 		a.tray.SetState(tray.StateIdle)
-		a.mu.Lock()
-		a.processing = false
-		a.mu.Unlock()
+		a.finishSession(session)
 		return
 	}
 
@@ -402,9 +1203,7 @@ func (a *App) stopRecording() {
 		a.notifier.Error(i18n.T("error_model_not_loaded"))
 		a.waveformWin.Hide()
 		a.tray.SetState(tray.StateIdle)
-		a.mu.Lock()
-		a.processing = false
-		a.mu.Unlock()
+		a.finishSession(session)
 		return
 	}
 
@@ -412,23 +1211,37 @@ func (a *App) stopRecording() {
 		a.notifier.Empty()
 		a.waveformWin.Hide()
 		a.tray.SetState(tray.StateIdle)
-		a.mu.Lock()
-		a.processing = false
-		a.mu.Unlock()
+		a.finishSession(session)
+		return
+	}
+
+	// Запись без единого звука - распознавать нечего, подсказываем
+	// проверить микрофон вместо общего «не удалось распознать»
+	if muted {
+		logx.Warn("Запись без звука, микрофон выключен?", "samples", len(samples))
+		a.notifier.Muted()
+		a.waveformWin.Hide()
+		a.tray.SetState(tray.StateIdle)
+		a.finishSession(session)
 		return
 	}
 
 	// Распознаём в отдельной горутине
 	go func() {
-		defer func() {
-			a.mu.Lock()
-			a.processing = false
-			a.mu.Unlock()
-		}()
+		defer a.finishSession(session)
+
+		// Длительность этапов - для строки в окне результата (show_timings)
+		timings := waveform.Timings{Recording: elapsed}
+		asrStart := time.Now()
 
 		lang := a.config.Language()
-		originalText, err := recognizer.Transcribe(samples, lang)
+		originalText, confidence, err := a.recognize(ctx, recognizer, samples, lang)
 
+		if ctx.Err() != nil {
+			// Отменено пользователем - окно уже закрыто
+			logx.Info("Распознавание отменено")
+			return
+		}
 		if err != nil {
 			a.notifier.Error(i18n.T("error_recognition"))
 			a.waveformWin.Hide()
@@ -436,12 +1249,44 @@ func (a *App) stopRecording() {
 			return
 		}
 
+		// Фантомные фразы Whisper на тишине считаем пустым результатом
+		if speech.IsHallucination(originalText, a.config.HallucinationBlocklist()) {
+			logx.Debug("Отброшена галлюцинация распознавания", "text", originalText)
+			originalText = ""
+		}
+
+		// При автоопределении языка пустой результат часто исправляет
+		// повтор с явно заданным языком
+		if retryLang := a.retryLanguage(lang); originalText == "" && retryLang != "" {
+			logx.Debug("Пустой результат, повтор распознавания", "lang", retryLang)
+			originalText, confidence, err = a.recognize(ctx, recognizer, samples, retryLang)
+			if ctx.Err() != nil {
+				logx.Info("Распознавание отменено")
+				return
+			}
+			if err != nil || speech.IsHallucination(originalText, a.config.HallucinationBlocklist()) {
+				originalText = ""
+			}
+		}
+
+		// Одинокие «.» или «…» на почти тихой записи вставлять незачем
+		if !hasMeaningfulContent(originalText) {
+			originalText = ""
+		}
+
+		// Одно случайное слово от нечаянного нажатия тоже считаем пустым результатом
+		if minWords := a.config.MinWords(); originalText != "" && countWords(originalText) < minWords {
+			logx.Debug("Отброшен слишком короткий результат", "text", originalText, "min_words", minWords)
+			originalText = ""
+		}
+
 		if originalText == "" {
 			a.notifier.Empty()
 			a.waveformWin.Hide()
 			a.tray.SetState(tray.StateIdle)
 			return
 		}
+		timings.Recognition = time.Since(asrStart)
 
 		correctedText := ""
 
@@ -449,29 +1294,307 @@ func (a *App) stopRecording() {
 		if a.config.LLMEnabled() && a.llmModel != nil {
 			// Переключаем окно в режим LLM обработки
 			a.waveformWin.SetState(waveform.StateLLMProcess)
-
-			ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
-			corrected, err := a.llmModel.CorrectText(ctx, originalText)
-			cancel()
-			if err == nil && corrected != "" {
-				correctedText = corrected
+			llmStart := time.Now()
+			correctedText = a.correctText(ctx, originalText)
+			if ctx.Err() != nil {
+				logx.Info("Коррекция отменена")
+				return
 			}
+			timings.Correction = time.Since(llmStart)
+		}
+		logx.Debug("Длительность этапов", "timings", timings.String())
+		if a.config.ShowTimings() {
+			a.waveformWin.SetTimings(timings)
 		}
 
-		a.waveformWin.SetResult(originalText, correctedText)
+		lowConfidence := confidence != speech.NoConfidence && confidence < speech.LowConfidenceThreshold
+		a.waveformWin.SetLowConfidence(lowConfidence)
+		originalText = a.transformText(originalText)
+		correctedText = a.transformText(correctedText)
+		// Вывод внешней команды показывается как итоговый вариант,
+		// исходный текст остаётся на вкладке оригинала
+		if out := runPostCommand(ctx, a.config.PostCommand(), originalText, correctedText); out != "" {
+			correctedText = out
+		}
+		if ctx.Err() != nil {
+			logx.Info("Обработка отменена")
+			return
+		}
+		switch {
+		case a.config.ShowWaveform():
+			a.waveformWin.SetResult(originalText, correctedText)
+		case a.config.Express():
+			// Экспресс-режиму доверяют: окно не открывается и при низкой
+			// уверенности, только если вывести текст не удалось
+			a.deliverResult(originalText, correctedText, false)
+		default:
+			a.deliverResult(originalText, correctedText, lowConfidence)
+		}
+		finalText := originalText
+		if correctedText != "" {
+			finalText = correctedText
+		}
+		a.remember(start, elapsed, finalText)
+		a.countStats(finalText, elapsed)
 		a.tray.SetState(tray.StateIdle)
+		a.playCue(embedded.SoundDone)
 		// Окно остаётся открытым - пользователь закроет его сам или нажмёт копировать
 	}()
 }
 
-// Close освобождает ресурсы приложения.
+// deliverResult выводит результат без окна записи (show_waveform выключен)
+// и сообщает о нём уведомлением. Окно результата всё же открывается, если
+// результат нужно проверить (низкая уверенность) или вывести его не удалось:
+// оттуда текст можно поправить, вставить или скопировать.
+func (a *App) deliverResult(original, corrected string, lowConfidence bool) {
+	if lowConfidence {
+		a.waveformWin.ShowResult(original, corrected)
+		return
+	}
+	text := corrected
+	if text == "" {
+		text = original
+	}
+	text = a.config.NewlineHandling().Apply(text)
+	if err := a.deliver(text); err != nil {
+		logx.Error("Ошибка вывода текста", "err", err)
+		a.notifier.Error(err.Error())
+		a.waveformWin.ShowResult(original, corrected)
+		return
+	}
+	a.notifier.Success(text)
+}
+
+// recognize распознаёт запись целиком, а запись длиннее chunk_after_seconds -
+// по перекрывающимся кускам: движок не держит в памяти всю запись, и
+// распознанный текст появляется в окне по мере готовности.
+func (a *App) recognize(ctx context.Context, rec speech.Recognizer, samples []float32, lang string) (string, float64, error) {
+	limit := a.config.ChunkAfterSeconds()
+	if limit <= 0 || len(samples) <= limit*audio.SampleRate {
+		return speech.TranscribeWithConfidence(ctx, rec, samples, lang)
+	}
+
+	logx.Debug("Распознавание по кускам", "seconds", len(samples)/audio.SampleRate)
+	blocklist := a.config.HallucinationBlocklist()
+	return speech.TranscribeChunked(ctx, rec, samples, lang, speech.ChunkOptions{
+		Drop: func(text string) bool {
+			return speech.IsHallucination(text, blocklist)
+		},
+		OnProgress: a.waveformWin.SetPartial,
+	})
+}
+
+// countWords считает слова текста: отдельно стоящая пунктуация
+// («...», «—») словом не считается.
+func countWords(text string) int {
+	n := 0
+	for _, word := range strings.Fields(text) {
+		if hasMeaningfulContent(word) {
+			n++
+		}
+	}
+	return n
+}
+
+// hasMeaningfulContent возвращает true, если в тексте есть хотя бы одна
+// буква или цифра любого алфавита: результат из одних пробелов и знаков
+// препинания считается пустым.
+func hasMeaningfulContent(text string) bool {
+	return strings.IndexFunc(text, func(r rune) bool {
+		return unicode.IsLetter(r) || unicode.IsNumber(r)
+	}) >= 0
+}
+
+// playCue проигрывает звуковой сигнал, если они включены в настройках.
+// Не блокирует: звук не задерживает запись и распознавание.
+func (a *App) playCue(sound []byte) {
+	if a.config.SoundCues() {
+		audio.Play(sound)
+	}
+}
+
+// cueLatency - запас на задержку вывода звука: сигнал начинает звучать
+// позже, чем открывается микрофон.
+const cueLatency = 100 * time.Millisecond
+
+// startCueSkip возвращает, сколько отбросить с начала записи, чтобы в неё
+// не попал сигнал начала записи. Без звуковых сигналов - 0.
+func (a *App) startCueSkip() time.Duration {
+	if !a.config.SoundCues() {
+		return 0
+	}
+	return audio.Duration(embedded.SoundStart) + cueLatency
+}
+
+// minRecordingDuration возвращает минимальную длительность записи для распознавания.
+func (a *App) minRecordingDuration() time.Duration {
+	return time.Duration(a.config.MinRecordingMs()) * time.Millisecond
+}
+
+// retryLanguage возвращает язык повторного распознавания пустого результата
+// или "", если повтор выключен, язык задан явно или модель не поддерживает
+// запасной язык. Повтор нужен только после автоопределения: при явном
+// языке пустой результат означает тишину, и второй проход её не исправит.
+func (a *App) retryLanguage(lang string) string {
+	if !a.config.RetryOnEmpty() || lang != "auto" {
+		return ""
+	}
+	fallback := a.config.FallbackLanguage()
+	if fallback == "" || fallback == lang {
+		return ""
+	}
+	// Модели Vosk одноязычные, язык распознавания они не принимают
+	info, ok := models.GetModel(a.speechFactory.CurrentModelID())
+	if !ok || info.Engine != models.EngineWhisper || !info.SupportsLanguage(fallback) {
+		return ""
+	}
+	return fallback
+}
+
+// padSilence добавляет тишину по краям записи и дополняет короткую
+// запись до длины из настроек.
+func (a *App) padSilence(samples []float32) []float32 {
+	return padSamples(a.config, samples)
+}
+
+// padSamples добавляет тишину по краям записи (pad_leading_ms,
+// pad_trailing_ms) и дополняет короткую запись до silence_pad_ms.
+func padSamples(cfg *config.Config, samples []float32) []float32 {
+	lead := audio.EdgePaddingSamples(time.Duration(cfg.PadLeadingMs()) * time.Millisecond)
+	trail := audio.EdgePaddingSamples(time.Duration(cfg.PadTrailingMs()) * time.Millisecond)
+	pad := audio.PaddingSamples(time.Duration(cfg.SilencePadMs()) * time.Millisecond)
+	return audio.PadSilence(audio.PadEdges(samples, lead, trail), pad)
+}
+
+// keepSamples запоминает запись для сохранения из трея, если это
+// включено в настройках. По умолчанию звук не хранится.
+func (a *App) keepSamples(samples []float32) {
+	if !a.config.DebugKeepAudio() || len(samples) == 0 {
+		return
+	}
+	a.mu.Lock()
+	a.lastSamples = samples
+	a.mu.Unlock()
+}
+
+// saveLastRecording сохраняет последнюю запись в WAV файл через диалог.
+func (a *App) saveLastRecording() {
+	a.mu.Lock()
+	samples := a.lastSamples
+	a.mu.Unlock()
+	if len(samples) == 0 {
+		a.notifier.Info(i18n.T("error_no_recording"))
+		return
+	}
+
+	name := "shofar-" + time.Now().Format("20060102-150405") + ".wav"
+	path, err := dialog.SaveWAV(i18n.T("tray_save_recording"), name)
+	if err != nil {
+		// Пользователь отменил
+		return
+	}
+	if err := audio.WriteWAVFile(path, samples); err != nil {
+		logx.Error("Ошибка сохранения записи", "err", err)
+		a.notifier.Error(i18n.T("error_save_recording") + ": " + shortError(err))
+		return
+	}
+	a.notifier.Info(i18n.T("notify_recording_saved") + ": " + path)
+}
+
+// transformText применяет правки текста из настроек (замены, пробелы,
+// заглавная буква, пунктуация Vosk). Работает независимо от LLM коррекции.
+func (a *App) transformText(text string) string {
+	text = textproc.Transform(text, textproc.Rules(a.config.TextRules()))
+	if a.config.VoskAutoPunctuate() && isVoskModel(a.speechFactory.CurrentModelID()) {
+		text = textproc.Punctuate(text)
+	}
+	return text
+}
+
+// isVoskModel возвращает true если модель распознаёт через Vosk,
+// который не расставляет заглавные буквы и знаки препинания.
+func isVoskModel(modelID string) bool {
+	info, ok := models.GetModel(modelID)
+	return ok && info.Engine == models.EngineVosk
+}
+
+// correctText исправляет текст через LLM. Возвращает пустую строку,
+// если модель не загружена, коррекция не удалась или ctx отменён.
+func (a *App) correctText(ctx context.Context, text string) string {
+	if a.llmModel == nil {
+		return ""
+	}
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+	corrected, err := a.llmModel.CorrectText(ctx, text, a.config.Language())
+	if errors.Is(err, llm.ErrContextFull) {
+		// Текст не поместился в контекст - пользователь должен знать, что коррекции не было
+		a.notifier.Info(i18n.T("warning_llm_context_full"))
+	}
+	if err != nil {
+		return ""
+	}
+	return corrected
+}
+
+// Close освобождает ресурсы приложения. Идущее распознавание отменяется,
+// и ресурсы освобождаются только после завершения его горутины: whisper.cpp,
+// Vosk и llama.cpp падают, если модель закрыть во время вычислений.
 func (a *App) Close() {
 	a.mu.Lock()
-	defer a.mu.Unlock()
+	if a.closing {
+		a.mu.Unlock()
+		return
+	}
+	a.closing = true
+
+	// Запись прерываем без распознавания, обработку - отменяем
+	if a.state == stateRecording {
+		a.recorder.Stop()
+		a.state = stateIdle
+	}
+	if a.cancelWork != nil {
+		a.cancelWork()
+	}
+	dictationStop, dictationDone := a.dictationStop, a.dictationDone
+	a.dictationStop, a.dictationDone = nil, nil
+	controlServer := a.controlServer
+	a.controlServer = nil
+	a.mu.Unlock()
 
 	if a.hotkey != nil {
 		a.hotkey.Unregister()
 	}
+	if a.cancelHotkey != nil {
+		a.cancelHotkey.Unregister()
+	}
+	if a.repeatHotkey != nil {
+		a.repeatHotkey.Unregister()
+	}
+
+	// Сервер ждёт завершения запросов, а их обработчики берут a.mu
+	if controlServer != nil {
+		controlServer.Close()
+	}
+
+	if dictationStop != nil {
+		close(dictationStop)
+		<-dictationDone
+	}
+
+	if a.settingsWin != nil {
+		a.settingsWin.Hide()
+	}
+
+	if !a.waitInflight(shutdownTimeout) {
+		// Освобождать модели под работающей горутиной опаснее, чем оставить
+		// их операционной системе при выходе
+		logx.Warn("Распознавание не завершилось, ресурсы не освобождены", "timeout", shutdownTimeout)
+		return
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
 
 	if a.recorder != nil {
 		a.recorder.Close()
@@ -486,24 +1609,42 @@ func (a *App) Close() {
 		a.llmModel = nil
 		a.llmModelID = ""
 	}
+}
 
-	if a.settingsWin != nil {
-		a.settingsWin.Hide()
+// waitInflight ждёт завершения обработки всех сессий не дольше timeout.
+// Возвращает false, если время вышло.
+func (a *App) waitInflight(timeout time.Duration) bool {
+	done := make(chan struct{})
+	go func() {
+		a.inflight.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return true
+	case <-time.After(timeout):
+		return false
 	}
 }
 
 // copyToClipboard copies text to system clipboard.
+// The program (wl-copy, xclip or xsel) is picked by tools.Current.
 func copyToClipboard(text string) error {
-	// Detect Wayland vs X11
-	if os.Getenv("WAYLAND_DISPLAY") != "" {
-		// Wayland: use wl-copy
-		cmd := exec.Command("wl-copy")
-		cmd.Stdin = strings.NewReader(text)
-		return cmd.Run()
+	tool, err := tools.Current().Require(tools.FeatureCopy)
+	if err != nil {
+		return err
 	}
 
-	// X11: use xclip
-	cmd := exec.Command("xclip", "-selection", "clipboard")
+	var cmd *exec.Cmd
+	switch tool {
+	case "wl-copy":
+		cmd = exec.Command("wl-copy")
+	case "xsel":
+		cmd = exec.Command("xsel", "--clipboard", "--input")
+	default:
+		cmd = exec.Command("xclip", "-selection", "clipboard")
+	}
 	cmd.Stdin = strings.NewReader(text)
 	return cmd.Run()
 }
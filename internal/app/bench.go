@@ -0,0 +1,100 @@
+package app
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"shofar/embedded"
+	"shofar/internal/audio"
+	"shofar/internal/config"
+	"shofar/internal/logx"
+	"shofar/internal/models"
+	"shofar/internal/speech"
+)
+
+// benchLanguage - язык встроенного образца речи.
+const benchLanguage = "en"
+
+// Benchmark загружает модель modelID и runs раз распознаёт встроенный
+// образец речи без запуска трея и окон. Результат пишется в w строками
+// key=value, чтобы замеры разных моделей было удобно сравнивать скриптом.
+// Потоки и пресет распознавания берутся из конфигурации.
+func Benchmark(w io.Writer, modelID string, runs int) error {
+	if runs <= 0 {
+		return fmt.Errorf("число прогонов должно быть больше нуля: %d", runs)
+	}
+
+	cfg := config.New()
+	logx.SetLevel(cfg.LogLevel())
+
+	samples, err := audio.DecodeWAV(bytes.NewReader(embedded.BenchSample))
+	if err != nil {
+		return fmt.Errorf("чтение образца: %w", err)
+	}
+	audioDur := time.Duration(len(samples)) * time.Second / audio.SampleRate
+
+	modelManager, err := models.NewManager()
+	if err != nil {
+		return err
+	}
+	if err := modelManager.LoadCustom(cfg.CustomModelsPath()); err != nil {
+		logx.Error("Ошибка загрузки пользовательских моделей", "err", err)
+	}
+
+	info, ok := models.GetModel(modelID)
+	if !ok || info.Engine == models.EngineLLM {
+		return fmt.Errorf("модель распознавания не найдена: %s", modelID)
+	}
+
+	speechFactory := speech.NewFactory(modelManager)
+	speechFactory.SetThreads(cfg.Threads())
+	speechFactory.SetWhisperParams(speech.WhisperPreset(cfg.RecognitionPreset()).Params())
+
+	start := time.Now()
+	if err := speechFactory.Load(modelID); err != nil {
+		return err
+	}
+	defer speechFactory.Close()
+	loadTime := time.Since(start)
+
+	recognizer := speechFactory.Current()
+	var total, fastest, slowest time.Duration
+	var text string
+	for i := 0; i < runs; i++ {
+		start := time.Now()
+		text, err = recognizer.Transcribe(samples, benchLanguage)
+		if err != nil {
+			return fmt.Errorf("прогон %d: %w", i+1, err)
+		}
+		elapsed := time.Since(start)
+		logx.Debug("Прогон замера", "run", i+1, "elapsed", elapsed)
+
+		total += elapsed
+		if i == 0 || elapsed < fastest {
+			fastest = elapsed
+		}
+		if elapsed > slowest {
+			slowest = elapsed
+		}
+	}
+	avg := total / time.Duration(runs)
+
+	fmt.Fprintf(w, "model=%s\n", info.ID)
+	fmt.Fprintf(w, "engine=%s\n", info.Engine)
+	fmt.Fprintf(w, "threads=%d\n", cfg.Threads())
+	fmt.Fprintf(w, "preset=%s\n", cfg.RecognitionPreset())
+	fmt.Fprintf(w, "audio_sec=%.2f\n", audioDur.Seconds())
+	fmt.Fprintf(w, "load_ms=%d\n", loadTime.Milliseconds())
+	fmt.Fprintf(w, "runs=%d\n", runs)
+	fmt.Fprintf(w, "avg_ms=%d\n", avg.Milliseconds())
+	fmt.Fprintf(w, "min_ms=%d\n", fastest.Milliseconds())
+	fmt.Fprintf(w, "max_ms=%d\n", slowest.Milliseconds())
+	// Real-time factor: меньше 1 - быстрее реального времени
+	fmt.Fprintf(w, "rtf=%.3f\n", avg.Seconds()/audioDur.Seconds())
+	// Переводы строк заменяются, чтобы вывод оставался построчным
+	fmt.Fprintf(w, "text=%s\n", strings.Join(strings.Fields(text), " "))
+	return nil
+}
@@ -0,0 +1,152 @@
+package app
+
+import (
+	"context"
+	"time"
+
+	"shofar/embedded"
+	"shofar/internal/audio"
+	"shofar/internal/i18n"
+	"shofar/internal/logx"
+	"shofar/internal/speech"
+	"shofar/internal/tray"
+	"shofar/internal/waveform"
+)
+
+const (
+	// DictationPause - пауза после речи, по которой фраза считается законченной
+	DictationPause = 700 * time.Millisecond
+	// dictationIdleFlush - сколько тишины без речи копится до сброса буфера
+	dictationIdleFlush = 2 * time.Second
+	// dictationPollInterval - период проверки записи на паузы
+	dictationPollInterval = 100 * time.Millisecond
+)
+
+// dictationLoop следит за записью и после каждой паузы в речи распознаёт
+// и вставляет фразу, не останавливая запись. Завершается при закрытии stop
+// или остановке записи.
+func (a *App) dictationLoop(stop <-chan struct{}, done chan<- struct{}) {
+	defer close(done)
+
+	ticker := time.NewTicker(dictationPollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+		}
+
+		if !a.recorder.IsRecording() {
+			return
+		}
+
+		silence, hasSpeech := audio.TrailingSilence(a.recorder.GetSamples())
+		switch {
+		case hasSpeech && silence >= DictationPause:
+			a.insertDictation(context.Background(), a.recorder.Flush())
+		case !hasSpeech && silence >= dictationIdleFlush:
+			// Одна тишина - отбрасываем, чтобы буфер не рос
+			a.recorder.Flush()
+		}
+	}
+}
+
+// insertDictation распознаёт фразу и сразу вставляет её в активное окно.
+func (a *App) insertDictation(ctx context.Context, samples []float32) {
+	if len(samples) == 0 {
+		return
+	}
+
+	a.mu.Lock()
+	recognizer := a.speechFactory.Current()
+	a.mu.Unlock()
+	if recognizer == nil {
+		a.notifier.Error(i18n.T("error_model_not_loaded"))
+		return
+	}
+
+	text, err := recognizer.TranscribeCtx(ctx, a.padSilence(samples), a.config.Language())
+	if ctx.Err() != nil {
+		return
+	}
+	if err != nil {
+		logx.Error("Ошибка распознавания фразы", "err", err)
+		a.notifier.Error(i18n.T("error_recognition"))
+		return
+	}
+	if !hasMeaningfulContent(text) || speech.IsHallucination(text, a.config.HallucinationBlocklist()) {
+		return
+	}
+
+	corrected := ""
+	if a.config.LLMEnabled() {
+		corrected = a.correctText(ctx, text)
+		if ctx.Err() != nil {
+			return
+		}
+	}
+	text, corrected = a.transformText(text), a.transformText(corrected)
+	if out := runPostCommand(ctx, a.config.PostCommand(), text, corrected); out != "" {
+		text = out
+	} else if corrected != "" {
+		text = corrected
+	}
+	if ctx.Err() != nil {
+		return
+	}
+	text = a.config.NewlineHandling().Apply(text)
+	if text == "" {
+		return
+	}
+
+	// Пробел разделяет фразы, вставленные подряд (в файл заметок
+	// каждая фраза пишется отдельной строкой)
+	if err := a.deliver(text + " "); err != nil {
+		logx.Error("Ошибка вывода текста", "err", err)
+		a.notifier.Error(err.Error())
+		return
+	}
+	// Фраза закончилась паузой, начало отсчитываем от её длины
+	duration := time.Duration(len(samples)) * time.Second / audio.SampleRate
+	a.remember(time.Now().Add(-duration), duration, text)
+	a.countStats(text, duration)
+}
+
+// finishDictation останавливает режим диктовки: дожидается текущей фразы,
+// вставляет остаток записи и закрывает окно.
+func (a *App) finishDictation(ctx context.Context, stop, done chan struct{}, session uint64) {
+	close(stop)
+	<-done
+
+	a.waveformWin.SetState(waveform.StateSpeechProcess)
+	samples := a.recorder.Stop()
+	a.playCue(embedded.SoundStop)
+	a.keepSamples(samples)
+
+	go func() {
+		defer a.finishSession(session)
+
+		if _, hasSpeech := audio.TrailingSilence(samples); hasSpeech {
+			a.tray.SetState(tray.StateProcessing)
+			a.insertDictation(ctx, samples)
+		}
+		a.waveformWin.Hide()
+		a.tray.SetState(tray.StateIdle)
+	}()
+}
+
+// stopDictationLoop завершает цикл диктовки при отмене записи.
+func (a *App) stopDictationLoop() {
+	a.mu.Lock()
+	stop, done := a.dictationStop, a.dictationDone
+	a.dictationStop, a.dictationDone = nil, nil
+	a.mu.Unlock()
+
+	if stop == nil {
+		return
+	}
+	close(stop)
+	<-done
+}
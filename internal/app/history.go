@@ -0,0 +1,99 @@
+package app
+
+import (
+	"fmt"
+	"time"
+
+	"shofar/internal/dialog"
+	"shofar/internal/export"
+	"shofar/internal/i18n"
+	"shofar/internal/logx"
+)
+
+// maxHistory - сколько последних записей сессии хранится для экспорта.
+const maxHistory = 1000
+
+// remember добавляет распознанный текст в историю сессии. История
+// хранится только в памяти и нужна для экспорта из трея.
+func (a *App) remember(start time.Time, duration time.Duration, text string) {
+	if text == "" {
+		return
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.history = append(a.history, export.Entry{Time: start, Duration: duration, Text: text})
+	if len(a.history) > maxHistory {
+		a.history = a.history[len(a.history)-maxHistory:]
+	}
+}
+
+// lastDelivered возвращает последний выведенный текст или "". Это не
+// последняя запись истории: текст могли поправить в окне результата,
+// а запись - отменить, не вставив.
+func (a *App) lastDelivered() string {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.delivered
+}
+
+// registerRepeatHotkey регистрирует клавишу повторной вставки из настроек
+// или снимает её, если клавиша не задана или недопустима.
+func (a *App) registerRepeatHotkey() error {
+	hk := a.config.RepeatHotkey()
+	if !hk.IsValid() {
+		if hk.Key != "" || hk.IsMouse() {
+			logx.Warn("Недопустимая клавиша повтора", "hotkey", hk.String())
+		}
+		return a.repeatHotkey.Unregister()
+	}
+	return a.repeatHotkey.Register(hk)
+}
+
+// onRepeatHotkeyPress вводит в активное окно последний выведенный текст
+// ещё раз, без новой записи.
+func (a *App) onRepeatHotkeyPress() {
+	if a.currentState() != stateIdle {
+		return
+	}
+	text := a.lastDelivered()
+	if text == "" {
+		a.notifier.Info(i18n.T("error_repeat_empty"))
+		return
+	}
+
+	// Вводим туда, где пользователь сейчас, а не в окно последней записи:
+	// Type возвращает фокус запомненному окну
+	a.typer.SaveFocus()
+	// Даём отпустить клавиши сочетания, иначе модификаторы исказят ввод
+	time.Sleep(time.Duration(a.config.InsertDelayMs()) * time.Millisecond)
+	if err := a.typer.Type(text); err != nil {
+		err = fmt.Errorf("%s: %w", i18n.T("error_input"), missingToolError(err))
+		logx.Error("Ошибка повторной вставки", "err", err)
+		a.notifier.Error(err.Error())
+	}
+}
+
+// exportHistory сохраняет историю сессии в файл через диалог.
+// Формат выбирается по расширению: txt, srt или json.
+func (a *App) exportHistory() {
+	a.mu.Lock()
+	entries := append([]export.Entry(nil), a.history...)
+	a.mu.Unlock()
+	if len(entries) == 0 {
+		a.notifier.Info(i18n.T("error_export_empty"))
+		return
+	}
+
+	name := "shofar-" + time.Now().Format("20060102-150405") + ".txt"
+	path, err := dialog.SaveExport(i18n.T("tray_export"), name)
+	if err != nil {
+		// Пользователь отменил
+		return
+	}
+	if err := export.WriteFile(path, entries); err != nil {
+		logx.Error("Ошибка экспорта", "err", err)
+		a.notifier.Error(i18n.T("error_export") + ": " + shortError(err))
+		return
+	}
+	a.notifier.Info(i18n.T("notify_exported") + ": " + path)
+}
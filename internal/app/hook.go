@@ -0,0 +1,57 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"time"
+
+	"shofar/internal/logx"
+)
+
+// postCommandTimeout - сколько ждать внешнюю команду обработки текста
+const postCommandTimeout = 10 * time.Second
+
+// postCommandWaitDelay - сколько после отмены ждать закрытия вывода
+// команды: запущенные ею процессы могут держать его открытым.
+const postCommandWaitDelay = time.Second
+
+// postCommandEnv - переменная окружения с текстом после LLM коррекции
+const postCommandEnv = "SHOFAR_CORRECTED_TEXT"
+
+// runPostCommand передаёт распознанный текст внешней команде из настроек
+// (post_command): исходный текст на stdin, исправленный LLM - в переменной
+// SHOFAR_CORRECTED_TEXT (пусто, если коррекции не было). Возвращает вывод
+// команды без завершающего перевода строки, если она завершилась с кодом 0
+// и что-то напечатала, иначе "" - тогда остаётся исходный результат.
+func runPostCommand(ctx context.Context, command, original, corrected string) string {
+	if command == "" || original == "" {
+		return ""
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, postCommandTimeout)
+	defer cancel()
+
+	var cmd *exec.Cmd
+	if runtime.GOOS == "windows" {
+		cmd = exec.CommandContext(ctx, "cmd", "/C", command)
+	} else {
+		cmd = exec.CommandContext(ctx, "sh", "-c", command)
+	}
+	killGroupOnCancel(cmd)
+	cmd.WaitDelay = postCommandWaitDelay
+	cmd.Stdin = strings.NewReader(original)
+	cmd.Env = append(os.Environ(), postCommandEnv+"="+corrected)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	out, err := cmd.Output()
+	if err != nil {
+		logx.Warn("Ошибка команды обработки текста", "err", err, "stderr", strings.TrimSpace(stderr.String()))
+		return ""
+	}
+	return strings.TrimRight(string(out), "\r\n")
+}
@@ -0,0 +1,9 @@
+//go:build !unix
+
+package app
+
+import "os/exec"
+
+// killGroupOnCancel ничего не меняет: по отмене убивается сам процесс,
+// а оставшиеся дочерние процессы отсекает WaitDelay.
+func killGroupOnCancel(cmd *exec.Cmd) {}
@@ -0,0 +1,18 @@
+//go:build unix
+
+package app
+
+import (
+	"os/exec"
+	"syscall"
+)
+
+// killGroupOnCancel запускает команду в своей группе процессов и по отмене
+// убивает всю группу: sh -c иначе оставляет дочерние процессы команды
+// работать и держать stdout открытым.
+func killGroupOnCancel(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	cmd.Cancel = func() error {
+		return syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+	}
+}
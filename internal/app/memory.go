@@ -0,0 +1,33 @@
+package app
+
+import (
+	"fmt"
+
+	"shofar/internal/dialog"
+	"shofar/internal/i18n"
+	"shofar/internal/logx"
+	"shofar/internal/models"
+	"shofar/internal/sysmem"
+)
+
+// confirmMemory сравнивает память, нужную модели, со свободной и при нехватке
+// спрашивает, загружать ли модель: иначе система может завершить процесс
+// без объяснений. Возвращает false, если пользователь отказался.
+func (a *App) confirmMemory(info models.ModelInfo) bool {
+	if info.RAMRequiredMB <= 0 {
+		return true
+	}
+	available, ok := sysmem.AvailableMB()
+	if !ok || available >= info.RAMRequiredMB {
+		return true
+	}
+
+	logx.Warn("Мало свободной памяти для модели", "model", info.ID,
+		"required_mb", info.RAMRequiredMB, "available_mb", available)
+	message := fmt.Sprintf(i18n.T("dialog_low_memory"), info.Name, info.RAMRequiredMB, available)
+	if dialog.Confirm(i18n.T("dialog_low_memory_title"), message) {
+		return true
+	}
+	logx.Info("Загрузка модели отменена из-за нехватки памяти", "model", info.ID)
+	return false
+}
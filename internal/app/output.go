@@ -0,0 +1,85 @@
+package app
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"shofar/internal/config"
+	"shofar/internal/i18n"
+)
+
+// notesDate - подстановка даты в пути файла заметок
+const notesDate = "{date}"
+
+// deliver отправляет итоговый текст по настройке output_target: вводит
+// в активное окно, копирует в буфер обмена или дописывает в файл заметок.
+// Ошибка уже содержит понятное пользователю описание. Выведенный текст
+// запоминается для клавиши повтора.
+func (a *App) deliver(text string) error {
+	switch a.config.OutputTarget() {
+	case config.OutputClipboard:
+		if err := copyToClipboard(text); err != nil {
+			return fmt.Errorf("%s: %w", i18n.T("error_clipboard"), missingToolError(err))
+		}
+	case config.OutputFile:
+		if err := appendNote(a.config.NotesFile(), text, a.config.NotesTimestamp(), time.Now()); err != nil {
+			return fmt.Errorf("%s: %w", i18n.T("error_notes_file"), err)
+		}
+	default:
+		if err := a.typer.Type(text); err != nil {
+			return fmt.Errorf("%s: %w", i18n.T("error_input"), missingToolError(err))
+		}
+	}
+
+	a.mu.Lock()
+	a.delivered = text
+	a.mu.Unlock()
+	return nil
+}
+
+// appendNote дописывает текст отдельной строкой в файл заметок, создавая
+// файл и каталоги при необходимости. В шаблоне пути "~" заменяется
+// домашним каталогом, {date} - датой now. С stamp перед текстом пишется время.
+func appendNote(pattern, text string, stamp bool, now time.Time) error {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return nil
+	}
+
+	path, err := notesPath(pattern, now)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	if stamp {
+		text = now.Format("[2006-01-02 15:04] ") + text
+	}
+	if _, err := f.WriteString(text + "\n"); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// notesPath раскрывает шаблон пути к файлу заметок.
+func notesPath(pattern string, now time.Time) (string, error) {
+	path := strings.ReplaceAll(pattern, notesDate, now.Format("2006-01-02"))
+	if path == "~" || strings.HasPrefix(path, "~/") || strings.HasPrefix(path, `~\`) {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", err
+		}
+		path = filepath.Join(home, path[1:])
+	}
+	return path, nil
+}
@@ -0,0 +1,59 @@
+package app
+
+import (
+	"time"
+
+	"shofar/internal/logx"
+	"shofar/internal/speech"
+)
+
+// partialPollInterval - период обновления промежуточного текста
+const partialPollInterval = 300 * time.Millisecond
+
+// startPartials запускает показ промежуточного текста, если текущий
+// распознаватель умеет распознавать потоком. Whisper не умеет: для него
+// окно записи остаётся без текста, а результат даёт обычное распознавание.
+func (a *App) startPartials(session uint64) {
+	sr, ok := a.speechFactory.Current().(speech.StreamingRecognizer)
+	if !ok {
+		return
+	}
+	stream, err := sr.NewStream()
+	if err != nil {
+		logx.Warn("Не удалось начать потоковое распознавание", "err", err)
+		return
+	}
+	go a.partialLoop(stream, session)
+}
+
+// partialLoop по ходу записи передаёт новые сэмплы в поток распознавания
+// и показывает промежуточный текст в окне записи. Завершается, когда
+// запись остановлена или началась следующая.
+func (a *App) partialLoop(stream speech.PartialStream, session uint64) {
+	defer stream.Close()
+
+	ticker := time.NewTicker(partialPollInterval)
+	defer ticker.Stop()
+
+	fed := 0
+	for range ticker.C {
+		a.mu.Lock()
+		current := a.state == stateRecording && a.session == session
+		a.mu.Unlock()
+		if !current {
+			return
+		}
+
+		samples := a.recorder.GetSamples()
+		if len(samples) <= fed {
+			continue
+		}
+		text, err := stream.Feed(samples[fed:])
+		fed = len(samples)
+		if err != nil {
+			logx.Debug("Ошибка промежуточного распознавания", "err", err)
+			return
+		}
+		a.waveformWin.SetPartial(text)
+	}
+}
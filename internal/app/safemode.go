@@ -0,0 +1,62 @@
+package app
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	"shofar/internal/logx"
+)
+
+// loadMarkerName - файл-метка рядом с бинарником (как config.json), который
+// существует, пока загружается модель. Если движок упал внутри cgo, метка
+// остаётся, и следующий запуск переходит в безопасный режим.
+const loadMarkerName = "shofar.loading"
+
+// loadMarkerPath возвращает путь к метке загрузки или "", если путь
+// к бинарнику не определить.
+func loadMarkerPath() string {
+	execPath, err := os.Executable()
+	if err != nil {
+		return ""
+	}
+	execPath, err = filepath.EvalSymlinks(execPath)
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(filepath.Dir(execPath), loadMarkerName)
+}
+
+// beginLoad отмечает начало загрузки модели modelID.
+func beginLoad(modelID string) {
+	path := loadMarkerPath()
+	if path == "" {
+		return
+	}
+	if err := os.WriteFile(path, []byte(modelID), 0644); err != nil {
+		logx.Warn("Не удалось создать метку загрузки", "err", err)
+	}
+}
+
+// endLoad снимает метку загрузки: загрузка завершилась, успешно или с ошибкой,
+// но без падения процесса.
+func endLoad() {
+	if path := loadMarkerPath(); path != "" {
+		os.Remove(path)
+	}
+}
+
+// crashedLoad возвращает ID модели, на загрузке которой упал прошлый запуск.
+// Метка снимается: безопасный режим включается только на один запуск.
+func crashedLoad() (modelID string, crashed bool) {
+	path := loadMarkerPath()
+	if path == "" {
+		return "", false
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", false
+	}
+	os.Remove(path)
+	return strings.TrimSpace(string(data)), true
+}
@@ -0,0 +1,149 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// recordState - этап цикла записи. Меняется только под a.mu:
+// idle → recording → processing → idle, отмена возвращает в idle.
+// Переходы не по порядку (остановка без записи, старт во время
+// обработки) отклоняются.
+type recordState int
+
+const (
+	stateIdle recordState = iota
+	stateRecording
+	stateProcessing
+)
+
+// String возвращает имя состояния (используется в статусе сервера управления).
+func (s recordState) String() string {
+	switch s {
+	case stateRecording:
+		return "recording"
+	case stateProcessing:
+		return "processing"
+	default:
+		return "idle"
+	}
+}
+
+// errBusy - действие невозможно, пока идёт запись или распознавание.
+var errBusy = errors.New("идёт запись или распознавание")
+
+// minToggleInterval - повторное нажатие горячей клавиши раньше этого
+// интервала после начала записи считается дребезгом и игнорируется,
+// а не останавливает только что начатую запись.
+const minToggleInterval = 400 * time.Millisecond
+
+// pressAction - что сделать с нажатием горячей клавиши.
+type pressAction int
+
+const (
+	pressIgnore pressAction = iota // приложение закрывается, дребезг или идёт обработка
+	pressStop                      // остановить идущую запись
+	pressStart                     // начать запись
+)
+
+// decidePress решает, что сделать с нажатием в момент now. Toggle режим:
+// нажатие во время записи останавливает её, но слишком быстрое - дребезг.
+// Вызывается под a.mu.
+func (a *App) decidePress(now time.Time) pressAction {
+	if a.closing {
+		return pressIgnore
+	}
+	switch a.state {
+	case stateRecording:
+		if now.Sub(a.recordingStart) < minToggleInterval {
+			return pressIgnore
+		}
+		return pressStop
+	case stateProcessing:
+		return pressIgnore
+	}
+	return pressStart
+}
+
+// currentState возвращает текущее состояние записи.
+func (a *App) currentState() recordState {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.state
+}
+
+// beginSession начинает запись новой сессии. Отложенная до загрузки
+// модели запись этой записью уже выполнена. Вызывается под a.mu.
+func (a *App) beginSession() {
+	a.state = stateRecording
+	a.session++
+	a.recordQueued = false
+}
+
+// finishSession возвращает приложение в ожидание после обработки сессии id.
+// Если за это время запись отменили и начали новую, состояние не трогаем:
+// запоздавшая обработка не должна сбросить чужую сессию.
+// Вызывается ровно один раз на каждый startProcessing.
+func (a *App) finishSession(id uint64) {
+	defer a.inflight.Done()
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.session != id {
+		return
+	}
+	if a.cancelWork != nil {
+		a.cancelWork()
+		a.cancelWork = nil
+	}
+	if a.state == stateProcessing {
+		a.state = stateIdle
+	}
+}
+
+// startProcessing переводит запись в обработку и возвращает контекст,
+// отмена которого прерывает распознавание и коррекцию. Вызывается под a.mu.
+// Close не освобождает ресурсы, пока обработка не завершится finishSession.
+func (a *App) startProcessing() (context.Context, uint64) {
+	a.inflight.Add(1)
+	ctx, cancel := context.WithCancel(context.Background())
+	a.state = stateProcessing
+	a.cancelWork = cancel
+	a.releasePausedHotkeys()
+	return ctx, a.session
+}
+
+// cancelProcessing прерывает идущее распознавание: движок останавливается,
+// процессор освобождается, а результат сессии отбрасывается.
+func (a *App) cancelProcessing() {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.cancelWork != nil {
+		a.cancelWork()
+		a.cancelWork = nil
+	}
+	a.state = stateIdle
+}
+
+// cancelQueuedRecording снимает запись, отложенную до загрузки модели.
+func (a *App) cancelQueuedRecording() {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.recordQueued = false
+}
+
+// cancelRecording прерывает идущую запись без распознавания.
+// Возвращает false, если запись не шла.
+func (a *App) cancelRecording() bool {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.state != stateRecording {
+		return false
+	}
+	// Записанные сэмплы отбрасываем
+	a.recorder.Stop()
+	a.state = stateIdle
+	a.releasePausedHotkeys()
+	return true
+}
@@ -0,0 +1,124 @@
+package app
+
+import (
+	"testing"
+	"time"
+)
+
+// testClock - время нажатий в тестах: каждое следующее нажатие по
+// умолчанию позже предыдущего на интервал защиты от дребезга.
+var testClock = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// press имитирует нажатие горячей клавиши через decidePress: начало
+// записи выполняется как в toggleRecording, остановка - через stop.
+// Возвращает принятое решение.
+func press(a *App) pressAction {
+	testClock = testClock.Add(minToggleInterval)
+	return pressAt(a, testClock)
+}
+
+func pressAt(a *App, now time.Time) pressAction {
+	a.mu.Lock()
+	action := a.decidePress(now)
+	if action == pressStart {
+		a.recordingStart = now
+		a.beginSession()
+	}
+	a.mu.Unlock()
+	return action
+}
+
+// stop имитирует остановку записи: сессия уходит в обработку. Возвращает
+// завершение обработки и проверку, отменён ли её контекст.
+func stop(a *App) (done func(), cancelled func() bool) {
+	a.mu.Lock()
+	ctx, id := a.startProcessing()
+	a.mu.Unlock()
+	return func() { a.finishSession(id) }, func() bool { return ctx.Err() != nil }
+}
+
+func TestStaleSessionDoesNotResetNewer(t *testing.T) {
+	a := &App{}
+
+	// Первая запись остановлена и обрабатывается, но её отменили
+	press(a)
+	finishFirst, firstCancelled := stop(a)
+	a.cancelProcessing()
+	if !firstCancelled() {
+		t.Fatal("отмена не прервала обработку первой сессии")
+	}
+
+	// Новая запись началась и тоже ушла в обработку
+	press(a)
+	finishSecond, secondCancelled := stop(a)
+
+	// Запоздавшая обработка первой сессии не трогает вторую
+	finishFirst()
+	if got := a.currentState(); got != stateProcessing {
+		t.Fatalf("после завершения старой сессии состояние %v, ожидалось processing", got)
+	}
+	if secondCancelled() {
+		t.Fatal("завершение старой сессии отменило новую")
+	}
+
+	finishSecond()
+	if got := a.currentState(); got != stateIdle {
+		t.Fatalf("после завершения новой сессии состояние %v, ожидалось idle", got)
+	}
+	if !secondCancelled() {
+		t.Fatal("контекст завершённой сессии не освобождён")
+	}
+	a.inflight.Wait()
+}
+
+func TestRapidPresses(t *testing.T) {
+	a := &App{}
+
+	if got := press(a); got != pressStart {
+		t.Fatalf("первое нажатие: %v, ожидалось начало записи", got)
+	}
+	// Нажатие во время записи её останавливает, а не перезапускает
+	if got := press(a); got != pressStop || a.session != 1 {
+		t.Fatalf("второе нажатие: %v, session %d, ожидалась остановка и 1", got, a.session)
+	}
+
+	finish, _ := stop(a)
+	// Во время обработки новая запись не начинается
+	if got := press(a); got != pressIgnore || a.currentState() != stateProcessing {
+		t.Fatalf("нажатие во время обработки: %v, состояние %v", got, a.currentState())
+	}
+
+	finish()
+	if got := press(a); got != pressStart || a.currentState() != stateRecording || a.session != 2 {
+		t.Fatalf("нажатие после обработки: %v, состояние %v, session %d", got, a.currentState(), a.session)
+	}
+}
+
+func TestPressDebounce(t *testing.T) {
+	a := &App{}
+	start := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	pressAt(a, start)
+
+	// Дребезг сразу после начала записи не останавливает её
+	if got := pressAt(a, start.Add(minToggleInterval/4)); got != pressIgnore {
+		t.Errorf("нажатие через %v: %v, ожидался пропуск", minToggleInterval/4, got)
+	}
+	if got := pressAt(a, start.Add(minToggleInterval)); got != pressStop {
+		t.Errorf("нажатие через %v: %v, ожидалась остановка", minToggleInterval, got)
+	}
+}
+
+func TestPressWhileClosing(t *testing.T) {
+	a := &App{closing: true}
+	if got := press(a); got != pressIgnore || a.currentState() != stateIdle {
+		t.Errorf("нажатие при закрытии: %v, состояние %v", got, a.currentState())
+	}
+}
+
+func TestBeginSessionClearsQueue(t *testing.T) {
+	a := &App{recordQueued: true}
+	press(a)
+	if a.recordQueued {
+		t.Fatal("отложенная запись осталась после начала записи")
+	}
+}
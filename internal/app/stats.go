@@ -0,0 +1,55 @@
+package app
+
+import (
+	"fmt"
+	"time"
+
+	"shofar/internal/dialog"
+	"shofar/internal/i18n"
+	"shofar/internal/logx"
+)
+
+// countStats учитывает в статистике успешную запись длительностью
+// duration с итоговым текстом text. Ничего не делает, если статистика выключена.
+func (a *App) countStats(text string, duration time.Duration) {
+	if a.stats == nil || text == "" {
+		return
+	}
+	if _, err := a.stats.Add(countWords(text), duration); err != nil {
+		logx.Warn("Не удалось сохранить статистику", "err", err)
+	}
+	a.refreshStats()
+}
+
+// refreshStats показывает текущую статистику в пункте трея.
+func (a *App) refreshStats() {
+	if a.stats == nil {
+		return
+	}
+	s := a.stats.Get()
+	a.tray.SetStats(fmt.Sprintf(i18n.T("stats_summary"), s.Words, formatSaved(s.TimeSaved())))
+}
+
+// showStats показывает подробную статистику и предлагает её сбросить.
+func (a *App) showStats() {
+	s := a.stats.Get()
+	message := fmt.Sprintf(i18n.T("dialog_stats"), s.Since.Format("2006-01-02"),
+		s.Words, s.Recordings, formatSaved(s.TimeSaved()))
+	if !dialog.Confirm(i18n.T("dialog_stats_title"), message) {
+		return
+	}
+	if err := a.stats.Reset(); err != nil {
+		logx.Error("Ошибка сброса статистики", "err", err)
+		a.notifier.Error(i18n.T("error_stats") + ": " + shortError(err))
+	}
+	a.refreshStats()
+}
+
+// formatSaved форматирует сэкономленное время с точностью до минуты.
+func formatSaved(d time.Duration) string {
+	minutes := int(d / time.Minute)
+	if minutes < 60 {
+		return fmt.Sprintf(i18n.T("stats_minutes"), minutes)
+	}
+	return fmt.Sprintf(i18n.T("stats_hours"), minutes/60, minutes%60)
+}
@@ -0,0 +1,60 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"shofar/internal/i18n"
+	"shofar/internal/logx"
+	"shofar/internal/tools"
+)
+
+// checkTools проверяет при запуске внешние программы для ввода текста,
+// буфера обмена и окон (Linux) и одним уведомлением перечисляет
+// недостающие и то, что без них не будет работать.
+func (a *App) checkTools() {
+	if s := tools.Current(); s.Session != "" {
+		logx.Info("Внешние программы", "strategy", s.String())
+	}
+
+	missing := tools.Missing()
+	if len(missing) == 0 {
+		return
+	}
+
+	lines := make([]string, 0, len(missing))
+	for _, t := range missing {
+		logx.Warn("Не найдена внешняя программа", "tool", t.Name(), "feature", t.Feature)
+		lines = append(lines, t.Name()+" — "+i18n.T("tool_feature_"+string(t.Feature)))
+	}
+	a.notifier.Error(i18n.T("notify_missing_tools") + ":\n" + strings.Join(lines, "\n"))
+}
+
+// missingToolError заменяет ошибку отсутствующей программы подсказкой
+// на языке интерфейса: что установить. Остальные ошибки не меняет.
+func missingToolError(err error) error {
+	var missing *tools.MissingError
+	if errors.As(err, &missing) {
+		return fmt.Errorf(i18n.T("error_missing_tool"), missing.Name)
+	}
+	return err
+}
+
+// Diagnose выводит в w программы, выбранные для ввода текста, буфера обмена
+// и окон, по одной возможности в строке: "type=ydotool". Ненайденная
+// программа - "-". Для флага -diagnose.
+func Diagnose(w io.Writer) error {
+	s := tools.Current()
+	if s.Session == "" {
+		_, err := fmt.Fprintln(w, "внешние программы не нужны")
+		return err
+	}
+	for _, part := range strings.Fields(s.String()) {
+		if _, err := fmt.Fprintln(w, part); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,119 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"shofar/internal/audio"
+	"shofar/internal/config"
+	"shofar/internal/llm"
+	"shofar/internal/logx"
+	"shofar/internal/models"
+	"shofar/internal/speech"
+	"shofar/internal/textproc"
+)
+
+// TranscribeFile распознаёт WAV файл моделью из конфигурации без запуска
+// трея и окон. Если useLLM, результат дополнительно исправляется LLM.
+func TranscribeFile(path string, useLLM bool) (string, error) {
+	cfg := config.New()
+	logx.SetLevel(cfg.LogLevel())
+
+	samples, err := audio.ReadWAVFile(path)
+	if err != nil {
+		return "", fmt.Errorf("чтение %s: %w", path, err)
+	}
+
+	modelManager, err := models.NewManager()
+	if err != nil {
+		return "", err
+	}
+	if err := modelManager.LoadCustom(cfg.CustomModelsPath()); err != nil {
+		logx.Error("Ошибка загрузки пользовательских моделей", "err", err)
+	}
+
+	modelID := cfg.ModelID()
+	if _, ok := models.GetModel(modelID); !ok {
+		modelID = models.DefaultModelID()
+	}
+
+	speechFactory := speech.NewFactory(modelManager)
+	speechFactory.SetThreads(cfg.Threads())
+	speechFactory.SetPrompt(cfg.WhisperPrompt())
+	speechFactory.SetWhisperParams(speech.WhisperPreset(cfg.RecognitionPreset()).Params())
+	if path := cfg.VoskGrammarFile(); path != "" {
+		phrases, err := speech.LoadGrammar(path)
+		if err != nil {
+			return "", err
+		}
+		speechFactory.SetVoskGrammar(phrases)
+	}
+	if err := speechFactory.Load(modelID); err != nil {
+		return "", err
+	}
+	defer speechFactory.Close()
+
+	if cfg.Denoise() {
+		samples = audio.Denoise(samples)
+	}
+	text, err := speechFactory.Current().Transcribe(padSamples(cfg, samples), cfg.Language())
+	if err != nil {
+		return "", err
+	}
+	if speech.IsHallucination(text, cfg.HallucinationBlocklist()) {
+		return "", nil
+	}
+	transform := func(text string) string {
+		text = textproc.Transform(text, textproc.Rules(cfg.TextRules()))
+		if cfg.VoskAutoPunctuate() && isVoskModel(modelID) {
+			text = textproc.Punctuate(text)
+		}
+		return text
+	}
+	if !useLLM || text == "" {
+		return postProcess(cfg, transform(text), ""), nil
+	}
+
+	llmID := cfg.LLMModelID()
+	if llmID == "" {
+		llmID = models.DefaultLLMModelID()
+	}
+	info, ok := models.GetModel(llmID)
+	if !ok {
+		return "", fmt.Errorf("модель не найдена: %s", llmID)
+	}
+	if !modelManager.IsDownloaded(info) {
+		return "", fmt.Errorf("модель не скачана: %s", info.Name)
+	}
+
+	model, err := llm.NewLlamaModel(modelManager.GetModelPath(info), cfg.LLMContextSize(), cfg.Threads(), cfg.LLMGPULayers())
+	if err != nil {
+		return "", err
+	}
+	defer model.Close()
+	model.SetSampler(llm.Sampler(cfg.LLMSampler()))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	corrected, err := model.CorrectText(ctx, text, cfg.Language())
+	if err != nil {
+		// Коррекция не удалась - возвращаем исходное распознавание
+		logx.Warn("Коррекция не применена", "err", err)
+		corrected = ""
+	}
+	return postProcess(cfg, transform(text), transform(corrected)), nil
+}
+
+// postProcess пропускает результат через внешнюю команду из настроек.
+// Без команды или при её ошибке возвращается исправленный текст,
+// а если коррекции не было - исходный.
+func postProcess(cfg *config.Config, original, corrected string) string {
+	if out := runPostCommand(context.Background(), cfg.PostCommand(), original, corrected); out != "" {
+		return out
+	}
+	if corrected != "" {
+		return corrected
+	}
+	return original
+}
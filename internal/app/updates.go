@@ -0,0 +1,36 @@
+package app
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"shofar/internal/i18n"
+	"shofar/internal/logx"
+)
+
+// updateCheckTimeout ограничивает проверку обновлений моделей целиком.
+const updateCheckTimeout = 2 * time.Minute
+
+// checkModelUpdates проверяет в фоне, не обновились ли на сервере
+// скачанные модели, и сообщает о найденных. Обновить модель можно
+// в окне настроек.
+func (a *App) checkModelUpdates() {
+	ctx, cancel := context.WithTimeout(context.Background(), updateCheckTimeout)
+	defer cancel()
+
+	outdated, err := a.modelManager.CheckForUpdates(ctx)
+	if err != nil {
+		// Без сети проверка просто не удалась, пользователю это не важно
+		logx.Warn("Ошибка проверки обновлений моделей", "err", err)
+	}
+	if len(outdated) == 0 {
+		return
+	}
+
+	names := make([]string, 0, len(outdated))
+	for _, info := range outdated {
+		names = append(names, info.Name)
+	}
+	a.notifier.Info(i18n.T("notify_model_updates") + ": " + strings.Join(names, ", "))
+}
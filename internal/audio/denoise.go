@@ -0,0 +1,168 @@
+package audio
+
+import (
+	"math"
+	"math/cmplx"
+	"sort"
+)
+
+const (
+	// denoiseFrame - размер кадра спектрального анализа (32ms при 16kHz),
+	// степень двойки для БПФ.
+	denoiseFrame = 512
+	// denoiseHop - сдвиг кадра: половина кадра, окна Ханна в сумме дают 1.
+	denoiseHop = denoiseFrame / 2
+	// noiseShare - доля самых тихих кадров записи, по которым оценивается
+	// шум: в паузах между словами микрофон слышит только фон. Начало записи
+	// для этого не годится - там звучит сигнал начала записи.
+	noiseShare = 0.1
+	// minNoiseFrames - сколько кадров нужно для оценки шума (~100ms).
+	minNoiseFrames = 6
+	// noiseOverSubtract - во сколько раз вычитаемый шум больше оценённого:
+	// шум в каждом кадре колеблется вокруг среднего.
+	noiseOverSubtract = 1.5
+	// noiseFloor - доля исходной амплитуды, которая остаётся в подавленной
+	// полосе. Полное обнуление даёт «музыкальный» шум, который Whisper
+	// принимает за речь.
+	noiseFloor = 0.1
+)
+
+// Denoise ослабляет постоянный фоновый шум (вентилятор, гул) спектральным
+// вычитанием: по самым тихим кадрам записи оценивается спектр шума,
+// и в каждом кадре из амплитуды каждой частоты вычитается уровень шума.
+// Запись короче оценки шума возвращается как есть. Исходный буфер не меняется.
+func Denoise(samples []float32) []float32 {
+	if len(samples) < (minNoiseFrames+1)*denoiseHop {
+		return samples
+	}
+
+	window := make([]float64, denoiseFrame)
+	for i := range window {
+		window[i] = 0.5 - 0.5*math.Cos(2*math.Pi*float64(i)/denoiseFrame)
+	}
+
+	// Дополняем запись нулями до целого числа кадров с запасом по краям,
+	// чтобы сумма окон покрывала каждый исходный сэмпл
+	frames := (len(samples)+denoiseHop-1)/denoiseHop + 1
+	padded := make([]float64, (frames+1)*denoiseHop)
+	for i, s := range samples {
+		padded[denoiseHop+i] = float64(s)
+	}
+
+	// Спектр шума - средняя амплитуда самых тихих кадров
+	buf := make([]complex128, denoiseFrame)
+	noise := make([]float64, denoiseFrame/2+1)
+	quiet := quietestFrames(padded[denoiseHop:denoiseHop+len(samples)], window)
+	if len(quiet) == 0 {
+		return samples
+	}
+	for _, start := range quiet {
+		spectrum(buf, padded[denoiseHop+start:denoiseHop+start+denoiseFrame], window)
+		for k := range noise {
+			noise[k] += cmplx.Abs(buf[k])
+		}
+	}
+	for k := range noise {
+		noise[k] = noise[k] / float64(len(quiet)) * noiseOverSubtract
+	}
+
+	out := make([]float64, len(padded))
+	for start := 0; start+denoiseFrame <= len(padded); start += denoiseHop {
+		spectrum(buf, padded[start:start+denoiseFrame], window)
+		for k := 0; k <= denoiseFrame/2; k++ {
+			mag := cmplx.Abs(buf[k])
+			if mag == 0 {
+				continue
+			}
+			gain := math.Max(mag-noise[k], noiseFloor*mag) / mag
+			buf[k] *= complex(gain, 0)
+			// Спектр вещественного сигнала симметричен
+			if k > 0 && k < denoiseFrame/2 {
+				buf[denoiseFrame-k] = cmplx.Conj(buf[k])
+			}
+		}
+		fft(buf, true)
+		for i := range buf {
+			out[start+i] += real(buf[i]) / denoiseFrame
+		}
+	}
+
+	result := make([]float32, len(samples))
+	for i := range result {
+		result[i] = float32(out[denoiseHop+i])
+	}
+	return result
+}
+
+// quietestFrames возвращает начала самых тихих кадров samples: noiseShare
+// всех кадров, но не меньше minNoiseFrames. Кадры цифровой тишины (нули,
+// которыми дополнена запись) пропускаются: шума в них нет.
+func quietestFrames(samples []float64, window []float64) []int {
+	type frame struct {
+		start  int
+		energy float64
+	}
+	var frames []frame
+	for start := 0; start+denoiseFrame <= len(samples); start += denoiseHop {
+		energy := 0.0
+		for i, s := range samples[start : start+denoiseFrame] {
+			v := s * window[i]
+			energy += v * v
+		}
+		if energy > 0 {
+			frames = append(frames, frame{start, energy})
+		}
+	}
+	if len(frames) < minNoiseFrames {
+		return nil
+	}
+
+	sort.Slice(frames, func(i, j int) bool { return frames[i].energy < frames[j].energy })
+	n := max(minNoiseFrames, int(float64(len(frames))*noiseShare))
+	starts := make([]int, n)
+	for i := range starts {
+		starts[i] = frames[i].start
+	}
+	return starts
+}
+
+// spectrum заполняет buf спектром кадра frame, умноженного на окно.
+func spectrum(buf []complex128, frame, window []float64) {
+	for i := range buf {
+		buf[i] = complex(frame[i]*window[i], 0)
+	}
+	fft(buf, false)
+}
+
+// fft - быстрое преобразование Фурье на месте (Кули-Тьюки, длина -
+// степень двойки). С inverse считается обратное преобразование без
+// деления на длину.
+func fft(x []complex128, inverse bool) {
+	n := len(x)
+	for i, j := 1, 0; i < n; i++ {
+		bit := n >> 1
+		for ; j&bit != 0; bit >>= 1 {
+			j ^= bit
+		}
+		j |= bit
+		if i < j {
+			x[i], x[j] = x[j], x[i]
+		}
+	}
+
+	sign := -1.0
+	if inverse {
+		sign = 1.0
+	}
+	for size := 2; size <= n; size <<= 1 {
+		step := cmplx.Rect(1, sign*2*math.Pi/float64(size))
+		for start := 0; start < n; start += size {
+			w := complex(1, 0)
+			for k := 0; k < size/2; k++ {
+				a, b := x[start+k], w*x[start+k+size/2]
+				x[start+k], x[start+k+size/2] = a+b, a-b
+				w *= step
+			}
+		}
+	}
+}
@@ -0,0 +1,79 @@
+package audio
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+// syntheticSpeech возвращает запись «речи»: слоги из гармоник основного
+// тона с паузами между словами. В начале звучит сигнал начала записи.
+func syntheticSpeech(seconds float64) []float32 {
+	n := int(seconds * SampleRate)
+	s := make([]float32, n)
+	for i := range s {
+		t := float64(i) / SampleRate
+		switch {
+		case t < 0.15:
+			// Сигнал начала записи
+			s[i] = float32(0.3 * math.Sin(2*math.Pi*880*t))
+		case math.Mod(t, 0.8) < 0.5:
+			// Слово: огибающая слогов и гармоники тона 150 Hz
+			env := 0.5 - 0.5*math.Cos(2*math.Pi*t/0.25)
+			v := 0.0
+			for h := 1; h <= 10; h++ {
+				v += math.Sin(2*math.Pi*150*float64(h)*t) / float64(h)
+			}
+			s[i] = float32(0.2 * env * v)
+		}
+	}
+	return s
+}
+
+// snr возвращает отношение сигнал/шум got относительно clean в dB.
+func snr(clean, got []float32) float64 {
+	var signal, noise float64
+	for i := range clean {
+		d := float64(got[i] - clean[i])
+		signal += float64(clean[i]) * float64(clean[i])
+		noise += d * d
+	}
+	return 10 * math.Log10(signal/noise)
+}
+
+func TestDenoiseImprovesSNR(t *testing.T) {
+	clean := syntheticSpeech(4)
+	rng := rand.New(rand.NewSource(1))
+	noisy := make([]float32, len(clean))
+	for i := range noisy {
+		noisy[i] = clean[i] + float32(0.03*rng.NormFloat64())
+	}
+
+	out := Denoise(noisy)
+	if len(out) != len(noisy) {
+		t.Fatalf("len = %d, want %d", len(out), len(noisy))
+	}
+	before, after := snr(clean, noisy), snr(clean, out)
+	t.Logf("SNR %.1f dB -> %.1f dB", before, after)
+	if after < before+5 {
+		t.Errorf("SNR improved from %.1f to %.1f dB, want at least +5 dB", before, after)
+	}
+}
+
+func TestDenoiseShort(t *testing.T) {
+	short := make([]float32, minNoiseFrames*denoiseHop)
+	short[0] = 1
+	if out := Denoise(short); &out[0] != &short[0] {
+		t.Error("short recording was processed")
+	}
+}
+
+func TestDenoiseSilence(t *testing.T) {
+	silence := make([]float32, SampleRate)
+	out := Denoise(silence)
+	for i, v := range out {
+		if v != 0 {
+			t.Fatalf("sample %d = %v, want 0", i, v)
+		}
+	}
+}
@@ -0,0 +1,98 @@
+package audio
+
+import (
+	"bytes"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/gordonklaus/portaudio"
+)
+
+// playMu не даёт звукам накладываться друг на друга.
+var playMu sync.Mutex
+
+// Play воспроизводит WAV на устройстве вывода по умолчанию.
+// Не блокирует: звук играет в отдельной горутине, ошибки только логируются.
+func Play(wav []byte) {
+	go func() {
+		if err := play(wav); err != nil {
+			log.Printf("Не удалось воспроизвести звук: %v", err)
+		}
+	}()
+}
+
+// Duration возвращает длительность WAV, 0 если файл не читается.
+func Duration(wav []byte) time.Duration {
+	samples, err := DecodeWAV(bytes.NewReader(wav))
+	if err != nil {
+		return 0
+	}
+	return time.Duration(len(samples)) * time.Second / SampleRate
+}
+
+func play(wav []byte) error {
+	samples, err := DecodeWAV(bytes.NewReader(wav))
+	if err != nil {
+		return err
+	}
+
+	playMu.Lock()
+	defer playMu.Unlock()
+
+	// PortAudio считает вызовы Initialize/Terminate, поэтому
+	// инициализация Recorder не затрагивается
+	if err := portaudio.Initialize(); err != nil {
+		return err
+	}
+	defer portaudio.Terminate()
+
+	buffer := make([]float32, FramesPerBuffer)
+	params, err := outputParams(buffer)
+	if err != nil {
+		return err
+	}
+	if rate := int(params.SampleRate); rate != SampleRate {
+		samples = resample(samples, SampleRate, rate)
+	}
+	stream, err := portaudio.OpenStream(params, buffer)
+	if err != nil {
+		return err
+	}
+	defer stream.Close()
+
+	if err := stream.Start(); err != nil {
+		return err
+	}
+	defer stream.Stop()
+
+	for len(samples) > 0 {
+		n := copy(buffer, samples)
+		// Последний неполный буфер добиваем тишиной
+		clear(buffer[n:])
+		samples = samples[n:]
+		if err := stream.Write(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// outputParams подбирает параметры потока для устройства вывода по умолчанию.
+// Многие устройства не открываются на SampleRate - тогда звук играет на
+// родной частоте устройства.
+func outputParams(buffer []float32) (portaudio.StreamParameters, error) {
+	dev, err := portaudio.DefaultOutputDevice()
+	if err != nil {
+		return portaudio.StreamParameters{}, err
+	}
+
+	params := portaudio.LowLatencyParameters(nil, dev)
+	params.Output.Channels = Channels
+	params.FramesPerBuffer = FramesPerBuffer
+	params.SampleRate = SampleRate
+	if portaudio.IsFormatSupported(params, buffer) != nil && dev.DefaultSampleRate > 0 {
+		params.SampleRate = dev.DefaultSampleRate
+	}
+	return params, nil
+}
@@ -2,6 +2,8 @@
 package audio
 
 import (
+	"errors"
+	"log"
 	"sync"
 	"time"
 
@@ -15,59 +17,188 @@ const (
 	Channels = 1
 	// FramesPerBuffer - размер буфера.
 	FramesPerBuffer = 1024
-	// MinSamples - минимальное количество сэмплов (200ms при 16kHz).
+	// MinSamples - минимальное количество сэмплов по умолчанию (200ms при 16kHz).
 	// Whisper требует минимум 100ms, добавляем запас.
 	MinSamples = SampleRate / 5 // 3200 samples = 200ms
+	// MinPadding - нижняя граница дополнения тишиной, меньше Whisper не принимает.
+	MinPadding = 100 * time.Millisecond
+	// ClipLevel - сэмпл с амплитудой от этого значения считается клиппингом.
+	ClipLevel = 0.99
+	// MaxEdgePadding - верхняя граница тишины на каждом краю записи:
+	// больше не помогает распознаванию, а только замедляет его.
+	MaxEdgePadding = time.Second
 )
 
+// ErrUnavailable возвращается, если аудиоподсистема не инициализирована.
+var ErrUnavailable = errors.New("audio: микрофон недоступен")
+
+// ErrBusy возвращается при попытке теста микрофона во время записи.
+var ErrBusy = errors.New("audio: идёт запись")
+
 // Recorder записывает аудио с микрофона.
 type Recorder struct {
-	mu       sync.Mutex
-	stream   *portaudio.Stream
-	buffer   []float32
-	samples  []float32
-	running  bool
-	done     chan struct{}
+	mu          sync.Mutex
+	stream      *portaudio.Stream
+	buffer      []float32
+	samples     []float32
+	running     bool
+	done        chan struct{}
+	initialized bool // portaudio.Initialize выполнен успешно
+	monitoring  bool // поток открыт для теста микрофона, а не для записи
+	padSamples  int  // Stop дополняет запись тишиной до этой длины
+	leadSkip    int  // столько сэмплов с начала записи отбрасывается
+	skip        int  // сколько ещё отбросить в текущей записи
+	total       int  // сэмплов с начала записи (Flush не сбрасывает)
+	clipped     int  // из них упёрлись в ClipLevel
+	deviceRate  int  // частота потока из настроек (0 - автоматически)
+	resampler   *resampler
 }
 
 // New создаёт новый Recorder.
+// Recorder возвращается и при ошибке инициализации PortAudio: запись
+// будет недоступна до успешного вызова Reinitialize.
 func New() (*Recorder, error) {
-	if err := portaudio.Initialize(); err != nil {
-		return nil, err
+	r := &Recorder{
+		buffer:     make([]float32, FramesPerBuffer),
+		padSamples: MinSamples,
 	}
 
-	r := &Recorder{
-		buffer: make([]float32, FramesPerBuffer),
+	if err := portaudio.Initialize(); err != nil {
+		return r, err
 	}
+	r.initialized = true
 
 	return r, nil
 }
 
+// Available возвращает true если аудиоподсистема инициализирована.
+func (r *Recorder) Available() bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.initialized
+}
+
+// Reinitialize переинициализирует PortAudio, например после подключения микрофона.
+// Во время записи ничего не делает.
+func (r *Recorder) Reinitialize() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.running {
+		return nil
+	}
+	return r.reinit()
+}
+
+// reinit перезапускает PortAudio. Вызывается под r.mu, когда поток закрыт.
+func (r *Recorder) reinit() error {
+	if r.initialized {
+		portaudio.Terminate()
+		r.initialized = false
+	}
+
+	if err := portaudio.Initialize(); err != nil {
+		return err
+	}
+	r.initialized = true
+	return nil
+}
+
+// startStream открывает поток, а если это не удалось - перезапускает
+// PortAudio и пробует ещё раз: список устройств PortAudio читает только
+// при инициализации, поэтому отключённый микрофон или сменённое устройство
+// по умолчанию без перезапуска не находятся. Вызывается под r.mu.
+func (r *Recorder) startStream(monitoring bool) error {
+	err := r.openStream(monitoring)
+	if err == nil {
+		return nil
+	}
+	log.Printf("Не удалось открыть микрофон, перезапускаем PortAudio: %v", err)
+	if rerr := r.reinit(); rerr != nil {
+		return errors.Join(err, rerr)
+	}
+	return r.openStream(monitoring)
+}
+
 // Start начинает запись аудио.
+// Идущий тест микрофона останавливается: настоящая запись важнее.
 func (r *Recorder) Start() error {
 	r.mu.Lock()
+	for r.running && r.monitoring {
+		r.mu.Unlock()
+		r.stopStream(true)
+		r.mu.Lock()
+	}
 	defer r.mu.Unlock()
 
 	if r.running {
 		return nil
 	}
 
+	return r.startStream(false)
+}
+
+// StartMonitor открывает поток для теста микрофона: сэмплы доступны через
+// GetSamples, но хранится только последняя секунда. Во время записи
+// возвращает ErrBusy.
+func (r *Recorder) StartMonitor() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.running {
+		if r.monitoring {
+			return nil
+		}
+		return ErrBusy
+	}
+
+	return r.startStream(true)
+}
+
+// StopMonitor закрывает поток теста микрофона. Запись не затрагивает.
+func (r *Recorder) StopMonitor() {
+	r.stopStream(true)
+}
+
+// IsMonitoring возвращает true если идёт тест микрофона.
+func (r *Recorder) IsMonitoring() bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.running && r.monitoring
+}
+
+// openStream открывает поток и запускает recordLoop. Вызывается под r.mu.
+func (r *Recorder) openStream(monitoring bool) error {
+	if !r.initialized {
+		return ErrUnavailable
+	}
+
 	r.samples = make([]float32, 0, SampleRate*30) // Буфер на 30 сек
 	r.done = make(chan struct{})
+	r.total, r.clipped = 0, 0
+	r.skip = 0
+	if !monitoring {
+		r.skip = r.leadSkip
+	}
 
-	stream, err := portaudio.OpenDefaultStream(
-		Channels,        // input channels
-		0,               // output channels
-		SampleRate,      // sample rate
-		FramesPerBuffer, // frames per buffer
-		r.buffer,        // buffer
-	)
+	params, err := r.streamParams()
+	if err != nil {
+		return err
+	}
+	stream, err := portaudio.OpenStream(params, r.buffer)
 	if err != nil {
 		return err
 	}
 
+	// Whisper и Vosk принимают только SampleRate - остальное пересэмплируем
+	r.resampler = nil
+	if rate := int(params.SampleRate); rate != SampleRate {
+		r.resampler = newResampler(rate, SampleRate)
+	}
+
 	r.stream = stream
 	r.running = true
+	r.monitoring = monitoring
 
 	if err := stream.Start(); err != nil {
 		r.stream.Close()
@@ -80,6 +211,41 @@ func (r *Recorder) Start() error {
 	return nil
 }
 
+// SetSampleRate задаёт частоту, на которой открывается поток микрофона
+// (0 - SampleRate, а если устройство её не поддерживает - его родная частота).
+// Применяется со следующей записи.
+func (r *Recorder) SetSampleRate(rate int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.deviceRate = max(rate, 0)
+}
+
+// streamParams подбирает параметры потока для устройства ввода по умолчанию.
+// Вызывается под r.mu.
+func (r *Recorder) streamParams() (portaudio.StreamParameters, error) {
+	dev, err := portaudio.DefaultInputDevice()
+	if err != nil {
+		return portaudio.StreamParameters{}, err
+	}
+
+	params := portaudio.HighLatencyParameters(dev, nil)
+	params.Input.Channels = Channels
+	params.FramesPerBuffer = FramesPerBuffer
+
+	if r.deviceRate > 0 {
+		params.SampleRate = float64(r.deviceRate)
+		return params, nil
+	}
+
+	// Профессиональные интерфейсы часто умеют только 44.1/48kHz:
+	// тогда пишем на родной частоте устройства
+	params.SampleRate = SampleRate
+	if portaudio.IsFormatSupported(params, r.buffer) != nil && dev.DefaultSampleRate > 0 {
+		params.SampleRate = dev.DefaultSampleRate
+	}
+	return params, nil
+}
+
 func (r *Recorder) recordLoop() {
 	defer func() {
 		close(r.done)
@@ -136,24 +302,122 @@ func (r *Recorder) recordLoop() {
 
 		r.mu.Lock()
 		if r.running {
-			bufCopy := make([]float32, len(r.buffer))
-			copy(bufCopy, r.buffer)
+			var bufCopy []float32
+			if r.resampler != nil {
+				bufCopy = r.resampler.process(r.buffer)
+			} else {
+				bufCopy = make([]float32, len(r.buffer))
+				copy(bufCopy, r.buffer)
+			}
+			// Начало записи со звуком сигнала отбрасываем
+			if r.skip > 0 {
+				n := min(r.skip, len(bufCopy))
+				bufCopy = bufCopy[n:]
+				r.skip -= n
+			}
 			r.samples = append(r.samples, bufCopy...)
+			r.total += len(bufCopy)
+			r.clipped += countClipped(bufCopy)
+			// Для теста микрофона достаточно последней секунды
+			if r.monitoring && len(r.samples) > SampleRate {
+				r.samples = append(r.samples[:0], r.samples[len(r.samples)-SampleRate:]...)
+			}
 		}
 		r.mu.Unlock()
 	}
 }
 
+// SetPadding задаёт длительность, до которой Stop дополняет короткую запись
+// тишиной. Значения меньше MinPadding поднимаются до MinPadding.
+func (r *Recorder) SetPadding(d time.Duration) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.padSamples = PaddingSamples(d)
+}
+
+// SetLeadSkip задаёт, сколько отбрасывать с начала записи: там звучит
+// сигнал начала, который микрофон тоже слышит. Применяется со следующей записи.
+func (r *Recorder) SetLeadSkip(d time.Duration) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.leadSkip = int(d * SampleRate / time.Second)
+}
+
 // Stop останавливает запись и возвращает записанные сэмплы.
 // Если запись слишком короткая, добавляет тишину для Whisper.
 func (r *Recorder) Stop() []float32 {
+	samples := r.stopStream(false)
+	if samples == nil {
+		return nil
+	}
+	r.mu.Lock()
+	padSamples := r.padSamples
+	r.mu.Unlock()
+	return PadSilence(samples, padSamples)
+}
+
+// Flush возвращает накопленные сэмплы и очищает буфер, не останавливая запись.
+// Используется в режиме диктовки для разбиения записи на фразы.
+func (r *Recorder) Flush() []float32 {
 	r.mu.Lock()
-	if !r.running {
+	defer r.mu.Unlock()
+
+	if !r.running || r.monitoring {
+		return nil
+	}
+
+	samples := r.samples
+	r.samples = make([]float32, 0, SampleRate*30)
+	return samples
+}
+
+// PaddingSamples переводит длительность дополнения тишиной в сэмплы,
+// не опускаясь ниже MinPadding.
+func PaddingSamples(d time.Duration) int {
+	d = max(d, MinPadding)
+	return int(d * SampleRate / time.Second)
+}
+
+// PadSilence дополняет слишком короткую запись тишиной до minSamples (для Whisper).
+func PadSilence(samples []float32, minSamples int) []float32 {
+	if len(samples) < minSamples {
+		padding := make([]float32, minSamples-len(samples))
+		samples = append(samples, padding...)
+	}
+	return samples
+}
+
+// EdgePaddingSamples переводит длительность тишины на краю записи в сэмплы,
+// ограничивая её диапазоном от нуля до MaxEdgePadding.
+func EdgePaddingSamples(d time.Duration) int {
+	d = min(max(d, 0), MaxEdgePadding)
+	return int(d * SampleRate / time.Second)
+}
+
+// PadEdges добавляет lead сэмплов тишины в начало записи и trail в конец:
+// без тишины в начале Whisper иногда обрезает первое слово.
+// Возвращает новый срез, исходный не меняется.
+func PadEdges(samples []float32, lead, trail int) []float32 {
+	if lead <= 0 && trail <= 0 {
+		return samples
+	}
+	lead, trail = max(lead, 0), max(trail, 0)
+	padded := make([]float32, lead+len(samples)+trail)
+	copy(padded[lead:], samples)
+	return padded
+}
+
+// stopStream останавливает поток и возвращает накопленные сэмплы.
+// При monitorOnly останавливает только тест микрофона.
+func (r *Recorder) stopStream(monitorOnly bool) []float32 {
+	r.mu.Lock()
+	if !r.running || (monitorOnly && !r.monitoring) {
 		r.mu.Unlock()
 		return nil
 	}
 
 	r.running = false
+	r.monitoring = false
 	stream := r.stream
 	r.stream = nil
 	samples := r.samples
@@ -175,26 +439,49 @@ func (r *Recorder) Stop() []float32 {
 		stream.Close()
 	}
 
-	// Добавляем тишину если запись слишком короткая
-	if len(samples) < MinSamples {
-		padding := make([]float32, MinSamples-len(samples))
-		samples = append(samples, padding...)
-	}
-
 	return samples
 }
 
 // Close освобождает ресурсы.
 func (r *Recorder) Close() {
 	r.Stop()
-	portaudio.Terminate()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.initialized {
+		portaudio.Terminate()
+		r.initialized = false
+	}
 }
 
-// IsRecording возвращает true если идёт запись.
+// IsRecording возвращает true если идёт запись (тест микрофона не считается).
 func (r *Recorder) IsRecording() bool {
 	r.mu.Lock()
 	defer r.mu.Unlock()
-	return r.running
+	return r.running && !r.monitoring
+}
+
+// ClipRatio возвращает долю сэмплов текущей записи, упёршихся в предел
+// амплитуды (ClipLevel). Заметная доля означает, что микрофон слишком
+// громкий и звук искажён - это ухудшает распознавание.
+func (r *Recorder) ClipRatio() float64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.total == 0 {
+		return 0
+	}
+	return float64(r.clipped) / float64(r.total)
+}
+
+// countClipped считает сэмплы с амплитудой не меньше ClipLevel.
+func countClipped(samples []float32) int {
+	n := 0
+	for _, s := range samples {
+		if s >= ClipLevel || s <= -ClipLevel {
+			n++
+		}
+	}
+	return n
 }
 
 // GetSamples возвращает копию текущих записанных сэмплов без остановки записи.
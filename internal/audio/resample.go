@@ -0,0 +1,111 @@
+package audio
+
+import "math"
+
+const (
+	// aliasTaps - длина фильтра нижних частот перед понижением частоты.
+	// Нечётная, чтобы задержка фильтра была целым числом сэмплов.
+	aliasTaps = 63
+	// aliasCutoff - доля новой частоты Найквиста, которую пропускает фильтр:
+	// запас до неё нужен на переходную полосу.
+	aliasCutoff = 0.9
+)
+
+// resampler переводит поток сэмплов с частоты устройства в SampleRate
+// по частям: состояние между буферами сохраняется, поэтому на стыках нет
+// щелчков и потерь. При понижении частоты сигнал сначала проходит
+// фильтр нижних частот, иначе частоты выше новой частоты Найквиста
+// (шипящие, шум) отражаются в речевую полосу. Затем сэмплы берутся
+// линейной интерполяцией.
+type resampler struct {
+	step float64 // входных сэмплов на один выходной
+	pos  float64 // позиция следующего выходного сэмпла относительно начала буфера
+	prev float32 // последний сэмпл предыдущего буфера (позиция -1)
+
+	fir  []float32 // коэффициенты фильтра, nil при повышении частоты
+	hist []float32 // последние aliasTaps-1 входных сэмплов для фильтра
+}
+
+// newResampler создаёт resampler с частоты from на частоту to.
+func newResampler(from, to int) *resampler {
+	r := &resampler{step: float64(from) / float64(to)}
+	if from > to {
+		r.fir = lowpass(aliasCutoff*0.5*float64(to)/float64(from), aliasTaps)
+		r.hist = make([]float32, aliasTaps-1)
+		// Фильтр задерживает сигнал на половину длины: первый выходной
+		// сэмпл берём там, где начинается сам сигнал
+		r.pos = aliasTaps / 2
+	}
+	return r
+}
+
+// lowpass возвращает фильтр нижних частот (sinc с окном Блэкмана) длиной
+// taps с частотой среза cutoff в долях частоты дискретизации.
+// Сумма коэффициентов - 1, чтобы не менять громкость.
+func lowpass(cutoff float64, taps int) []float32 {
+	h := make([]float64, taps)
+	sum := 0.0
+	mid := float64(taps-1) / 2
+	for i := range h {
+		x := float64(i) - mid
+		sinc := 2 * cutoff
+		if x != 0 {
+			sinc = math.Sin(2*math.Pi*cutoff*x) / (math.Pi * x)
+		}
+		t := 2 * math.Pi * float64(i) / float64(taps-1)
+		h[i] = sinc * (0.42 - 0.5*math.Cos(t) + 0.08*math.Cos(2*t))
+		sum += h[i]
+	}
+	fir := make([]float32, taps)
+	for i := range h {
+		fir[i] = float32(h[i] / sum)
+	}
+	return fir
+}
+
+// filter пропускает буфер через фильтр нижних частот. Выход задержан
+// на aliasTaps/2 сэмплов, недостающее начало берётся из прошлых буферов.
+func (r *resampler) filter(in []float32) []float32 {
+	buf := make([]float32, 0, len(r.hist)+len(in))
+	buf = append(append(buf, r.hist...), in...)
+	out := make([]float32, len(in))
+	for i := range out {
+		var acc float32
+		for k, c := range r.fir {
+			acc += c * buf[i+k]
+		}
+		out[i] = acc
+	}
+	copy(r.hist, buf[len(buf)-len(r.hist):])
+	return out
+}
+
+// process пересэмплирует очередной буфер. Результат - новый срез.
+func (r *resampler) process(in []float32) []float32 {
+	if len(in) == 0 {
+		return nil
+	}
+	if r.fir != nil {
+		in = r.filter(in)
+	}
+
+	out := make([]float32, 0, int(float64(len(in))/r.step)+1)
+	last := float64(len(in) - 1)
+	for ; r.pos <= last; r.pos += r.step {
+		// pos >= -1: между prev и in[0] интерполируем от prev
+		j := int(r.pos + 1)
+		frac := float32(r.pos + 1 - float64(j))
+		a := r.prev
+		if j > 0 {
+			a = in[j-1]
+		}
+		if j >= len(in) {
+			out = append(out, a)
+			continue
+		}
+		out = append(out, a*(1-frac)+in[j]*frac)
+	}
+	r.pos -= float64(len(in))
+	r.prev = in[len(in)-1]
+	return out
+}
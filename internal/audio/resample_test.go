@@ -0,0 +1,86 @@
+package audio
+
+import (
+	"math"
+	"testing"
+)
+
+func sine(freq float64, rate, n int) []float32 {
+	s := make([]float32, n)
+	for i := range s {
+		s[i] = float32(0.5 * math.Sin(2*math.Pi*freq*float64(i)/float64(rate)))
+	}
+	return s
+}
+
+// toneLevel возвращает амплитуду частоты freq в сигнале (алгоритм Гёрцеля).
+func toneLevel(s []float32, freq float64, rate int) float64 {
+	w := 2 * math.Pi * freq / float64(rate)
+	coeff := 2 * math.Cos(w)
+	var s1, s2 float64
+	for _, x := range s {
+		s1, s2 = float64(x)+coeff*s1-s2, s1
+	}
+	power := s1*s1 + s2*s2 - coeff*s1*s2
+	return 2 * math.Sqrt(math.Max(power, 0)) / float64(len(s))
+}
+
+// processChunks пропускает сигнал через resampler буферами по size сэмплов.
+func processChunks(r *resampler, in []float32, size int) []float32 {
+	var out []float32
+	for start := 0; start < len(in); start += size {
+		out = append(out, r.process(in[start:min(start+size, len(in))])...)
+	}
+	return out
+}
+
+func TestResamplerLength(t *testing.T) {
+	for _, from := range []int{8000, 22050, 44100, 48000, 96000} {
+		in := make([]float32, from) // одна секунда
+		out := processChunks(newResampler(from, SampleRate), in, 441)
+		// Фильтр задерживает конец записи на доли миллисекунды
+		if diff := SampleRate - len(out); diff < 0 || diff > aliasTaps {
+			t.Errorf("%d Hz: %d samples for one second, want about %d", from, len(out), SampleRate)
+		}
+		if got := len(resample(in, from, SampleRate)); got != SampleRate {
+			t.Errorf("resample %d Hz: %d samples, want %d", from, got, SampleRate)
+		}
+	}
+}
+
+func TestResamplerPreservesFrequency(t *testing.T) {
+	for _, from := range []int{44100, 48000} {
+		out := processChunks(newResampler(from, SampleRate), sine(1000, from, from), 512)
+		// Начало без переходного процесса фильтра
+		out = out[aliasTaps:]
+		if level := toneLevel(out, 1000, SampleRate); level < 0.45 || level > 0.55 {
+			t.Errorf("%d Hz: 1 kHz level %.3f, want 0.5", from, level)
+		}
+		if level := toneLevel(out, 1100, SampleRate); level > 0.05 {
+			t.Errorf("%d Hz: energy leaked to 1.1 kHz: %.3f", from, level)
+		}
+	}
+}
+
+func TestResamplerAntiAlias(t *testing.T) {
+	// 10 kHz выше частоты Найквиста 16 kHz и без фильтра отразился бы в 6 kHz
+	out := resample(sine(10000, 48000, 48000), 48000, SampleRate)
+	if level := toneLevel(out[aliasTaps:], 6000, SampleRate); level > 0.01 {
+		t.Errorf("10 kHz aliased to 6 kHz with level %.3f", level)
+	}
+}
+
+// TestResamplerChunked проверяет, что разбиение на буферы не меняет результат.
+func TestResamplerChunked(t *testing.T) {
+	in := sine(440, 44100, 44100)
+	whole := newResampler(44100, SampleRate).process(in)
+	chunked := processChunks(newResampler(44100, SampleRate), in, 100)
+	if len(whole) != len(chunked) {
+		t.Fatalf("lengths differ: %d vs %d", len(whole), len(chunked))
+	}
+	for i := range whole {
+		if math.Abs(float64(whole[i]-chunked[i])) > 1e-5 {
+			t.Fatalf("sample %d: %v vs %v", i, whole[i], chunked[i])
+		}
+	}
+}
@@ -0,0 +1,95 @@
+package audio
+
+import (
+	"math"
+	"time"
+)
+
+const (
+	// SilenceThreshold - RMS ниже этого значения считается тишиной.
+	SilenceThreshold = 0.01
+	// MuteLevel - если ни один сэмпл записи не достиг этой амплитуды,
+	// микрофон, скорее всего, выключен: даже тихая комната даёт шум выше.
+	MuteLevel = 1e-4
+	// silenceFrame - размер кадра анализа тишины (30ms при 16kHz).
+	silenceFrame = SampleRate * 30 / 1000
+	// trimGuard - тишина, оставляемая TrimSilence по краям речи (150ms),
+	// чтобы не срезать тихие начала и окончания слов.
+	trimGuard = SampleRate * 150 / 1000
+)
+
+// TrailingSilence возвращает длительность тишины в конце записи и
+// признак того, что до неё была речь.
+func TrailingSilence(samples []float32) (silence time.Duration, hasSpeech bool) {
+	end := len(samples)
+	for end >= silenceFrame {
+		if frameRMS(samples[end-silenceFrame:end]) >= SilenceThreshold {
+			hasSpeech = true
+			break
+		}
+		end -= silenceFrame
+	}
+
+	silent := len(samples) - end
+	return time.Duration(silent) * time.Second / SampleRate, hasSpeech
+}
+
+// TrimSilence обрезает тишину в начале и в конце записи. Окно размером
+// в кадр сдвигается на полкадра; окна с RMS ниже threshold считаются тишиной.
+// По краям речи остаётся запас trimGuard. Если речи нет совсем, запись
+// возвращается как есть - пустой результат определит распознавание.
+// Результат - срез исходного буфера с ограниченной ёмкостью: append к нему
+// не затирает исходные сэмплы.
+func TrimSilence(samples []float32, threshold float32) []float32 {
+	const hop = silenceFrame / 2
+	if len(samples) < silenceFrame {
+		return samples
+	}
+
+	start := -1
+	for i := 0; i+silenceFrame <= len(samples); i += hop {
+		if frameRMS(samples[i:i+silenceFrame]) >= float64(threshold) {
+			start = i
+			break
+		}
+	}
+	if start < 0 {
+		return samples
+	}
+
+	end := start + silenceFrame
+	for i := len(samples) - silenceFrame; i > start; i -= hop {
+		if frameRMS(samples[i:i+silenceFrame]) >= float64(threshold) {
+			end = i + silenceFrame
+			break
+		}
+	}
+
+	start = max(0, start-trimGuard)
+	end = min(len(samples), end+trimGuard)
+	return samples[start:end:end]
+}
+
+// IsMuted возвращает true если вся запись - цифровая тишина: так выглядит
+// звук с выключенного (в том числе аппаратно) микрофона.
+// Пустая запись выключенным микрофоном не считается.
+func IsMuted(samples []float32) bool {
+	if len(samples) == 0 {
+		return false
+	}
+	for _, s := range samples {
+		if s >= MuteLevel || s <= -MuteLevel {
+			return false
+		}
+	}
+	return true
+}
+
+// frameRMS вычисляет среднеквадратичное значение кадра.
+func frameRMS(frame []float32) float64 {
+	var sum float64
+	for _, s := range frame {
+		sum += float64(s) * float64(s)
+	}
+	return math.Sqrt(sum / float64(len(frame)))
+}
@@ -0,0 +1,223 @@
+package audio
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+)
+
+// Форматы сэмплов WAV.
+const (
+	wavFormatPCM        = 1
+	wavFormatFloat      = 3
+	wavFormatExtensible = 0xFFFE
+)
+
+// ErrInvalidWAV возвращается для файлов, которые не являются поддерживаемым WAV.
+var ErrInvalidWAV = errors.New("audio: неподдерживаемый WAV файл")
+
+// wavFormat - содержимое чанка "fmt ".
+type wavFormat struct {
+	format        uint16
+	channels      int
+	sampleRate    int
+	bitsPerSample int
+}
+
+// ReadWAVFile читает WAV файл и возвращает сэмплы в формате распознавания:
+// float32, 16kHz, mono.
+func ReadWAVFile(path string) ([]float32, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return DecodeWAV(f)
+}
+
+// DecodeWAV декодирует WAV (PCM 8/16/24/32 бит или float32) и приводит его
+// к 16kHz mono: каналы усредняются, частота меняется линейной интерполяцией.
+func DecodeWAV(r io.Reader) ([]float32, error) {
+	var header [12]byte
+	if _, err := io.ReadFull(r, header[:]); err != nil {
+		return nil, ErrInvalidWAV
+	}
+	if string(header[0:4]) != "RIFF" || string(header[8:12]) != "WAVE" {
+		return nil, ErrInvalidWAV
+	}
+
+	var format *wavFormat
+	for {
+		var chunk [8]byte
+		if _, err := io.ReadFull(r, chunk[:]); err != nil {
+			return nil, fmt.Errorf("%w: нет данных", ErrInvalidWAV)
+		}
+		id := string(chunk[0:4])
+		size := int64(binary.LittleEndian.Uint32(chunk[4:8]))
+
+		switch id {
+		case "fmt ":
+			if size > 1024 {
+				return nil, ErrInvalidWAV
+			}
+			body := make([]byte, size)
+			if _, err := io.ReadFull(r, body); err != nil {
+				return nil, ErrInvalidWAV
+			}
+			f, err := parseWAVFormat(body)
+			if err != nil {
+				return nil, err
+			}
+			format = f
+		case "data":
+			if format == nil {
+				return nil, fmt.Errorf("%w: data до fmt", ErrInvalidWAV)
+			}
+			// Обрезанный файл (например, запись прервана) декодируем как есть
+			data, err := io.ReadAll(io.LimitReader(r, size))
+			if err != nil {
+				return nil, err
+			}
+			samples := decodeWAVSamples(data, format)
+			return resample(samples, format.sampleRate, SampleRate), nil
+		default:
+			if _, err := io.CopyN(io.Discard, r, size); err != nil {
+				return nil, ErrInvalidWAV
+			}
+		}
+
+		// Чанки выровнены по двум байтам
+		if size%2 == 1 {
+			if _, err := io.CopyN(io.Discard, r, 1); err != nil {
+				return nil, ErrInvalidWAV
+			}
+		}
+	}
+}
+
+func parseWAVFormat(body []byte) (*wavFormat, error) {
+	if len(body) < 16 {
+		return nil, ErrInvalidWAV
+	}
+	f := &wavFormat{
+		format:        binary.LittleEndian.Uint16(body[0:2]),
+		channels:      int(binary.LittleEndian.Uint16(body[2:4])),
+		sampleRate:    int(binary.LittleEndian.Uint32(body[4:8])),
+		bitsPerSample: int(binary.LittleEndian.Uint16(body[14:16])),
+	}
+	// В WAVE_FORMAT_EXTENSIBLE настоящий формат - первые 2 байта SubFormat GUID
+	if f.format == wavFormatExtensible && len(body) >= 26 {
+		f.format = binary.LittleEndian.Uint16(body[24:26])
+	}
+
+	if f.channels < 1 || f.sampleRate < 1 {
+		return nil, ErrInvalidWAV
+	}
+	switch {
+	case f.format == wavFormatPCM && (f.bitsPerSample == 8 || f.bitsPerSample == 16 ||
+		f.bitsPerSample == 24 || f.bitsPerSample == 32):
+	case f.format == wavFormatFloat && f.bitsPerSample == 32:
+	default:
+		return nil, fmt.Errorf("%w: формат %d, %d бит", ErrInvalidWAV, f.format, f.bitsPerSample)
+	}
+	return f, nil
+}
+
+// WriteWAVFile сохраняет сэмплы (float32, SampleRate, mono) в WAV файл PCM16.
+func WriteWAVFile(path string, samples []float32) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := EncodeWAV(f, samples, SampleRate); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// EncodeWAV записывает mono сэмплы float32 [-1, 1] как WAV PCM16.
+// Значения за пределами диапазона обрезаются.
+func EncodeWAV(w io.Writer, samples []float32, sampleRate int) error {
+	dataSize := len(samples) * 2
+	header := make([]byte, 44)
+	copy(header[0:4], "RIFF")
+	binary.LittleEndian.PutUint32(header[4:8], uint32(36+dataSize))
+	copy(header[8:12], "WAVE")
+	copy(header[12:16], "fmt ")
+	binary.LittleEndian.PutUint32(header[16:20], 16)
+	binary.LittleEndian.PutUint16(header[20:22], wavFormatPCM)
+	binary.LittleEndian.PutUint16(header[22:24], 1)
+	binary.LittleEndian.PutUint32(header[24:28], uint32(sampleRate))
+	binary.LittleEndian.PutUint32(header[28:32], uint32(sampleRate*2))
+	binary.LittleEndian.PutUint16(header[32:34], 2)
+	binary.LittleEndian.PutUint16(header[34:36], 16)
+	copy(header[36:40], "data")
+	binary.LittleEndian.PutUint32(header[40:44], uint32(dataSize))
+	if _, err := w.Write(header); err != nil {
+		return err
+	}
+
+	data := make([]byte, dataSize)
+	for i, s := range samples {
+		s = max(-1, min(1, s))
+		binary.LittleEndian.PutUint16(data[i*2:], uint16(int16(s*math.MaxInt16)))
+	}
+	_, err := w.Write(data)
+	return err
+}
+
+// decodeWAVSamples переводит сэмплы в float32 [-1, 1] и сводит каналы в mono.
+func decodeWAVSamples(data []byte, f *wavFormat) []float32 {
+	bytesPerSample := f.bitsPerSample / 8
+	frameSize := bytesPerSample * f.channels
+	frames := len(data) / frameSize
+
+	samples := make([]float32, frames)
+	for i := 0; i < frames; i++ {
+		var sum float32
+		for ch := 0; ch < f.channels; ch++ {
+			off := i*frameSize + ch*bytesPerSample
+			sum += decodeWAVSample(data[off:off+bytesPerSample], f.format)
+		}
+		samples[i] = sum / float32(f.channels)
+	}
+	return samples
+}
+
+func decodeWAVSample(b []byte, format uint16) float32 {
+	if format == wavFormatFloat {
+		return math.Float32frombits(binary.LittleEndian.Uint32(b))
+	}
+	switch len(b) {
+	case 1:
+		// 8-битный PCM беззнаковый
+		return (float32(b[0]) - 128) / 128
+	case 2:
+		return float32(int16(binary.LittleEndian.Uint16(b))) / 32768
+	case 3:
+		v := int32(b[0]) | int32(b[1])<<8 | int32(int8(b[2]))<<16
+		return float32(v) / 8388608
+	default:
+		return float32(int32(binary.LittleEndian.Uint32(b))) / 2147483648
+	}
+}
+
+// resample меняет частоту дискретизации целой записи тем же resampler,
+// что и запись с микрофона. Конец записи дополняется тишиной на задержку
+// фильтра, чтобы последние сэмплы не застряли в нём.
+func resample(samples []float32, from, to int) []float32 {
+	if from == to || len(samples) == 0 {
+		return samples
+	}
+
+	n := int(int64(len(samples)) * int64(to) / int64(from))
+	padded := make([]float32, len(samples)+aliasTaps/2)
+	copy(padded, samples)
+	out := newResampler(from, to).process(padded)
+	return out[:min(n, len(out))]
+}
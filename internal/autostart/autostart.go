@@ -0,0 +1,27 @@
+// Package autostart включает и выключает запуск приложения при входе
+// в систему. Состояние не хранится в конфигурации: источник истины -
+// сама ОС (файл автозапуска или ключ реестра), поэтому ручные изменения
+// пользователя сразу видны в меню.
+package autostart
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// appName - имя записи автозапуска.
+const appName = "Shofar"
+
+// executable возвращает путь к бинарнику с разрешёнными симлинками.
+func executable() (string, error) {
+	path, err := os.Executable()
+	if err != nil {
+		return "", fmt.Errorf("не удалось определить путь к бинарнику: %w", err)
+	}
+	path, err = filepath.EvalSymlinks(path)
+	if err != nil {
+		return "", fmt.Errorf("не удалось разрешить симлинки: %w", err)
+	}
+	return path, nil
+}
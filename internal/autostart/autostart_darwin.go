@@ -0,0 +1,77 @@
+//go:build darwin
+
+package autostart
+
+import (
+	"fmt"
+	"html"
+	"os"
+	"path/filepath"
+)
+
+// label - идентификатор LaunchAgent.
+const label = "com.rootooz.shofar"
+
+// plistPath возвращает путь к LaunchAgent в ~/Library/LaunchAgents.
+func plistPath() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, "Library", "LaunchAgents", label+".plist"), nil
+}
+
+// Enable добавляет приложение в автозапуск. launchd читает LaunchAgents
+// при входе, поэтому до следующего входа ничего не запускается.
+func Enable() error {
+	exe, err := executable()
+	if err != nil {
+		return err
+	}
+	path, err := plistPath()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+
+	plist := fmt.Sprintf(`<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE plist PUBLIC "-//Apple//DTD PLIST 1.0//EN" "http://www.apple.com/DTDs/PropertyList-1.0.dtd">
+<plist version="1.0">
+<dict>
+	<key>Label</key>
+	<string>%s</string>
+	<key>ProgramArguments</key>
+	<array>
+		<string>%s</string>
+	</array>
+	<key>RunAtLoad</key>
+	<true/>
+</dict>
+</plist>
+`, label, html.EscapeString(exe))
+	return os.WriteFile(path, []byte(plist), 0644)
+}
+
+// Disable убирает приложение из автозапуска.
+func Disable() error {
+	path, err := plistPath()
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// IsEnabled возвращает true если приложение запускается при входе.
+func IsEnabled() bool {
+	path, err := plistPath()
+	if err != nil {
+		return false
+	}
+	_, err = os.Stat(path)
+	return err == nil
+}
@@ -0,0 +1,87 @@
+//go:build linux
+
+package autostart
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// desktopPath возвращает путь к .desktop файлу в ~/.config/autostart
+// (с учётом XDG_CONFIG_HOME).
+func desktopPath() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "autostart", "shofar.desktop"), nil
+}
+
+// Enable добавляет приложение в автозапуск.
+func Enable() error {
+	exe, err := executable()
+	if err != nil {
+		return err
+	}
+	path, err := desktopPath()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+
+	entry := strings.Join([]string{
+		"[Desktop Entry]",
+		"Type=Application",
+		"Name=" + appName,
+		"Comment=Voice to text",
+		"Exec=" + quoteExec(exe),
+		"Terminal=false",
+		"X-GNOME-Autostart-enabled=true",
+		"",
+	}, "\n")
+	return os.WriteFile(path, []byte(entry), 0644)
+}
+
+// quoteExec заключает путь в кавычки по правилам ключа Exec из
+// спецификации Desktop Entry: внутри кавычек экранируются ", `, $ и \.
+func quoteExec(path string) string {
+	if !strings.ContainsAny(path, " \t\n\"'\\><~|&;$*?#()`") {
+		return path
+	}
+	r := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "`", "\\`", `$`, `\$`)
+	return `"` + r.Replace(path) + `"`
+}
+
+// Disable убирает приложение из автозапуска.
+func Disable() error {
+	path, err := desktopPath()
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// IsEnabled возвращает true если приложение запускается при входе.
+// Запись, отключённая через Hidden=true, считается выключенной.
+func IsEnabled() bool {
+	path, err := desktopPath()
+	if err != nil {
+		return false
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return false
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		if strings.TrimSpace(line) == "Hidden=true" {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,23 @@
+//go:build !linux && !darwin && !windows
+
+package autostart
+
+import "errors"
+
+// errUnsupported - автозапуск не реализован для этой ОС.
+var errUnsupported = errors.New("автозапуск не поддерживается на этой системе")
+
+// Enable добавляет приложение в автозапуск.
+func Enable() error {
+	return errUnsupported
+}
+
+// Disable убирает приложение из автозапуска.
+func Disable() error {
+	return errUnsupported
+}
+
+// IsEnabled возвращает true если приложение запускается при входе.
+func IsEnabled() bool {
+	return false
+}
@@ -0,0 +1,58 @@
+//go:build windows
+
+package autostart
+
+import (
+	"errors"
+	"fmt"
+
+	"golang.org/x/sys/windows/registry"
+)
+
+// runKey - ключ автозапуска текущего пользователя.
+const runKey = `Software\Microsoft\Windows\CurrentVersion\Run`
+
+// Enable добавляет приложение в автозапуск.
+func Enable() error {
+	exe, err := executable()
+	if err != nil {
+		return err
+	}
+	key, _, err := registry.CreateKey(registry.CURRENT_USER, runKey, registry.SET_VALUE)
+	if err != nil {
+		return fmt.Errorf("открытие ключа автозапуска: %w", err)
+	}
+	defer key.Close()
+
+	// Путь в кавычках: в нём могут быть пробелы
+	return key.SetStringValue(appName, `"`+exe+`"`)
+}
+
+// Disable убирает приложение из автозапуска.
+func Disable() error {
+	key, err := registry.OpenKey(registry.CURRENT_USER, runKey, registry.SET_VALUE)
+	if errors.Is(err, registry.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("открытие ключа автозапуска: %w", err)
+	}
+	defer key.Close()
+
+	if err := key.DeleteValue(appName); err != nil && !errors.Is(err, registry.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// IsEnabled возвращает true если приложение запускается при входе.
+func IsEnabled() bool {
+	key, err := registry.OpenKey(registry.CURRENT_USER, runKey, registry.QUERY_VALUE)
+	if err != nil {
+		return false
+	}
+	defer key.Close()
+
+	_, _, err = key.GetStringValue(appName)
+	return err == nil
+}
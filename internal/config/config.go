@@ -2,12 +2,53 @@
 package config
 
 import (
+	"crypto/rand"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"os"
 	"path/filepath"
+	"runtime"
+	"strings"
 	"sync"
 )
 
+// DefaultControlServerPort - порт локального сервера управления по умолчанию.
+const DefaultControlServerPort = 8765
+
+// DefaultInsertDelayMs - задержка перед вставкой текста по умолчанию (мс).
+// Даёт время закрыть окно результата и вернуть фокус в целевое приложение.
+const DefaultInsertDelayMs = 150
+
+// DefaultMinRecordingMs - записи короче этого (мс) не распознаются.
+const DefaultMinRecordingMs = 500
+
+// DefaultSilencePadMs - короткая запись дополняется тишиной до этой длины (мс).
+const DefaultSilencePadMs = 200
+
+// DefaultEdgePadMs - тишина, которая добавляется в начало и в конец каждой
+// записи (мс), чтобы движок не обрезал первое и последнее слово.
+const DefaultEdgePadMs = 100
+
+// DefaultHotkeyDebounceMs - повторные нажатия горячей клавиши чаще этого (мс)
+// игнорируются.
+const DefaultHotkeyDebounceMs = 300
+
+// DefaultFollowupTimeoutMs - сколько после ведущего сочетания ждать
+// вторую клавишу последовательности (мс).
+const DefaultFollowupTimeoutMs = 1000
+
+// DefaultNotifyMaxChars - длина текста уведомления по умолчанию (в символах).
+const DefaultNotifyMaxChars = 100
+
+// DefaultLLMContextSize - размер контекста LLM (n_ctx) по умолчанию в токенах.
+const DefaultLLMContextSize = 2048
+
+// DefaultLLMGPULayers - число слоёв LLM на GPU по умолчанию:
+// отрицательное значение оставляет выбор llama.cpp.
+const DefaultLLMGPULayers = -1
+
 // Modifier представляет модификатор клавиши.
 type Modifier string
 
@@ -25,6 +66,7 @@ const (
 	KeySpace  Key = "space"
 	KeyReturn Key = "return"
 	KeyTab    Key = "tab"
+	KeyEscape Key = "escape"
 	KeyA      Key = "a"
 	KeyB      Key = "b"
 	KeyC      Key = "c"
@@ -51,6 +93,16 @@ const (
 	KeyX      Key = "x"
 	KeyY      Key = "y"
 	KeyZ      Key = "z"
+	Key0      Key = "0"
+	Key1      Key = "1"
+	Key2      Key = "2"
+	Key3      Key = "3"
+	Key4      Key = "4"
+	Key5      Key = "5"
+	Key6      Key = "6"
+	Key7      Key = "7"
+	Key8      Key = "8"
+	Key9      Key = "9"
 	KeyF1     Key = "f1"
 	KeyF2     Key = "f2"
 	KeyF3     Key = "f3"
@@ -63,16 +115,62 @@ const (
 	KeyF10    Key = "f10"
 	KeyF11    Key = "f11"
 	KeyF12    Key = "f12"
+
+	// Пунктуация (по названиям X11 keysym, раскладка US)
+	KeyGrave        Key = "grave"        // `
+	KeyMinus        Key = "minus"        // -
+	KeyEqual        Key = "equal"        // =
+	KeyBracketLeft  Key = "bracketleft"  // [
+	KeyBracketRight Key = "bracketright" // ]
+	KeySemicolon    Key = "semicolon"    // ;
+	KeyApostrophe   Key = "apostrophe"   // '
+	KeyComma        Key = "comma"        // ,
+	KeyPeriod       Key = "period"       // .
+	KeySlash        Key = "slash"        // /
+	KeyBackslash    Key = "backslash"    // \
+)
+
+// TriggerType - чем вызывается горячая клавиша.
+type TriggerType string
+
+const (
+	// TriggerKeyboard - сочетание клавиш (по умолчанию, и для пустого значения).
+	TriggerKeyboard TriggerType = "keyboard"
+	// TriggerMouse - кнопка мыши, например боковая. Модификаторы не учитываются.
+	TriggerMouse TriggerType = "mouse"
+)
+
+// MouseButton - кнопка мыши для TriggerMouse.
+type MouseButton string
+
+const (
+	MouseMiddle  MouseButton = "middle"
+	MouseBack    MouseButton = "back"    // боковая "назад"
+	MouseForward MouseButton = "forward" // боковая "вперёд"
 )
 
 // HotkeyConfig хранит настройки горячей клавиши.
 type HotkeyConfig struct {
-	Modifiers []Modifier `json:"modifiers"`
-	Key       Key        `json:"key"`
+	Trigger   TriggerType `json:"trigger,omitempty"`
+	Modifiers []Modifier  `json:"modifiers"`
+	Key       Key         `json:"key"`
+	Button    MouseButton `json:"button,omitempty"` // для TriggerMouse
+	// FollowupKey - вторая клавиша последовательности: запись начинается,
+	// только если её нажать вскоре после сочетания Modifiers+Key.
+	// Пусто - срабатывает само сочетание.
+	FollowupKey Key `json:"followup_key,omitempty"`
+}
+
+// IsMouse возвращает true если горячая клавиша - кнопка мыши.
+func (h HotkeyConfig) IsMouse() bool {
+	return h.Trigger == TriggerMouse
 }
 
 // String возвращает строковое представление горячей клавиши.
 func (h HotkeyConfig) String() string {
+	if h.IsMouse() {
+		return "mouse:" + string(h.Button)
+	}
 	result := ""
 	for _, m := range h.Modifiers {
 		if result != "" {
@@ -84,23 +182,285 @@ func (h HotkeyConfig) String() string {
 		result += "+"
 	}
 	result += string(h.Key)
+	if h.FollowupKey != "" {
+		result += ", " + string(h.FollowupKey)
+	}
 	return result
 }
 
+// IsValid проверяет, что горячую клавишу можно зарегистрировать.
+// Без модификаторов допускаются только функциональные клавиши F1-F12.
+func (h HotkeyConfig) IsValid() bool {
+	if h.IsMouse() {
+		switch h.Button {
+		case MouseMiddle, MouseBack, MouseForward:
+			return true
+		}
+		return false
+	}
+	if h.Key == "" {
+		return false
+	}
+	if len(h.Modifiers) > 0 {
+		return true
+	}
+	return h.Key.IsFunction()
+}
+
+// IsFunction возвращает true для функциональных клавиш F1-F12.
+func (k Key) IsFunction() bool {
+	switch k {
+	case KeyF1, KeyF2, KeyF3, KeyF4, KeyF5, KeyF6,
+		KeyF7, KeyF8, KeyF9, KeyF10, KeyF11, KeyF12:
+		return true
+	}
+	return false
+}
+
+// HotkeyPreset именованная комбинация горячей клавиши.
+type HotkeyPreset struct {
+	Name   string       `json:"name"`
+	Hotkey HotkeyConfig `json:"hotkey"`
+}
+
+// BuiltinHotkeyPresets возвращает встроенные пресеты горячих клавиш.
+func BuiltinHotkeyPresets() []HotkeyPreset {
+	return []HotkeyPreset{
+		{Name: "Ctrl+Shift+Space", Hotkey: HotkeyConfig{Modifiers: []Modifier{ModCtrl, ModShift}, Key: KeySpace}},
+		{Name: "F9", Hotkey: HotkeyConfig{Key: KeyF9}},
+		{Name: "Ctrl+`", Hotkey: HotkeyConfig{Modifiers: []Modifier{ModCtrl}, Key: KeyGrave}},
+		{Name: "Super+Space", Hotkey: HotkeyConfig{Modifiers: []Modifier{ModSuper}, Key: KeySpace}},
+	}
+}
+
 // LLMConfig хранит настройки LLM для исправления текста.
 type LLMConfig struct {
-	Enabled bool   `json:"enabled"`
-	ModelID string `json:"model_id,omitempty"` // ID модели из registry (llm-qwen2.5-0.5b)
+	Enabled     bool   `json:"enabled"`
+	ModelID     string `json:"model_id,omitempty"`     // ID модели из registry (llm-qwen2.5-0.5b)
+	ContextSize int    `json:"context_size,omitempty"` // n_ctx в токенах
+	Sampler     string `json:"sampler,omitempty"`      // greedy или balanced
+	GPULayers   int    `json:"gpu_layers"`             // слоёв на GPU: 0 - только CPU, -1 - сколько поддерживает сборка
+}
+
+// TextRules хранит детерминированные правки распознанного текста.
+// Поля совпадают с textproc.Rules.
+type TextRules struct {
+	Capitalize     bool              `json:"capitalize,omitempty"`      // заглавная первая буква
+	CollapseSpaces bool              `json:"collapse_spaces,omitempty"` // несколько пробелов подряд -> один
+	Trim           bool              `json:"trim,omitempty"`            // убрать пробелы по краям
+	Replace        map[string]string `json:"replace,omitempty"`         // фраза -> замена, без учёта регистра
+}
+
+// NewlineHandling - как переводы строк результата попадают в целевое окно
+// при вставке и копировании.
+type NewlineHandling string
+
+const (
+	// NewlineKeep - переводы строк сохраняются (Markdown, редакторы).
+	NewlineKeep NewlineHandling = "keep"
+	// NewlineSpace - каждый перевод строки заменяется пробелом (чаты, где Enter отправляет сообщение).
+	NewlineSpace NewlineHandling = "space"
+	// NewlineStrip - переводы строк удаляются.
+	NewlineStrip NewlineHandling = "strip"
+)
+
+// NewlineModes возвращает все варианты обработки переводов строк.
+func NewlineModes() []NewlineHandling {
+	return []NewlineHandling{NewlineKeep, NewlineSpace, NewlineStrip}
+}
+
+// Apply применяет обработку переводов строк к тексту. \r\n считается
+// одним переводом строки.
+func (h NewlineHandling) Apply(text string) string {
+	switch h {
+	case NewlineSpace:
+		text = strings.ReplaceAll(text, "\r\n", "\n")
+		return strings.ReplaceAll(text, "\n", " ")
+	case NewlineStrip:
+		text = strings.ReplaceAll(text, "\r\n", "\n")
+		return strings.ReplaceAll(text, "\n", "")
+	default:
+		return text
+	}
+}
+
+// NotificationStyle - какие системные уведомления показываются.
+type NotificationStyle string
+
+const (
+	// NotificationSystem - все уведомления, включая "Запись" и "Обработка".
+	NotificationSystem NotificationStyle = "system"
+	// NotificationMinimal - только результат и ошибки.
+	NotificationMinimal NotificationStyle = "minimal"
+	// NotificationOff - уведомления не показываются.
+	NotificationOff NotificationStyle = "off"
+)
+
+// NotificationStyles возвращает все стили уведомлений.
+func NotificationStyles() []NotificationStyle {
+	return []NotificationStyle{NotificationSystem, NotificationMinimal, NotificationOff}
+}
+
+// OutputTarget - куда отправляется итоговый текст.
+type OutputTarget string
+
+const (
+	// OutputActiveWindow - ввод в активное окно (по умолчанию).
+	OutputActiveWindow OutputTarget = "active-window"
+	// OutputClipboard - копирование в буфер обмена.
+	OutputClipboard OutputTarget = "clipboard"
+	// OutputFile - дописывание в файл заметок.
+	OutputFile OutputTarget = "file"
+)
+
+// OutputTargets возвращает все варианты вывода текста.
+func OutputTargets() []OutputTarget {
+	return []OutputTarget{OutputActiveWindow, OutputClipboard, OutputFile}
+}
+
+// DefaultNotesFile - файл заметок по умолчанию: отдельный на каждый день.
+// "~" - домашний каталог, {date} - дата записи (2006-01-02).
+const DefaultNotesFile = "~/Shofar/{date}.txt"
+
+// TrayIconStyle - набор иконок в трее.
+type TrayIconStyle string
+
+const (
+	// TrayIconColor - цветные иконки (по умолчанию на Linux и Windows).
+	TrayIconColor TrayIconStyle = "color"
+	// TrayIconTemplate - монохромные template-иконки (по умолчанию на macOS).
+	TrayIconTemplate TrayIconStyle = "template"
+)
+
+// TrayIconStyles возвращает все наборы иконок трея.
+func TrayIconStyles() []TrayIconStyle {
+	return []TrayIconStyle{TrayIconColor, TrayIconTemplate}
+}
+
+// WaveformSize - размер окна записи.
+type WaveformSize string
+
+const (
+	// WaveformCompact - узкая полоска для маленьких экранов.
+	WaveformCompact WaveformSize = "compact"
+	// WaveformNormal - размер по умолчанию.
+	WaveformNormal WaveformSize = "normal"
+	// WaveformLarge - крупная волна для больших мониторов.
+	WaveformLarge WaveformSize = "large"
+)
+
+// WaveformSizes возвращает все размеры окна записи.
+func WaveformSizes() []WaveformSize {
+	return []WaveformSize{WaveformCompact, WaveformNormal, WaveformLarge}
+}
+
+// RecognitionPreset - компромисс между скоростью и точностью распознавания whisper.
+type RecognitionPreset string
+
+const (
+	// RecognitionFast - без повторного декодирования неуверенных фрагментов.
+	RecognitionFast RecognitionPreset = "fast"
+	// RecognitionAccurate - неуверенные фрагменты декодируются повторно.
+	RecognitionAccurate RecognitionPreset = "accurate"
+)
+
+// RecognitionPresets возвращает все пресеты распознавания.
+func RecognitionPresets() []RecognitionPreset {
+	return []RecognitionPreset{RecognitionFast, RecognitionAccurate}
+}
+
+// LLMSampler - способ выбора следующего токена при коррекции текста.
+type LLMSampler string
+
+const (
+	// LLMSamplerGreedy - всегда самый вероятный токен: коррекция детерминирована.
+	LLMSamplerGreedy LLMSampler = "greedy"
+	// LLMSamplerBalanced - выборка с низкой температурой (temp, top_k, top_p).
+	LLMSamplerBalanced LLMSampler = "balanced"
+)
+
+// LLMSamplers возвращает все способы выбора токена.
+func LLMSamplers() []LLMSampler {
+	return []LLMSampler{LLMSamplerGreedy, LLMSamplerBalanced}
+}
+
+// WindowPosition - координаты левого верхнего угла окна на экране.
+type WindowPosition struct {
+	X, Y int
 }
 
 // configData структура для сериализации.
 type configData struct {
-	Language      string       `json:"language"`
-	UILanguage    string       `json:"ui_language,omitempty"`
-	Notifications bool         `json:"notifications"`
-	Hotkey        HotkeyConfig `json:"hotkey"`
-	ModelID       string       `json:"model_id,omitempty"`
-	LLM           LLMConfig    `json:"llm,omitempty"`
+	Version       int            `json:"version"` // версия формата, см. migrate
+	Language      string         `json:"language"`
+	UILanguage    string         `json:"ui_language,omitempty"`
+	Theme         string         `json:"theme,omitempty"`
+	Notifications bool           `json:"notifications"`
+	Hotkey        HotkeyConfig   `json:"hotkey"`
+	HotkeyPresets []HotkeyPreset `json:"hotkey_presets,omitempty"`
+	CancelHotkey  HotkeyConfig   `json:"cancel_hotkey"`
+	RepeatHotkey  HotkeyConfig   `json:"repeat_hotkey"`
+	ModelID       string         `json:"model_id,omitempty"`
+	Engine        string         `json:"engine,omitempty"`
+	LLM           LLMConfig      `json:"llm,omitempty"`
+	InsertDelayMs int            `json:"insert_delay_ms"`
+	MinRecordMs   int            `json:"min_recording_ms"`
+	MinWords      int            `json:"min_words"`
+	SilencePadMs  int            `json:"silence_pad_ms"`
+	PadLeadingMs  int            `json:"pad_leading_ms"`
+	PadTrailingMs int            `json:"pad_trailing_ms"`
+	InputRate     int            `json:"input_sample_rate,omitempty"`
+	DebounceMs    int            `json:"hotkey_debounce_ms"`
+	DetectRepeat  bool           `json:"hotkey_detect_repeat,omitempty"`
+	FollowupMs    int            `json:"hotkey_followup_timeout_ms,omitempty"`
+	ProxyURL      string         `json:"proxy_url,omitempty"`
+	Threads       int            `json:"threads,omitempty"`
+	DictationMode bool           `json:"dictation_mode,omitempty"`
+	SoundCues     bool           `json:"sound_cues,omitempty"`
+	Onboarded     bool           `json:"onboarded,omitempty"`
+	WhisperPrompt string         `json:"whisper_prompt,omitempty"`
+	VoskGrammar   string         `json:"vosk_grammar,omitempty"`
+	TextRules     TextRules      `json:"text_rules,omitempty"`
+	Newlines      string         `json:"newline_handling,omitempty"`
+	DebugAudio    bool           `json:"debug_keep_audio,omitempty"`
+	ShowTimings   bool           `json:"show_timings,omitempty"`
+	CollectStats  bool           `json:"collect_stats,omitempty"`
+	ResultSelAll  bool           `json:"result_select_all,omitempty"`
+	LogLevel      string         `json:"log_level,omitempty"`
+	ReplaceSelect bool           `json:"replace_selection"`
+	RestoreFocus  bool           `json:"restore_focus"`
+	TargetClass   string         `json:"target_window_class,omitempty"`
+	ShowWaveform  bool           `json:"show_waveform"`
+	Express       bool           `json:"express,omitempty"`
+	CheckUpdates  bool           `json:"check_model_updates"`
+	NoStealFocus  bool           `json:"no_steal_focus,omitempty"`
+	NotifyStyle   string         `json:"notification_style,omitempty"`
+	TrayIcons     string         `json:"tray_icon_style,omitempty"`
+	WaveformSize  string         `json:"waveform_size,omitempty"`
+	NotifyMaxChar int            `json:"notification_max_chars"`
+	TrimSilence   bool           `json:"trim_silence,omitempty"`
+	Denoise       bool           `json:"denoise,omitempty"`
+	ChunkAfterSec int            `json:"chunk_after_seconds,omitempty"`
+	RetryOnEmpty  bool           `json:"retry_on_empty,omitempty"`
+	VoskPunctuate bool           `json:"vosk_auto_punctuate,omitempty"`
+	LivePartials  bool           `json:"partial_results,omitempty"`
+	FallbackLang  string         `json:"fallback_language,omitempty"`
+	WhisperPreset string         `json:"whisper_preset,omitempty"`
+	CustomModels  string         `json:"custom_models,omitempty"`
+	PostCommand   string         `json:"post_command,omitempty"`
+	Output        string         `json:"output_target,omitempty"`
+	NotesFile     string         `json:"notes_file,omitempty"`
+	NotesStamp    bool           `json:"notes_timestamp,omitempty"`
+	WaveformX     *int           `json:"waveform_x,omitempty"`
+	WaveformY     *int           `json:"waveform_y,omitempty"`
+
+	WaveformClickThrough bool `json:"waveform_click_through,omitempty"`
+
+	ControlServerEnabled bool   `json:"control_server_enabled"`
+	ControlServerPort    int    `json:"control_server_port,omitempty"`
+	ControlToken         string `json:"control_token,omitempty"`
+
+	HallucinationBlocklist []string `json:"hallucination_blocklist,omitempty"`
 }
 
 // Config хранит настройки приложения.
@@ -108,10 +468,67 @@ type Config struct {
 	mu             sync.RWMutex
 	language       string
 	uiLanguage     string
+	theme          string // dark, light или system
 	notifications  bool
 	hotkey         HotkeyConfig
+	hotkeyPresets  []HotkeyPreset
+	cancelHotkey   HotkeyConfig
+	repeatHotkey   HotkeyConfig // пусто - не зарегистрирована
 	modelID        string
+	engine         string // whisper или vosk; пусто - определяется по модели
 	llm            LLMConfig
+	insertDelayMs  int
+	minRecordMs    int // записи короче не распознаются, 0 - без ограничения
+	minWords       int // результат с меньшим числом слов отбрасывается, 0 - без ограничения
+	silencePadMs   int // короткие записи дополняются тишиной до этой длины
+	padLeadingMs   int // тишина перед записью
+	padTrailingMs  int // тишина после записи
+	inputRate      int // частота потока микрофона, 0 - автоматически
+	proxyURL       string
+	debounceMs     int             // минимальный интервал между нажатиями горячей клавиши
+	detectRepeat   bool            // отличать автоповтор клавиши от двойного нажатия
+	followupMs     int             // ожидание второй клавиши последовательности
+	threads        int             // 0 - автоматически (по умолчанию библиотек)
+	dictationMode  bool            // запись продолжается после вставки каждой фразы
+	soundCues      bool            // звуки начала/остановки записи и готового результата
+	trimSilence    bool            // обрезать тишину по краям записи перед распознаванием
+	denoise        bool            // подавлять фоновый шум перед распознаванием
+	chunkAfterSec  int             // записи длиннее распознаются по кускам, 0 - никогда
+	retryOnEmpty   bool            // повторять пустое распознавание на запасном языке
+	fallbackLang   string          // язык повторного распознавания
+	voskPunctuate  bool            // заглавные буквы и точки в результатах Vosk
+	livePartials   bool            // промежуточный текст в окне записи (Vosk)
+	onboarded      bool            // первая модель скачана, приветствие больше не показывается
+	whisperPrompt  string          // начальная подсказка whisper (термины, имена)
+	voskGrammar    string          // путь к JSON файлу с фразами грамматики vosk
+	textRules      TextRules       // правки текста после распознавания
+	newlines       NewlineHandling // переводы строк при вставке и копировании
+	debugAudio     bool            // хранить последнюю запись для сохранения из трея
+	showTimings    bool            // показывать длительность этапов в окне результата
+	collectStats   bool            // считать слова и сэкономленное время в stats.json
+	resultSelAll   bool            // текст результата выделен целиком при открытии
+	clickThrough   bool            // окно записи пропускает клики мыши (Linux)
+	logLevel       string          // debug, info, warn или error
+	replaceSel     bool            // ввод заменяет выделенный текст (macOS, Windows)
+	restoreFocus   bool            // возвращать фокус исходному окну перед вводом (Linux)
+	targetClass    string          // WM_CLASS окна, куда всегда вводится текст (Linux)
+	showWaveform   bool            // показывать окно записи; без него результат выводится сразу
+	express        bool            // экспресс-режим: без окна, результат вводится сразу
+	checkUpdates   bool            // проверять при запуске обновления скачанных моделей
+	noStealFocus   bool            // окна Shofar открываются без фокуса (X11)
+	notifyStyle    NotificationStyle
+	trayIcons      TrayIconStyle
+	notifyMaxChars int // длина текста уведомления, 0 - без обрезки
+	whisperPreset  RecognitionPreset
+	customModels   string          // путь к custom_models.json, пусто - рядом с бинарником
+	postCommand    string          // внешняя команда обработки результата, пусто - нет
+	output         OutputTarget    // куда отправляется итоговый текст
+	notesFile      string          // путь к файлу заметок, пусто - DefaultNotesFile
+	notesStamp     bool            // перед каждой заметкой пишется время
+	hallucinations []string        // дополнительные фразы-галлюцинации распознавания
+	waveformPos    *WindowPosition // nil - позиция по умолчанию (правый нижний угол)
+	waveformSize   WaveformSize
+	control        controlConfig
 	configPath     string
 	onHotkeyChange func(HotkeyConfig)
 }
@@ -121,14 +538,38 @@ func New() *Config {
 	c := &Config{
 		language:      "auto", // auto для смешанного русского/английского
 		uiLanguage:    "ru",   // По умолчанию русский интерфейс
+		theme:         "dark",
+		fallbackLang:  "ru",
+		logLevel:      "info",
 		notifications: true,
 		hotkey: HotkeyConfig{
 			Modifiers: []Modifier{ModCtrl, ModShift},
 			Key:       KeySpace,
 		},
+		cancelHotkey: HotkeyConfig{
+			Modifiers: []Modifier{ModCtrl, ModShift},
+			Key:       KeyEscape,
+		},
 		llm: LLMConfig{
-			Enabled: false,
-			ModelID: "llm-qwen2.5-0.5b",
+			Enabled:     false,
+			ModelID:     "llm-qwen2.5-0.5b",
+			ContextSize: DefaultLLMContextSize,
+			GPULayers:   DefaultLLMGPULayers,
+		},
+		insertDelayMs:  DefaultInsertDelayMs,
+		minRecordMs:    DefaultMinRecordingMs,
+		silencePadMs:   DefaultSilencePadMs,
+		padLeadingMs:   DefaultEdgePadMs,
+		padTrailingMs:  DefaultEdgePadMs,
+		debounceMs:     DefaultHotkeyDebounceMs,
+		followupMs:     DefaultFollowupTimeoutMs,
+		notifyMaxChars: DefaultNotifyMaxChars,
+		replaceSel:     true,
+		restoreFocus:   runtime.GOOS == "linux",
+		showWaveform:   true,
+		checkUpdates:   true,
+		control: controlConfig{
+			port: DefaultControlServerPort,
 		},
 	}
 
@@ -159,26 +600,175 @@ func (c *Config) load() {
 	if err != nil {
 		return // Файл не существует, используем defaults
 	}
+	c.apply(data)
+}
+
+// Reload перечитывает файл настроек, изменённый вручную или синхронизацией.
+// Все изменения из приложения сохраняются в файл сразу, поэтому перечитывание
+// их не теряет; поля, которых нет в файле, сохраняют текущие значения.
+// Если файл не читается или повреждён, настройки не меняются и возвращается
+// ошибка. При смене горячей клавиши вызывается callback OnHotkeyChange.
+func (c *Config) Reload() error {
+	if c.configPath == "" {
+		return errors.New("config: путь к файлу настроек не определён")
+	}
+	data, err := os.ReadFile(c.configPath)
+	if err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	oldHotkey := c.hotkey.String()
+	if err := c.apply(data); err != nil {
+		c.mu.Unlock()
+		return fmt.Errorf("config: %s: %w", filepath.Base(c.configPath), err)
+	}
+	hk := c.hotkey
+	callback := c.onHotkeyChange
+	c.mu.Unlock()
+
+	if callback != nil && hk.String() != oldHotkey {
+		callback(hk)
+	}
+	return nil
+}
 
-	var cfg configData
+// apply разбирает содержимое файла настроек и обновляет поля.
+// При ошибке разбора поля не меняются.
+func (c *Config) apply(data []byte) error {
+	// Поля, для которых ноль - допустимое значение, заполняем
+	// текущими значениями: при отсутствии в файле они сохранятся.
+	cfg := configData{
+		InsertDelayMs: c.insertDelayMs,
+		MinRecordMs:   c.minRecordMs,
+		MinWords:      c.minWords,
+		SilencePadMs:  c.silencePadMs,
+		PadLeadingMs:  c.padLeadingMs,
+		PadTrailingMs: c.padTrailingMs,
+		DebounceMs:    c.debounceMs,
+		ReplaceSelect: c.replaceSel,
+		RestoreFocus:  c.restoreFocus,
+		ShowWaveform:  c.showWaveform,
+		CheckUpdates:  c.checkUpdates,
+		NotifyMaxChar: c.notifyMaxChars,
+		LLM:           LLMConfig{GPULayers: c.llm.GPULayers},
+	}
 	if err := json.Unmarshal(data, &cfg); err != nil {
-		return
+		return err
 	}
+	cfg = migrate(cfg, cfg.Version)
 
 	c.language = cfg.Language
 	if cfg.UILanguage != "" {
 		c.uiLanguage = cfg.UILanguage
 	}
+	if cfg.Theme != "" {
+		c.theme = cfg.Theme
+	}
 	c.notifications = cfg.Notifications
-	if cfg.Hotkey.Key != "" {
+	if cfg.Hotkey.Key != "" || cfg.Hotkey.IsMouse() {
 		c.hotkey = cfg.Hotkey
 	}
+	c.hotkeyPresets = cfg.HotkeyPresets
+	if cfg.CancelHotkey.Key != "" {
+		c.cancelHotkey = cfg.CancelHotkey
+	}
+	c.repeatHotkey = cfg.RepeatHotkey
 	c.modelID = cfg.ModelID
+	c.engine = cfg.Engine
 	// LLM config
 	c.llm.Enabled = cfg.LLM.Enabled
 	if cfg.LLM.ModelID != "" {
 		c.llm.ModelID = cfg.LLM.ModelID
 	}
+	if cfg.LLM.ContextSize > 0 {
+		c.llm.ContextSize = cfg.LLM.ContextSize
+	}
+	c.llm.Sampler = cfg.LLM.Sampler
+	c.llm.GPULayers = cfg.LLM.GPULayers
+	if cfg.InsertDelayMs >= 0 {
+		c.insertDelayMs = cfg.InsertDelayMs
+	}
+	if cfg.DebounceMs >= 0 {
+		c.debounceMs = cfg.DebounceMs
+	}
+	c.detectRepeat = cfg.DetectRepeat
+	if cfg.FollowupMs > 0 {
+		c.followupMs = cfg.FollowupMs
+	}
+	if cfg.MinRecordMs >= 0 {
+		c.minRecordMs = cfg.MinRecordMs
+	}
+	if cfg.MinWords >= 0 {
+		c.minWords = cfg.MinWords
+	}
+	if cfg.SilencePadMs > 0 {
+		c.silencePadMs = cfg.SilencePadMs
+	}
+	if cfg.PadLeadingMs >= 0 {
+		c.padLeadingMs = cfg.PadLeadingMs
+	}
+	if cfg.PadTrailingMs >= 0 {
+		c.padTrailingMs = cfg.PadTrailingMs
+	}
+	c.inputRate = max(cfg.InputRate, 0)
+	c.proxyURL = cfg.ProxyURL
+	if cfg.Threads > 0 {
+		c.threads = cfg.Threads
+	}
+	c.dictationMode = cfg.DictationMode
+	c.soundCues = cfg.SoundCues
+	c.trimSilence = cfg.TrimSilence
+	c.denoise = cfg.Denoise
+	c.chunkAfterSec = max(cfg.ChunkAfterSec, 0)
+	c.retryOnEmpty = cfg.RetryOnEmpty
+	c.voskPunctuate = cfg.VoskPunctuate
+	c.livePartials = cfg.LivePartials
+	if cfg.FallbackLang != "" {
+		c.fallbackLang = cfg.FallbackLang
+	}
+	c.onboarded = cfg.Onboarded
+	c.whisperPrompt = cfg.WhisperPrompt
+	c.voskGrammar = cfg.VoskGrammar
+	c.hallucinations = cfg.HallucinationBlocklist
+	c.textRules = cfg.TextRules
+	c.newlines = NewlineHandling(cfg.Newlines)
+	c.debugAudio = cfg.DebugAudio
+	c.showTimings = cfg.ShowTimings
+	c.collectStats = cfg.CollectStats
+	c.resultSelAll = cfg.ResultSelAll
+	c.clickThrough = cfg.WaveformClickThrough
+	c.replaceSel = cfg.ReplaceSelect
+	c.restoreFocus = cfg.RestoreFocus
+	c.targetClass = cfg.TargetClass
+	c.showWaveform = cfg.ShowWaveform
+	c.express = cfg.Express
+	c.checkUpdates = cfg.CheckUpdates
+	c.noStealFocus = cfg.NoStealFocus
+	c.notifyStyle = NotificationStyle(cfg.NotifyStyle)
+	c.trayIcons = TrayIconStyle(cfg.TrayIcons)
+	if cfg.NotifyMaxChar >= 0 {
+		c.notifyMaxChars = cfg.NotifyMaxChar
+	}
+	c.whisperPreset = RecognitionPreset(cfg.WhisperPreset)
+	c.customModels = cfg.CustomModels
+	c.postCommand = cfg.PostCommand
+	c.output = OutputTarget(cfg.Output)
+	c.notesFile = cfg.NotesFile
+	c.notesStamp = cfg.NotesStamp
+	if cfg.LogLevel != "" {
+		c.logLevel = cfg.LogLevel
+	}
+	c.control.enabled = cfg.ControlServerEnabled
+	if cfg.ControlServerPort > 0 {
+		c.control.port = cfg.ControlServerPort
+	}
+	c.control.token = cfg.ControlToken
+	if cfg.WaveformX != nil && cfg.WaveformY != nil {
+		c.waveformPos = &WindowPosition{X: *cfg.WaveformX, Y: *cfg.WaveformY}
+	}
+	c.waveformSize = WaveformSize(cfg.WaveformSize)
+	return nil
 }
 
 // save сохраняет конфигурацию в файл.
@@ -188,12 +778,78 @@ func (c *Config) save() {
 	}
 
 	cfg := configData{
+		Version:       configVersion,
 		Language:      c.language,
 		UILanguage:    c.uiLanguage,
+		Theme:         c.theme,
 		Notifications: c.notifications,
 		Hotkey:        c.hotkey,
+		HotkeyPresets: c.hotkeyPresets,
+		CancelHotkey:  c.cancelHotkey,
+		RepeatHotkey:  c.repeatHotkey,
 		ModelID:       c.modelID,
+		Engine:        c.engine,
 		LLM:           c.llm,
+		InsertDelayMs: c.insertDelayMs,
+		MinRecordMs:   c.minRecordMs,
+		MinWords:      c.minWords,
+		SilencePadMs:  c.silencePadMs,
+		PadLeadingMs:  c.padLeadingMs,
+		PadTrailingMs: c.padTrailingMs,
+		InputRate:     c.inputRate,
+		DebounceMs:    c.debounceMs,
+		DetectRepeat:  c.detectRepeat,
+		FollowupMs:    c.followupMs,
+		ProxyURL:      c.proxyURL,
+		Threads:       c.threads,
+		DictationMode: c.dictationMode,
+		SoundCues:     c.soundCues,
+		Onboarded:     c.onboarded,
+		WhisperPrompt: c.whisperPrompt,
+		VoskGrammar:   c.voskGrammar,
+		TextRules:     c.textRules,
+		Newlines:      string(c.newlines),
+		DebugAudio:    c.debugAudio,
+		ShowTimings:   c.showTimings,
+		CollectStats:  c.collectStats,
+		ResultSelAll:  c.resultSelAll,
+		LogLevel:      c.logLevel,
+		ReplaceSelect: c.replaceSel,
+		RestoreFocus:  c.restoreFocus,
+		TargetClass:   c.targetClass,
+		ShowWaveform:  c.showWaveform,
+		Express:       c.express,
+		CheckUpdates:  c.checkUpdates,
+		NoStealFocus:  c.noStealFocus,
+		NotifyMaxChar: c.notifyMaxChars,
+		NotifyStyle:   string(c.notifyStyle),
+		TrayIcons:     string(c.trayIcons),
+		WaveformSize:  string(c.waveformSize),
+		WhisperPreset: string(c.whisperPreset),
+		CustomModels:  c.customModels,
+		PostCommand:   c.postCommand,
+		Output:        string(c.output),
+		NotesFile:     c.notesFile,
+		NotesStamp:    c.notesStamp,
+		TrimSilence:   c.trimSilence,
+		Denoise:       c.denoise,
+		ChunkAfterSec: c.chunkAfterSec,
+		RetryOnEmpty:  c.retryOnEmpty,
+		VoskPunctuate: c.voskPunctuate,
+		LivePartials:  c.livePartials,
+		FallbackLang:  c.fallbackLang,
+
+		HallucinationBlocklist: c.hallucinations,
+		WaveformClickThrough:   c.clickThrough,
+
+		ControlServerEnabled: c.control.enabled,
+		ControlServerPort:    c.control.port,
+		ControlToken:         c.control.token,
+	}
+	if c.waveformPos != nil {
+		x, y := c.waveformPos.X, c.waveformPos.Y
+		cfg.WaveformX = &x
+		cfg.WaveformY = &y
 	}
 
 	data, err := json.MarshalIndent(cfg, "", "  ")
@@ -263,6 +919,30 @@ func (c *Config) SetHotkey(hk HotkeyConfig) {
 	}
 }
 
+// CancelHotkey возвращает горячую клавишу отмены записи.
+func (c *Config) CancelHotkey() HotkeyConfig {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.cancelHotkey
+}
+
+// SetCancelHotkey устанавливает горячую клавишу отмены записи.
+func (c *Config) SetCancelHotkey(hk HotkeyConfig) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.cancelHotkey = hk
+	c.save()
+}
+
+// RepeatHotkey возвращает горячую клавишу повторной вставки последнего
+// распознанного текста. Пустая - клавиша не нужна.
+// Меняется только в файле настроек.
+func (c *Config) RepeatHotkey() HotkeyConfig {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.repeatHotkey
+}
+
 // OnHotkeyChange устанавливает callback для изменения горячей клавиши.
 func (c *Config) OnHotkeyChange(fn func(HotkeyConfig)) {
 	c.mu.Lock()
@@ -270,6 +950,30 @@ func (c *Config) OnHotkeyChange(fn func(HotkeyConfig)) {
 	c.onHotkeyChange = fn
 }
 
+// HotkeyPresets возвращает пользовательские пресеты горячих клавиш.
+func (c *Config) HotkeyPresets() []HotkeyPreset {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	presets := make([]HotkeyPreset, len(c.hotkeyPresets))
+	copy(presets, c.hotkeyPresets)
+	return presets
+}
+
+// SaveHotkeyPreset сохраняет пресет. Пресет с тем же именем перезаписывается.
+func (c *Config) SaveHotkeyPreset(preset HotkeyPreset) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for i, p := range c.hotkeyPresets {
+		if p.Name == preset.Name {
+			c.hotkeyPresets[i] = preset
+			c.save()
+			return
+		}
+	}
+	c.hotkeyPresets = append(c.hotkeyPresets, preset)
+	c.save()
+}
+
 // ModelID возвращает ID текущей модели распознавания.
 func (c *Config) ModelID() string {
 	c.mu.RLock()
@@ -285,6 +989,23 @@ func (c *Config) SetModelID(id string) {
 	c.save()
 }
 
+// Engine возвращает выбранный движок распознавания.
+// Пустая строка - движок не сохранялся (старый файл настроек).
+func (c *Config) Engine() string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.engine
+}
+
+// SetEngine устанавливает движок распознавания. Движок хранится отдельно
+// от модели, чтобы выбор сохранялся, даже если его модели ещё не скачаны.
+func (c *Config) SetEngine(engine string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.engine = engine
+	c.save()
+}
+
 // LLM возвращает текущие настройки LLM.
 func (c *Config) LLM() LLMConfig {
 	c.mu.RLock()
@@ -315,6 +1036,49 @@ func (c *Config) LLMEnabled() bool {
 	return c.llm.Enabled
 }
 
+// LLMContextSize возвращает размер контекста LLM в токенах.
+func (c *Config) LLMContextSize() int {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.llm.ContextSize
+}
+
+// SetLLMContextSize устанавливает размер контекста LLM в токенах.
+func (c *Config) SetLLMContextSize(n int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.llm.ContextSize = n
+	c.save()
+}
+
+// LLMGPULayers возвращает, сколько слоёв LLM выгружать на GPU
+// (0 - только CPU, отрицательное - сколько поддерживает сборка).
+// Меняется только в файле настроек.
+func (c *Config) LLMGPULayers() int {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.llm.GPULayers
+}
+
+// LLMSampler возвращает способ выбора токена LLM.
+// По умолчанию (и для неизвестных значений) - жадный.
+func (c *Config) LLMSampler() LLMSampler {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if LLMSampler(c.llm.Sampler) == LLMSamplerBalanced {
+		return LLMSamplerBalanced
+	}
+	return LLMSamplerGreedy
+}
+
+// SetLLMSampler устанавливает способ выбора токена LLM.
+func (c *Config) SetLLMSampler(sampler LLMSampler) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.llm.Sampler = string(sampler)
+	c.save()
+}
+
 // LLMModelID возвращает ID модели LLM.
 func (c *Config) LLMModelID() string {
 	c.mu.RLock()
@@ -330,32 +1094,768 @@ func (c *Config) SetLLMModelID(id string) {
 	c.save()
 }
 
-// AvailableModifiers возвращает список доступных модификаторов.
-func AvailableModifiers() []Modifier {
-	return []Modifier{ModCtrl, ModShift, ModAlt, ModSuper}
+// InsertDelayMs возвращает задержку перед вставкой текста в миллисекундах.
+func (c *Config) InsertDelayMs() int {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.insertDelayMs
 }
 
-// AvailableKeys возвращает список доступных клавиш.
-func AvailableKeys() []Key {
-	return []Key{
-		KeySpace, KeyReturn, KeyTab,
-		KeyA, KeyB, KeyC, KeyD, KeyE, KeyF, KeyG, KeyH, KeyI, KeyJ, KeyK, KeyL, KeyM,
-		KeyN, KeyO, KeyP, KeyQ, KeyR, KeyS, KeyT, KeyU, KeyV, KeyW, KeyX, KeyY, KeyZ,
-		KeyF1, KeyF2, KeyF3, KeyF4, KeyF5, KeyF6, KeyF7, KeyF8, KeyF9, KeyF10, KeyF11, KeyF12,
+// HotkeyDebounceMs возвращает минимальный интервал между нажатиями горячей
+// клавиши в миллисекундах (0 - без ограничения). Меняется только в файле настроек.
+func (c *Config) HotkeyDebounceMs() int {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.debounceMs
+}
+
+// HotkeyFollowupTimeoutMs возвращает, сколько миллисекунд после ведущего
+// сочетания ждать вторую клавишу (см. HotkeyConfig.FollowupKey).
+// Меняется только в файле настроек.
+func (c *Config) HotkeyFollowupTimeoutMs() int {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.followupMs
+}
+
+// HotkeyDetectRepeat возвращает true если автоповтор зажатой клавиши
+// отсекается по событиям отпускания, а не только по интервалу.
+// Меняется только в файле настроек.
+func (c *Config) HotkeyDetectRepeat() bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.detectRepeat
+}
+
+// SetInsertDelayMs устанавливает задержку перед вставкой текста.
+func (c *Config) SetInsertDelayMs(ms int) {
+	if ms < 0 {
+		ms = 0
 	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.insertDelayMs = ms
+	c.save()
 }
 
-// UILanguage возвращает язык интерфейса.
-func (c *Config) UILanguage() string {
+// MinRecordingMs возвращает минимальную длительность записи в миллисекундах:
+// более короткие записи отбрасываются без распознавания.
+func (c *Config) MinRecordingMs() int {
 	c.mu.RLock()
 	defer c.mu.RUnlock()
-	return c.uiLanguage
+	return c.minRecordMs
 }
 
-// SetUILanguage устанавливает язык интерфейса.
-func (c *Config) SetUILanguage(lang string) {
+// SetMinRecordingMs устанавливает минимальную длительность записи.
+func (c *Config) SetMinRecordingMs(ms int) {
+	if ms < 0 {
+		ms = 0
+	}
 	c.mu.Lock()
 	defer c.mu.Unlock()
-	c.uiLanguage = lang
+	c.minRecordMs = ms
+	c.save()
+}
+
+// MinWords возвращает минимальное число слов в результате: более короткий
+// результат («э», «мм» от случайного нажатия) считается пустым.
+// 0 - без ограничения. Меняется только в файле настроек.
+func (c *Config) MinWords() int {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.minWords
+}
+
+// SilencePadMs возвращает длительность в миллисекундах, до которой короткая
+// запись дополняется тишиной перед распознаванием.
+func (c *Config) SilencePadMs() int {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.silencePadMs
+}
+
+// PadLeadingMs возвращает, сколько миллисекунд тишины добавляется в начало
+// записи перед распознаванием (0 - не добавляется). Меняется только в файле настроек.
+func (c *Config) PadLeadingMs() int {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.padLeadingMs
+}
+
+// PadTrailingMs возвращает, сколько миллисекунд тишины добавляется в конец
+// записи перед распознаванием (0 - не добавляется). Меняется только в файле настроек.
+func (c *Config) PadTrailingMs() int {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.padTrailingMs
+}
+
+// InputSampleRate возвращает частоту, на которой открывается поток микрофона
+// (0 - 16kHz, а если устройство её не поддерживает - его родная частота).
+// Запись всё равно пересэмплируется в 16kHz. Меняется только в файле настроек.
+func (c *Config) InputSampleRate() int {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.inputRate
+}
+
+// Threads возвращает число потоков для whisper и LLM (0 - автоматически).
+func (c *Config) Threads() int {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.threads
+}
+
+// SetThreads устанавливает число потоков для whisper и LLM.
+func (c *Config) SetThreads(n int) {
+	if n < 0 {
+		n = 0
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.threads = n
+	c.save()
+}
+
+// DictationMode возвращает true если включён режим диктовки: каждая фраза
+// после паузы распознаётся и вставляется, а запись продолжается.
+func (c *Config) DictationMode() bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.dictationMode
+}
+
+// SetDictationMode включает/выключает режим диктовки.
+func (c *Config) SetDictationMode(enabled bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.dictationMode = enabled
+	c.save()
+}
+
+// SoundCues возвращает true если включены звуковые сигналы.
+func (c *Config) SoundCues() bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.soundCues
+}
+
+// SetSoundCues включает/выключает звуковые сигналы.
+func (c *Config) SetSoundCues(enabled bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.soundCues = enabled
+	c.save()
+}
+
+// WaveformClickThrough возвращает true если окно записи не перехватывает
+// клики мыши во время записи.
+func (c *Config) WaveformClickThrough() bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.clickThrough
+}
+
+// SetWaveformClickThrough включает/выключает пропуск кликов через окно записи.
+func (c *Config) SetWaveformClickThrough(enabled bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.clickThrough = enabled
+	c.save()
+}
+
+// TrimSilence возвращает true если тишина в начале и в конце записи
+// обрезается перед распознаванием.
+func (c *Config) TrimSilence() bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.trimSilence
+}
+
+// SetTrimSilence включает/выключает обрезку тишины.
+func (c *Config) SetTrimSilence(enabled bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.trimSilence = enabled
+	c.save()
+}
+
+// Denoise возвращает true если фоновый шум подавляется перед распознаванием.
+func (c *Config) Denoise() bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.denoise
+}
+
+// SetDenoise включает/выключает подавление шума.
+func (c *Config) SetDenoise(enabled bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.denoise = enabled
+	c.save()
+}
+
+// ChunkAfterSeconds возвращает длину записи в секундах, начиная с которой
+// она распознаётся по перекрывающимся кускам (0 - всегда целиком).
+// Меняется только в файле настроек.
+func (c *Config) ChunkAfterSeconds() int {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.chunkAfterSec
+}
+
+// RetryOnEmpty возвращает true если пустой результат распознавания
+// повторяется один раз с запасным языком (FallbackLanguage).
+func (c *Config) RetryOnEmpty() bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.retryOnEmpty
+}
+
+// SetRetryOnEmpty включает/выключает повтор пустого распознавания.
+func (c *Config) SetRetryOnEmpty(enabled bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.retryOnEmpty = enabled
+	c.save()
+}
+
+// VoskAutoPunctuate возвращает true если в результатах Vosk, который
+// выдаёт текст строчными буквами без знаков препинания, расставляются
+// заглавные буквы и точка в конце.
+func (c *Config) VoskAutoPunctuate() bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.voskPunctuate
+}
+
+// SetVoskAutoPunctuate включает/выключает пунктуацию результатов Vosk.
+func (c *Config) SetVoskAutoPunctuate(enabled bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.voskPunctuate = enabled
+	c.save()
+}
+
+// PartialResults возвращает true если во время записи в окне показывается
+// промежуточный текст. Работает с моделями Vosk, Whisper распознаёт
+// запись только целиком.
+func (c *Config) PartialResults() bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.livePartials
+}
+
+// SetPartialResults включает/выключает промежуточный текст при записи.
+func (c *Config) SetPartialResults(enabled bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.livePartials = enabled
+	c.save()
+}
+
+// FallbackLanguage возвращает язык повторного распознавания (по умолчанию "ru").
+// Меняется только в файле настроек.
+func (c *Config) FallbackLanguage() string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.fallbackLang
+}
+
+// Onboarded возвращает true если первый запуск пройден:
+// модель распознавания уже была скачана.
+func (c *Config) Onboarded() bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.onboarded
+}
+
+// SetOnboarded отмечает первый запуск пройденным.
+func (c *Config) SetOnboarded() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.onboarded {
+		return
+	}
+	c.onboarded = true
+	c.save()
+}
+
+// WhisperPrompt возвращает начальную подсказку для whisper.
+// Подсказка передаётся в каждое распознавание отдельно, по умолчанию пустая.
+func (c *Config) WhisperPrompt() string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.whisperPrompt
+}
+
+// SetWhisperPrompt устанавливает начальную подсказку для whisper.
+func (c *Config) SetWhisperPrompt(prompt string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.whisperPrompt = prompt
+	c.save()
+}
+
+// VoskGrammarFile возвращает путь к JSON файлу с фразами, которыми
+// ограничено распознавание Vosk. Пусто - без ограничений.
+func (c *Config) VoskGrammarFile() string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.voskGrammar
+}
+
+// SetVoskGrammarFile устанавливает путь к файлу грамматики Vosk.
+func (c *Config) SetVoskGrammarFile(path string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.voskGrammar = path
+	c.save()
+}
+
+// TextRules возвращает правки, применяемые к тексту перед вставкой.
+func (c *Config) TextRules() TextRules {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	rules := c.textRules
+	rules.Replace = make(map[string]string, len(c.textRules.Replace))
+	for k, v := range c.textRules.Replace {
+		rules.Replace[k] = v
+	}
+	return rules
+}
+
+// SetTextRules устанавливает правки текста.
+func (c *Config) SetTextRules(rules TextRules) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.textRules = rules
+	c.save()
+}
+
+// NewlineHandling возвращает обработку переводов строк при вставке и копировании.
+// По умолчанию (и для неизвестных значений) переводы строк сохраняются.
+func (c *Config) NewlineHandling() NewlineHandling {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	switch c.newlines {
+	case NewlineSpace, NewlineStrip:
+		return c.newlines
+	default:
+		return NewlineKeep
+	}
+}
+
+// SetNewlineHandling устанавливает обработку переводов строк.
+func (c *Config) SetNewlineHandling(h NewlineHandling) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.newlines = h
+	c.save()
+}
+
+// NotificationStyle возвращает стиль уведомлений.
+// По умолчанию (и для неизвестных значений) показываются все уведомления.
+func (c *Config) NotificationStyle() NotificationStyle {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	switch c.notifyStyle {
+	case NotificationMinimal, NotificationOff:
+		return c.notifyStyle
+	default:
+		return NotificationSystem
+	}
+}
+
+// SetNotificationStyle устанавливает стиль уведомлений.
+func (c *Config) SetNotificationStyle(style NotificationStyle) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.notifyStyle = style
+	c.save()
+}
+
+// TrayIconStyle возвращает набор иконок трея. По умолчанию (и для
+// неизвестных значений) на macOS - template, на остальных системах - цветные.
+// Меняется только в файле настроек.
+func (c *Config) TrayIconStyle() TrayIconStyle {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	switch c.trayIcons {
+	case TrayIconColor, TrayIconTemplate:
+		return c.trayIcons
+	}
+	if runtime.GOOS == "darwin" {
+		return TrayIconTemplate
+	}
+	return TrayIconColor
+}
+
+// NotifyMaxChars возвращает длину текста уведомления в символах
+// (по умолчанию 100), длиннее текст обрезается. 0 - без обрезки.
+// Меняется только в файле настроек.
+func (c *Config) NotifyMaxChars() int {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.notifyMaxChars
+}
+
+// RecognitionPreset возвращает пресет распознавания whisper.
+// По умолчанию (и для неизвестных значений) - точный.
+func (c *Config) RecognitionPreset() RecognitionPreset {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if c.whisperPreset == RecognitionFast {
+		return RecognitionFast
+	}
+	return RecognitionAccurate
+}
+
+// SetRecognitionPreset устанавливает пресет распознавания whisper.
+func (c *Config) SetRecognitionPreset(preset RecognitionPreset) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.whisperPreset = preset
+	c.save()
+}
+
+// DebugKeepAudio возвращает true если приложение хранит звук последней
+// записи, чтобы его можно было сохранить в файл для отладки распознавания.
+// Включается только вручную в файле настроек.
+func (c *Config) DebugKeepAudio() bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.debugAudio
+}
+
+// ShowTimings возвращает true если окно результата показывает, сколько
+// длились запись, распознавание и LLM коррекция.
+// Включается только вручную в файле настроек.
+func (c *Config) ShowTimings() bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.showTimings
+}
+
+// CollectStats возвращает true если ведётся статистика диктовки:
+// число распознанных слов и сэкономленное время (пункт в трее).
+// Включается только вручную в файле настроек, применяется при запуске.
+func (c *Config) CollectStats() bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.collectStats
+}
+
+// ResultSelectAll возвращает true если окно результата открывается
+// с фокусом в редакторе и выделенным текстом: первая же клавиша заменяет
+// его. Иначе курсор стоит в конце текста. Меняется только в файле настроек.
+func (c *Config) ResultSelectAll() bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.resultSelAll
+}
+
+// LogLevel возвращает минимальный уровень записей журнала:
+// debug, info, warn или error. Меняется только в файле настроек.
+func (c *Config) LogLevel() string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.logLevel
+}
+
+// CustomModelsPath возвращает путь к файлу пользовательских моделей.
+// Пусто - custom_models.json рядом с бинарником. Меняется только в файле настроек.
+func (c *Config) CustomModelsPath() string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.customModels
+}
+
+// PostCommand возвращает внешнюю команду, через которую проходит
+// результат распознавания (пусто - не используется).
+// Меняется только в файле настроек.
+func (c *Config) PostCommand() string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.postCommand
+}
+
+// OutputTarget возвращает, куда отправляется итоговый текст. По умолчанию
+// (и для неизвестных значений) - ввод в активное окно.
+// Меняется только в файле настроек.
+func (c *Config) OutputTarget() OutputTarget {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	switch c.output {
+	case OutputClipboard, OutputFile:
+		return c.output
+	default:
+		return OutputActiveWindow
+	}
+}
+
+// NotesFile возвращает шаблон пути к файлу заметок для вывода в файл
+// (см. DefaultNotesFile). Меняется только в файле настроек.
+func (c *Config) NotesFile() string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if c.notesFile == "" {
+		return DefaultNotesFile
+	}
+	return c.notesFile
+}
+
+// NotesTimestamp возвращает true если перед заметкой пишутся дата и время.
+// Меняется только в файле настроек.
+func (c *Config) NotesTimestamp() bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.notesStamp
+}
+
+// ReplaceSelection возвращает true если вставляемый текст заменяет
+// выделение (по умолчанию). false - текст добавляется после выделения.
+// Меняется только в файле настроек.
+func (c *Config) ReplaceSelection() bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.replaceSel
+}
+
+// RestoreFocus возвращает true если перед вводом фокус возвращается окну,
+// активному в начале записи (по умолчанию на Linux, работает под X11).
+// Меняется только в файле настроек.
+func (c *Config) RestoreFocus() bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.restoreFocus
+}
+
+// TargetWindowClass возвращает WM_CLASS окна, в которое вводится текст
+// независимо от фокуса ("" - в активное окно). Работает на Linux под X11.
+// Меняется только в файле настроек, применяется после перезапуска.
+func (c *Config) TargetWindowClass() string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.targetClass
+}
+
+// ShowWaveform возвращает true если во время записи показывается окно
+// с волной (по умолчанию). Без окна о записи говорят иконка трея и
+// уведомления, а результат сразу выводится по output_target.
+// В экспресс-режиме окна нет независимо от show_waveform.
+// Меняется только в файле настроек.
+func (c *Config) ShowWaveform() bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.showWaveform && !c.express
+}
+
+// Express возвращает true если включён экспресс-режим: окно записи
+// не открывается, а результат сразу вводится в активное окно, даже
+// при низкой уверенности распознавания.
+func (c *Config) Express() bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.express
+}
+
+// SetExpress включает или выключает экспресс-режим. При включении вывод
+// переключается в активное окно. Выключение возвращает окно записи по
+// show_waveform; вывод и задержка вставки не меняются.
+func (c *Config) SetExpress(enabled bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.express = enabled
+	if enabled {
+		c.output = OutputActiveWindow
+	}
+	c.save()
+}
+
+// CheckModelUpdates возвращает true если при запуске скачанные модели
+// сверяются с сервером (по умолчанию). Меняется только в файле настроек.
+func (c *Config) CheckModelUpdates() bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.checkUpdates
+}
+
+// NoStealFocus возвращает true если окно записи и настройки открываются,
+// не забирая фокус у текущего окна (работает под X11).
+// Меняется только в файле настроек.
+func (c *Config) NoStealFocus() bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.noStealFocus
+}
+
+// HallucinationBlocklist возвращает дополнительные фразы, которые считаются
+// пустым результатом распознавания (к встроенному списку speech.DefaultHallucinations).
+func (c *Config) HallucinationBlocklist() []string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	result := make([]string, len(c.hallucinations))
+	copy(result, c.hallucinations)
+	return result
+}
+
+// SetHallucinationBlocklist устанавливает дополнительные фразы-галлюцинации.
+func (c *Config) SetHallucinationBlocklist(phrases []string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.hallucinations = phrases
+	c.save()
+}
+
+// controlConfig настройки локального сервера управления.
+type controlConfig struct {
+	enabled bool
+	port    int
+	token   string
+}
+
+// ControlServerEnabled возвращает true если локальный сервер управления включён.
+// По умолчанию выключен, включается в config.json.
+func (c *Config) ControlServerEnabled() bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.control.enabled
+}
+
+// ControlServerPort возвращает порт сервера управления на 127.0.0.1.
+func (c *Config) ControlServerPort() int {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.control.port
+}
+
+// ControlToken возвращает токен доступа к серверу управления.
+// При первом обращении генерирует случайный токен и сохраняет его в config.json.
+func (c *Config) ControlToken() (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.control.token != "" {
+		return c.control.token, nil
+	}
+
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	c.control.token = hex.EncodeToString(buf)
+	c.save()
+	return c.control.token, nil
+}
+
+// ProxyURL возвращает адрес прокси для скачивания моделей.
+// Пустая строка - прокси из переменных окружения.
+func (c *Config) ProxyURL() string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.proxyURL
+}
+
+// SetProxyURL устанавливает адрес прокси для скачивания моделей.
+func (c *Config) SetProxyURL(proxyURL string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.proxyURL = proxyURL
+	c.save()
+}
+
+// WaveformPosition возвращает сохранённую позицию окна визуализации.
+// ok == false, если позиция не сохранялась.
+func (c *Config) WaveformPosition() (pos WindowPosition, ok bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if c.waveformPos == nil {
+		return WindowPosition{}, false
+	}
+	return *c.waveformPos, true
+}
+
+// SetWaveformPosition сохраняет позицию окна визуализации.
+func (c *Config) SetWaveformPosition(pos WindowPosition) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.waveformPos = &pos
+	c.save()
+}
+
+// WaveformSize возвращает размер окна записи (по умолчанию WaveformNormal).
+func (c *Config) WaveformSize() WaveformSize {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	switch c.waveformSize {
+	case WaveformCompact, WaveformLarge:
+		return c.waveformSize
+	}
+	return WaveformNormal
+}
+
+// SetWaveformSize сохраняет размер окна записи.
+func (c *Config) SetWaveformSize(size WaveformSize) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.waveformSize = size
+	c.save()
+}
+
+// ResetWaveformPosition сбрасывает сохранённую позицию окна визуализации,
+// окно снова будет появляться в правом нижнем углу экрана.
+func (c *Config) ResetWaveformPosition() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.waveformPos = nil
+	c.save()
+}
+
+// AvailableModifiers возвращает список доступных модификаторов.
+func AvailableModifiers() []Modifier {
+	return []Modifier{ModCtrl, ModShift, ModAlt, ModSuper}
+}
+
+// AvailableKeys возвращает список доступных клавиш.
+func AvailableKeys() []Key {
+	return []Key{
+		KeySpace, KeyReturn, KeyTab, KeyEscape,
+		KeyA, KeyB, KeyC, KeyD, KeyE, KeyF, KeyG, KeyH, KeyI, KeyJ, KeyK, KeyL, KeyM,
+		KeyN, KeyO, KeyP, KeyQ, KeyR, KeyS, KeyT, KeyU, KeyV, KeyW, KeyX, KeyY, KeyZ,
+		Key0, Key1, Key2, Key3, Key4, Key5, Key6, Key7, Key8, Key9,
+		KeyGrave, KeyMinus, KeyEqual, KeyBracketLeft, KeyBracketRight,
+		KeySemicolon, KeyApostrophe, KeyComma, KeyPeriod, KeySlash, KeyBackslash,
+		KeyF1, KeyF2, KeyF3, KeyF4, KeyF5, KeyF6, KeyF7, KeyF8, KeyF9, KeyF10, KeyF11, KeyF12,
+	}
+}
+
+// UILanguage возвращает язык интерфейса.
+func (c *Config) UILanguage() string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.uiLanguage
+}
+
+// SetUILanguage устанавливает язык интерфейса.
+func (c *Config) SetUILanguage(lang string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.uiLanguage = lang
+	c.save()
+}
+
+// Theme возвращает тему оформления: "dark", "light" или "system".
+func (c *Config) Theme() string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.theme
+}
+
+// SetTheme устанавливает тему оформления.
+func (c *Config) SetTheme(name string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.theme = name
 	c.save()
 }
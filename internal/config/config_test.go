@@ -0,0 +1,40 @@
+package config
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+// TestSetExpressKeepsInsertDelay проверяет, что экспресс-режим хранится
+// отдельно: включение и выключение не трогает свою задержку вставки,
+// а show_waveform возвращается к прежнему значению.
+func TestSetExpressKeepsInsertDelay(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	c := &Config{configPath: path}
+	c.load()
+	c.SetInsertDelayMs(400)
+	showWaveform := c.ShowWaveform()
+
+	c.SetExpress(true)
+	if !c.Express() || c.ShowWaveform() || c.OutputTarget() != OutputActiveWindow {
+		t.Errorf("after SetExpress(true): express %v, waveform %v, output %q",
+			c.Express(), c.ShowWaveform(), c.OutputTarget())
+	}
+
+	c.SetExpress(false)
+	if c.Express() {
+		t.Error("Express() = true after SetExpress(false)")
+	}
+	if got := c.InsertDelayMs(); got != 400 {
+		t.Errorf("InsertDelayMs() = %d, want 400", got)
+	}
+	if got := c.ShowWaveform(); got != showWaveform {
+		t.Errorf("ShowWaveform() = %v, want %v", got, showWaveform)
+	}
+
+	reloaded := &Config{configPath: path}
+	reloaded.load()
+	if reloaded.Express() || reloaded.InsertDelayMs() != 400 {
+		t.Errorf("reloaded: express %v, delay %d", reloaded.Express(), reloaded.InsertDelayMs())
+	}
+}
@@ -0,0 +1,37 @@
+package config
+
+import "strings"
+
+// configVersion - текущая версия формата config.json. Увеличивается при
+// каждом несовместимом изменении формата вместе с новым шагом в migrate.
+//
+// История версий:
+//   - 0: файлы без поля version. Движок распознавания не сохранялся,
+//     он определялся по модели.
+//   - 1: поле version, движок сохраняется в engine.
+const configVersion = 1
+
+// migrate приводит данные файла версии version к текущему формату.
+// Шаги применяются по очереди, поэтому файл любой старой версии проходит
+// через все последующие изменения. Файлы новее текущей версии (после
+// отката приложения) читаются как есть: неизвестные поля теряются при
+// следующем сохранении.
+func migrate(old configData, version int) configData {
+	cfg := old
+	for v := version; v < configVersion; v++ {
+		switch v {
+		case 0:
+			// Движок выводится из ID модели, как это делал PreferredEngine
+			if cfg.Engine == "" {
+				switch {
+				case strings.HasPrefix(cfg.ModelID, "vosk-"):
+					cfg.Engine = "vosk"
+				case strings.HasPrefix(cfg.ModelID, "whisper-"):
+					cfg.Engine = "whisper"
+				}
+			}
+		}
+	}
+	cfg.Version = configVersion
+	return cfg
+}
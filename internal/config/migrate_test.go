@@ -0,0 +1,112 @@
+package config
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestMigrate(t *testing.T) {
+	tests := []struct {
+		name       string
+		json       string
+		wantEngine string
+		wantModel  string
+	}{
+		{
+			name:       "v0 vosk",
+			json:       `{"model_id": "vosk-small-ru"}`,
+			wantEngine: "vosk",
+			wantModel:  "vosk-small-ru",
+		},
+		{
+			name:       "v0 whisper",
+			json:       `{"model_id": "whisper-base"}`,
+			wantEngine: "whisper",
+			wantModel:  "whisper-base",
+		},
+		{
+			name:      "v0 unknown model",
+			json:      `{"model_id": "custom-model"}`,
+			wantModel: "custom-model",
+		},
+		{
+			name:       "v0 engine kept",
+			json:       `{"model_id": "vosk-small-ru", "engine": "whisper"}`,
+			wantEngine: "whisper",
+			wantModel:  "vosk-small-ru",
+		},
+		{
+			name:       "v1 engine not derived",
+			json:       `{"version": 1, "model_id": "vosk-small-ru"}`,
+			wantEngine: "",
+			wantModel:  "vosk-small-ru",
+		},
+		{
+			name:       "v1 engine kept",
+			json:       `{"version": 1, "model_id": "whisper-base", "engine": "whisper"}`,
+			wantEngine: "whisper",
+			wantModel:  "whisper-base",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var old configData
+			if err := json.Unmarshal([]byte(tt.json), &old); err != nil {
+				t.Fatal(err)
+			}
+			cfg := migrate(old, old.Version)
+			if cfg.Version != configVersion {
+				t.Errorf("Version = %d, want %d", cfg.Version, configVersion)
+			}
+			if cfg.Engine != tt.wantEngine {
+				t.Errorf("Engine = %q, want %q", cfg.Engine, tt.wantEngine)
+			}
+			if cfg.ModelID != tt.wantModel {
+				t.Errorf("ModelID = %q, want %q", cfg.ModelID, tt.wantModel)
+			}
+		})
+	}
+}
+
+// TestMigrateNewerVersion проверяет, что файл новее текущей версии
+// читается как есть.
+func TestMigrateNewerVersion(t *testing.T) {
+	old := configData{Version: configVersion + 1, ModelID: "vosk-small-ru"}
+	cfg := migrate(old, old.Version)
+	if cfg.Engine != "" || cfg.ModelID != old.ModelID {
+		t.Errorf("migrate changed newer config: %+v", cfg)
+	}
+}
+
+// TestLoadSavesVersion проверяет весь путь: файл v0 читается, движок
+// выводится из модели, а сохранённый файл получает текущую версию.
+func TestLoadSavesVersion(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	if err := os.WriteFile(path, []byte(`{"model_id": "whisper-base", "language": "ru"}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	c := &Config{configPath: path}
+	c.load()
+	if got := c.Engine(); got != "whisper" {
+		t.Errorf("Engine() = %q, want whisper", got)
+	}
+	if got := c.Language(); got != "ru" {
+		t.Errorf("Language() = %q, want ru", got)
+	}
+
+	c.SetModelID("whisper-base")
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var saved configData
+	if err := json.Unmarshal(data, &saved); err != nil {
+		t.Fatal(err)
+	}
+	if saved.Version != configVersion || saved.Engine != "whisper" {
+		t.Errorf("saved version %d engine %q, want %d whisper", saved.Version, saved.Engine, configVersion)
+	}
+}
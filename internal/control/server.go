@@ -0,0 +1,162 @@
+// Package control предоставляет локальный HTTP API для управления записью из скриптов.
+package control
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// TokenHeader - заголовок с токеном доступа (альтернатива Authorization: Bearer).
+const TokenHeader = "X-Shofar-Token"
+
+// Status состояние приложения для GET /status.
+type Status struct {
+	State string `json:"state"`           // idle, recording, processing
+	Model string `json:"model,omitempty"` // ID текущей модели распознавания
+}
+
+// ModelRequest тело POST /model.
+type ModelRequest struct {
+	Model string `json:"model"`
+}
+
+// Callbacks содержит обработчики команд. Вызываются из горутин HTTP сервера.
+type Callbacks struct {
+	OnStart func() error
+	OnStop  func() error
+	OnModel func(modelID string) error // смена модели распознавания, только в ожидании
+	Status  func() Status
+}
+
+// Server - локальный HTTP сервер управления.
+type Server struct {
+	token     string
+	callbacks Callbacks
+	srv       *http.Server
+}
+
+// New создаёт сервер на 127.0.0.1:port. Запросы без token отклоняются.
+func New(port int, token string, callbacks Callbacks) *Server {
+	s := &Server{
+		token:     token,
+		callbacks: callbacks,
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /record/start", s.auth(s.handleStart))
+	mux.HandleFunc("POST /record/stop", s.auth(s.handleStop))
+	mux.HandleFunc("POST /model", s.auth(s.handleModel))
+	mux.HandleFunc("GET /status", s.auth(s.handleStatus))
+
+	s.srv = &http.Server{
+		Addr:              fmt.Sprintf("127.0.0.1:%d", port),
+		Handler:           mux,
+		ReadHeaderTimeout: 5 * time.Second,
+	}
+	return s
+}
+
+// Start начинает приём запросов в фоне.
+func (s *Server) Start() error {
+	if s.token == "" {
+		return errors.New("control: пустой токен доступа")
+	}
+
+	ln, err := net.Listen("tcp", s.srv.Addr)
+	if err != nil {
+		return err
+	}
+
+	log.Printf("Сервер управления запущен на %s", s.srv.Addr)
+	go func() {
+		if err := s.srv.Serve(ln); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Printf("Ошибка сервера управления: %v", err)
+		}
+	}()
+	return nil
+}
+
+// Close останавливает сервер.
+func (s *Server) Close() error {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	return s.srv.Shutdown(ctx)
+}
+
+// auth проверяет токен запроса.
+func (s *Server) auth(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		token := r.Header.Get(TokenHeader)
+		if token == "" {
+			token = strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+		}
+		if subtle.ConstantTimeCompare([]byte(token), []byte(s.token)) != 1 {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		next(w, r)
+	}
+}
+
+func (s *Server) handleStart(w http.ResponseWriter, r *http.Request) {
+	s.run(w, s.callbacks.OnStart)
+}
+
+func (s *Server) handleStop(w http.ResponseWriter, r *http.Request) {
+	s.run(w, s.callbacks.OnStop)
+}
+
+func (s *Server) handleModel(w http.ResponseWriter, r *http.Request) {
+	var req ModelRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Model == "" {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "ожидается {\"model\": \"<id>\"}"})
+		return
+	}
+	// Смена модели закрывает текущий распознаватель: во время записи
+	// и распознавания её не начинаем
+	if s.callbacks.Status != nil {
+		if state := s.callbacks.Status().State; state != "idle" {
+			writeJSON(w, http.StatusConflict, map[string]string{"error": "модель нельзя сменить в состоянии " + state})
+			return
+		}
+	}
+	s.run(w, func() error {
+		if s.callbacks.OnModel == nil {
+			return errors.New("смена модели не поддерживается")
+		}
+		return s.callbacks.OnModel(req.Model)
+	})
+}
+
+func (s *Server) handleStatus(w http.ResponseWriter, r *http.Request) {
+	var status Status
+	if s.callbacks.Status != nil {
+		status = s.callbacks.Status()
+	}
+	writeJSON(w, http.StatusOK, status)
+}
+
+// run выполняет команду и отвечает текущим статусом.
+func (s *Server) run(w http.ResponseWriter, fn func() error) {
+	if fn != nil {
+		if err := fn(); err != nil {
+			writeJSON(w, http.StatusConflict, map[string]string{"error": err.Error()})
+			return
+		}
+	}
+	s.handleStatus(w, nil)
+}
+
+func writeJSON(w http.ResponseWriter, code int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(v)
+}
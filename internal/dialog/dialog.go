@@ -3,10 +3,13 @@ package dialog
 
 import (
 	"fmt"
+	"os"
+	"path/filepath"
 	"strings"
 
 	"github.com/ncruces/zenity"
 	"shofar/internal/config"
+	"shofar/internal/i18n"
 )
 
 // SelectHotkey открывает диалог выбора горячей клавиши.
@@ -119,3 +122,66 @@ func ShowInfo(title, message string) {
 func ShowError(title, message string) {
 	zenity.Error(message, zenity.Title(title))
 }
+
+// Confirm задаёт вопрос с кнопками "Да" и "Нет".
+// Возвращает true, если пользователь согласился.
+func Confirm(title, message string) bool {
+	return zenity.Question(message, zenity.Title(title)) == nil
+}
+
+// SaveWAV открывает диалог сохранения WAV файла с предложенным именем.
+// Возвращает выбранный путь или ошибку если пользователь отменил.
+func SaveWAV(title, defaultName string) (string, error) {
+	path, err := zenity.SelectFileSave(
+		zenity.Title(title),
+		zenity.Filename(defaultName),
+		zenity.ConfirmOverwrite(),
+		zenity.FileFilter{Name: "WAV", Patterns: []string{"*.wav"}},
+	)
+	if err != nil {
+		return "", err
+	}
+	if !strings.HasSuffix(strings.ToLower(path), ".wav") {
+		return confirmAppended(title, path+".wav")
+	}
+	return path, nil
+}
+
+// SaveExport открывает диалог сохранения распознанного текста: txt, srt
+// или json. zenity не сообщает выбранный фильтр, поэтому формат задаёт
+// расширение имени, а без одного из этих расширений добавляется .txt.
+// Возвращает выбранный путь или ошибку если пользователь отменил.
+func SaveExport(title, defaultName string) (string, error) {
+	path, err := zenity.SelectFileSave(
+		zenity.Title(title),
+		zenity.Filename(defaultName),
+		zenity.ConfirmOverwrite(),
+		zenity.FileFilters{
+			{Name: "Text", Patterns: []string{"*.txt"}},
+			{Name: "SubRip", Patterns: []string{"*.srt"}},
+			{Name: "JSON", Patterns: []string{"*.json"}},
+		},
+	)
+	if err != nil {
+		return "", err
+	}
+	lower := strings.ToLower(path)
+	for _, ext := range []string{".txt", ".srt", ".json"} {
+		if strings.HasSuffix(lower, ext) {
+			return path, nil
+		}
+	}
+	return confirmAppended(title, path+".txt")
+}
+
+// confirmAppended спрашивает, заменить ли существующий файл path, если
+// расширение добавлено после диалога: диалог проверял перезапись по пути
+// без расширения. Отказ возвращается как отмена диалога.
+func confirmAppended(title, path string) (string, error) {
+	if _, err := os.Stat(path); err == nil {
+		if !Confirm(title, fmt.Sprintf(i18n.T("dialog_overwrite"), filepath.Base(path))) {
+			return "", zenity.ErrCanceled
+		}
+	}
+	return path, nil
+}
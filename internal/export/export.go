@@ -0,0 +1,147 @@
+// Package export сохраняет распознанный за сессию текст в файл:
+// простой текст, субтитры SRT или JSON.
+package export
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Format - формат файла экспорта.
+type Format string
+
+const (
+	FormatText Format = "txt"
+	FormatSRT  Format = "srt"
+	FormatJSON Format = "json"
+)
+
+// Formats возвращает поддерживаемые форматы.
+func Formats() []Format {
+	return []Format{FormatText, FormatSRT, FormatJSON}
+}
+
+// FormatFromPath определяет формат по расширению файла.
+// Неизвестное расширение - простой текст.
+func FormatFromPath(path string) Format {
+	ext := Format(strings.ToLower(strings.TrimPrefix(filepath.Ext(path), ".")))
+	for _, f := range Formats() {
+		if ext == f {
+			return f
+		}
+	}
+	return FormatText
+}
+
+// Entry - одна распознанная запись сессии.
+type Entry struct {
+	Time     time.Time     `json:"time"`     // начало записи
+	Duration time.Duration `json:"duration"` // длительность записи в наносекундах
+	Text     string        `json:"text"`
+}
+
+// document - содержимое JSON файла. Версия позволит менять формат,
+// не ломая чтение старых файлов.
+type document struct {
+	Version int     `json:"version"`
+	Entries []Entry `json:"entries"`
+}
+
+const jsonVersion = 1
+
+// WriteFile сохраняет записи в файл, формат выбирается по расширению.
+func WriteFile(path string, entries []Entry) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := Write(f, FormatFromPath(path), entries); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// Write записывает записи в w в формате format.
+func Write(w io.Writer, format Format, entries []Entry) error {
+	switch format {
+	case FormatSRT:
+		return writeSRT(w, entries)
+	case FormatJSON:
+		return writeJSON(w, entries)
+	default:
+		return writeText(w, entries)
+	}
+}
+
+// ReadJSON читает записи из файла, сохранённого в FormatJSON.
+func ReadJSON(r io.Reader) ([]Entry, error) {
+	var doc document
+	if err := json.NewDecoder(r).Decode(&doc); err != nil {
+		return nil, err
+	}
+	if doc.Version > jsonVersion {
+		return nil, fmt.Errorf("export: неизвестная версия файла %d", doc.Version)
+	}
+	return doc.Entries, nil
+}
+
+// writeText пишет каждую запись отдельной строкой. Пустые записи пропускаются.
+func writeText(w io.Writer, entries []Entry) error {
+	for _, e := range entries {
+		text := strings.TrimSpace(e.Text)
+		if text == "" {
+			continue
+		}
+		if _, err := io.WriteString(w, text+"\n"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeSRT пишет записи как субтитры: время отсчитывается от начала
+// первой записи, каждая запись - один субтитр на всю её длительность.
+// Пустые записи пропускаются: пустая строка в SRT завершает субтитр.
+func writeSRT(w io.Writer, entries []Entry) error {
+	if len(entries) == 0 {
+		return nil
+	}
+	origin := entries[0].Time
+	n := 0
+	for _, e := range entries {
+		text := strings.TrimSpace(e.Text)
+		if text == "" {
+			continue
+		}
+		n++
+		start := max(e.Time.Sub(origin), 0)
+		end := start + e.Duration
+		_, err := fmt.Fprintf(w, "%d\n%s --> %s\n%s\n\n", n, srtTime(start), srtTime(end), text)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// srtTime форматирует смещение как ЧЧ:ММ:СС,ммм.
+func srtTime(d time.Duration) string {
+	ms := d.Milliseconds()
+	return fmt.Sprintf("%02d:%02d:%02d,%03d", ms/3600000, ms/60000%60, ms/1000%60, ms%1000)
+}
+
+// writeJSON пишет записи с отступами, читается обратно через ReadJSON.
+func writeJSON(w io.Writer, entries []Entry) error {
+	if entries == nil {
+		entries = []Entry{}
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(document{Version: jsonVersion, Entries: entries})
+}
@@ -0,0 +1,115 @@
+package export
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+var origin = time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+
+func TestFormatFromPath(t *testing.T) {
+	tests := map[string]Format{
+		"out.txt":  FormatText,
+		"out.SRT":  FormatSRT,
+		"out.json": FormatJSON,
+		"out.md":   FormatText,
+		"out":      FormatText,
+	}
+	for path, want := range tests {
+		if got := FormatFromPath(path); got != want {
+			t.Errorf("FormatFromPath(%q) = %q, want %q", path, got, want)
+		}
+	}
+}
+
+func TestWriteText(t *testing.T) {
+	entries := []Entry{
+		{Time: origin, Text: " Привет "},
+		{Time: origin.Add(time.Minute), Text: "  "},
+		{Time: origin.Add(2 * time.Minute), Text: "мир"},
+	}
+	var buf bytes.Buffer
+	if err := Write(&buf, FormatText, entries); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := buf.String(), "Привет\nмир\n"; got != want {
+		t.Errorf("text = %q, want %q", got, want)
+	}
+}
+
+func TestWriteSRT(t *testing.T) {
+	entries := []Entry{
+		{Time: origin, Duration: 2500 * time.Millisecond, Text: "первая"},
+		{Time: origin.Add(30 * time.Second), Duration: time.Second, Text: ""},
+		{Time: origin.Add(time.Hour + 61*time.Second + 7*time.Millisecond), Duration: 1500 * time.Millisecond, Text: "вторая"},
+	}
+	var buf bytes.Buffer
+	if err := Write(&buf, FormatSRT, entries); err != nil {
+		t.Fatal(err)
+	}
+	want := "1\n00:00:00,000 --> 00:00:02,500\nпервая\n\n" +
+		"2\n01:01:01,007 --> 01:01:02,507\nвторая\n\n"
+	if got := buf.String(); got != want {
+		t.Errorf("srt =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestWriteEmpty(t *testing.T) {
+	for _, format := range []Format{FormatText, FormatSRT} {
+		var buf bytes.Buffer
+		if err := Write(&buf, format, nil); err != nil {
+			t.Fatal(err)
+		}
+		if buf.Len() != 0 {
+			t.Errorf("%s: пустая история записала %q", format, buf.String())
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := Write(&buf, FormatJSON, nil); err != nil {
+		t.Fatal(err)
+	}
+	entries, err := ReadJSON(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if entries == nil || len(entries) != 0 {
+		t.Errorf("json: пустая история прочитана как %#v", entries)
+	}
+}
+
+func TestJSONRoundTrip(t *testing.T) {
+	entries := []Entry{
+		{Time: origin, Duration: 2500 * time.Millisecond, Text: "первая"},
+		{Time: origin.Add(time.Minute), Duration: time.Second, Text: "second \"quoted\""},
+	}
+	path := filepath.Join(t.TempDir(), "history.json")
+	if err := WriteFile(path, entries); err != nil {
+		t.Fatal(err)
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	got, err := ReadJSON(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, entries) {
+		t.Errorf("ReadJSON = %#v, want %#v", got, entries)
+	}
+}
+
+func TestReadJSONNewerVersion(t *testing.T) {
+	_, err := ReadJSON(strings.NewReader(`{"version": 99, "entries": []}`))
+	if err == nil {
+		t.Error("файл новой версии прочитан без ошибки")
+	}
+}
@@ -0,0 +1,17 @@
+// Package focus не даёт окнам приложения забирать фокус ввода у окна,
+// в котором работает пользователь (полезно в тайловых оконных менеджерах).
+package focus
+
+import "time"
+
+// appearTimeout - сколько ждать появления нового окна
+const appearTimeout = time.Second
+
+// Keep запоминает активное окно. Возвращённую функцию нужно вызвать после
+// создания окна с заголовком windowTitle: она дожидается его появления и,
+// если фокус ушёл к нему, возвращает фокус запомненному окну. Окно получит
+// фокус, когда пользователь щёлкнет по нему.
+// Работает под X11, на остальных платформах ничего не делает.
+func Keep() (restore func(windowTitle string)) {
+	return keep()
+}
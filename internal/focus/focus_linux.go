@@ -0,0 +1,66 @@
+//go:build linux
+
+package focus
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// activateTimeout ограничивает активацию окна: с --sync xdotool ждёт её
+// бесконечно, если оконный менеджер отказал или окно уже закрыто.
+const activateTimeout = 2 * time.Second
+
+func keep() func(windowTitle string) {
+	prev, err := ActiveWindow()
+	if err != nil || prev == "" || os.Getenv("WAYLAND_DISPLAY") != "" {
+		return func(string) {}
+	}
+
+	return func(windowTitle string) {
+		deadline := time.Now().Add(appearTimeout)
+		for time.Now().Before(deadline) {
+			time.Sleep(50 * time.Millisecond)
+			ids := findWindows(windowTitle)
+			if len(ids) == 0 {
+				continue
+			}
+			active, _ := ActiveWindow()
+			for _, id := range ids {
+				if id == active {
+					Activate(prev)
+					return
+				}
+			}
+		}
+	}
+}
+
+// ActiveWindow возвращает id активного окна X11 (xdotool getactivewindow).
+// Вместе с Activate используется и для возврата фокуса при вводе текста.
+func ActiveWindow() (string, error) {
+	out, err := exec.Command("xdotool", "getactivewindow").Output()
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// Activate активирует окно X11 и ждёт этого не дольше activateTimeout.
+func Activate(window string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), activateTimeout)
+	defer cancel()
+	return exec.CommandContext(ctx, "xdotool", "windowactivate", "--sync", window).Run()
+}
+
+// findWindows возвращает id окон с заголовком windowTitle.
+func findWindows(windowTitle string) []string {
+	out, err := exec.Command("xdotool", "search", "--name", windowTitle).Output()
+	if err != nil {
+		return nil
+	}
+	return strings.Fields(string(out))
+}
@@ -0,0 +1,7 @@
+//go:build linux
+
+package hotkey
+
+// followupSupported - вторая клавиша последовательности захватывается
+// временной регистрацией без модификаторов (XGrabKey).
+const followupSupported = true
@@ -0,0 +1,7 @@
+//go:build !linux
+
+package hotkey
+
+// followupSupported - временный захват второй клавиши пока проверен
+// только под X11: здесь последовательность срабатывает по ведущему сочетанию.
+const followupSupported = false
@@ -2,13 +2,13 @@
 package hotkey
 
 import (
-	"log"
 	"sync"
 	"time"
 
 	"golang.design/x/hotkey"
 	"golang.design/x/hotkey/mainthread"
 	"shofar/internal/config"
+	"shofar/internal/logx"
 )
 
 // Handler обрабатывает события горячих клавиш.
@@ -19,19 +19,82 @@ type Handler struct {
 	onRelease func()
 	current   config.HotkeyConfig
 	stopCh    chan struct{}
+	debounce  time.Duration // повторные нажатия чаще игнорируются
+	repeat    bool          // отсекать автоповтор по событиям отпускания
+	followup  time.Duration // ожидание второй клавиши последовательности
 }
 
+// grab - зарегистрированное сочетание клавиш. Интерфейс нужен тестам:
+// настоящий *hotkey.Hotkey требует дисплея.
+type grab interface {
+	Register() error
+	Unregister() error
+	Keydown() <-chan hotkey.Event
+	Keyup() <-chan hotkey.Event
+}
+
+// unregisterTimeout - сколько ждать отмены регистрации. В X11 она
+// завершается только после отпускания захваченной клавиши, поэтому
+// может не завершиться вовсе.
+const unregisterTimeout = 500 * time.Millisecond
+
+// unregister отменяет регистрацию hk, но ждёт не дольше unregisterTimeout.
+func unregister(hk grab) {
+	done := make(chan struct{})
+	go func() {
+		hk.Unregister()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(unregisterTimeout):
+		logx.Warn("Hotkey unregister timeout")
+	}
+}
+
+// DefaultDebounce - минимальный интервал между нажатиями по умолчанию.
+const DefaultDebounce = 300 * time.Millisecond
+
+// repeatGap - keydown раньше этого после keyup считается автоповтором:
+// X11 шлёт автоповтор парами отпускание/нажатие почти без паузы, а между
+// настоящими нажатиями рука тратит заметно больше времени.
+const repeatGap = 40 * time.Millisecond
+
 // New создаёт обработчик горячей клавиши.
 func New(onPress, onRelease func()) *Handler {
 	return &Handler{
 		onPress:   onPress,
 		onRelease: onRelease,
+		debounce:  DefaultDebounce,
+		followup:  DefaultFollowupTimeout,
+	}
+}
+
+// DefaultFollowupTimeout - ожидание второй клавиши по умолчанию.
+const DefaultFollowupTimeout = time.Second
+
+// SetDebounce задаёт минимальный интервал между нажатиями и включает
+// отсечение автоповтора зажатой клавиши. Применяется при следующей Register.
+func (h *Handler) SetDebounce(interval time.Duration, detectRepeat bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.debounce = interval
+	h.repeat = detectRepeat
+}
+
+// SetFollowupTimeout задаёт, сколько после ведущего сочетания ждать вторую
+// клавишу последовательности. Применяется при следующей Register.
+func (h *Handler) SetFollowupTimeout(timeout time.Duration) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if timeout > 0 {
+		h.followup = timeout
 	}
 }
 
-// Register регистрирует горячую клавишу.
+// Register регистрирует горячую клавишу: сочетание клавиш или кнопку мыши.
 func (h *Handler) Register(cfg config.HotkeyConfig) error {
-	log.Printf("Регистрация горячей клавиши: %s", cfg.String())
+	logx.Debug("Регистрация горячей клавиши", "hotkey", cfg.String())
 
 	h.mu.Lock()
 
@@ -51,21 +114,24 @@ func (h *Handler) Register(cfg config.HotkeyConfig) error {
 
 	// Отменяем предыдущую регистрацию в горутине с таймаутом
 	if oldHk != nil {
-		done := make(chan struct{})
-		go func() {
-			oldHk.Unregister()
-			close(done)
-		}()
-		select {
-		case <-done:
-		case <-time.After(500 * time.Millisecond):
-			log.Printf("Hotkey unregister timeout")
-		}
+		unregister(oldHk)
 	}
 
 	h.mu.Lock()
 	defer h.mu.Unlock()
 
+	if cfg.IsMouse() {
+		stopCh := make(chan struct{})
+		if err := listenMouse(cfg.Button, stopCh, h.onPress); err != nil {
+			logx.Error("Ошибка регистрации кнопки мыши", "err", err)
+			return err
+		}
+		h.current = cfg
+		h.stopCh = stopCh
+		logx.Info("Горячая клавиша зарегистрирована", "hotkey", cfg.String())
+		return nil
+	}
+
 	// Конвертируем модификаторы
 	mods := make([]hotkey.Modifier, 0, len(cfg.Modifiers))
 	for _, m := range cfg.Modifiers {
@@ -75,7 +141,7 @@ func (h *Handler) Register(cfg config.HotkeyConfig) error {
 	}
 
 	// Конвертируем клавишу
-	key, ok := keyMap[cfg.Key]
+	key, ok := lookupKey(cfg.Key)
 	if !ok {
 		key = hotkey.KeySpace // fallback
 	}
@@ -85,29 +151,46 @@ func (h *Handler) Register(cfg config.HotkeyConfig) error {
 	h.stopCh = make(chan struct{})
 
 	if err := h.hk.Register(); err != nil {
-		log.Printf("Ошибка регистрации: %v", err)
+		logx.Error("Ошибка регистрации", "err", err)
 		h.hk = nil
 		h.stopCh = nil
 		return err
 	}
 
-	log.Printf("Горячая клавиша успешно зарегистрирована: %s", cfg.String())
+	logx.Info("Горячая клавиша зарегистрирована", "hotkey", cfg.String())
 	go h.listen(h.stopCh)
 	return nil
 }
 
+// followupKey возвращает вторую клавишу последовательности из cfg.
+// ok=false - клавиши нет или её не захватить, срабатывает само сочетание.
+func followupKey(cfg config.HotkeyConfig) (key hotkey.Key, ok bool) {
+	if cfg.FollowupKey == "" {
+		return 0, false
+	}
+	if !followupSupported {
+		logx.Warn("Вторая клавиша поддерживается только в Linux, срабатывает ведущее сочетание", "key", cfg.FollowupKey)
+		return 0, false
+	}
+	key, ok = lookupKey(cfg.FollowupKey)
+	if !ok {
+		logx.Warn("Неизвестная вторая клавиша, срабатывает ведущее сочетание", "key", cfg.FollowupKey)
+	}
+	return key, ok
+}
+
 func (h *Handler) listen(stopCh chan struct{}) {
 	h.mu.Lock()
 	hk := h.hk
+	filter := pressFilter{interval: h.debounce, repeat: h.repeat, now: time.Now}
+	next, hasNext := followupKey(h.current)
+	timeout := h.followup
 	h.mu.Unlock()
 
 	if hk == nil {
 		return
 	}
 
-	var lastKeydown time.Time
-	const debounceInterval = 300 * time.Millisecond // Защита от key repeat
-
 	for {
 		select {
 		case <-stopCh:
@@ -117,11 +200,12 @@ func (h *Handler) listen(stopCh chan struct{}) {
 				return
 			}
 			// Debounce: игнорируем повторные keydown от key repeat
-			now := time.Now()
-			if now.Sub(lastKeydown) < debounceInterval {
+			if !filter.down() {
+				continue
+			}
+			if hasNext && !awaitFollowup(hk, hotkey.New(nil, next), timeout, stopCh, &filter) {
 				continue
 			}
-			lastKeydown = now
 			if h.onPress != nil {
 				h.onPress()
 			}
@@ -129,11 +213,80 @@ func (h *Handler) listen(stopCh chan struct{}) {
 			if !ok {
 				return
 			}
+			filter.up()
 			// В toggle режиме игнорируем keyup
 		}
 	}
 }
 
+// awaitFollowup захватывает вторую клавишу последовательности next на
+// timeout и возвращает true, если её нажали. Отпускание ведущего сочетания
+// за это время передаётся фильтру. Захват снимается в фоне: в X11 отмена
+// регистрации ждёт отпускания клавиши, и если её так и не нажали, захват
+// держится до следующего нажатия - оно уходит Shofar, а не приложению.
+// Слушатель ведущего сочетания при этом не ждёт.
+func awaitFollowup(leader, next grab, timeout time.Duration, stopCh chan struct{}, filter *pressFilter) bool {
+	if err := next.Register(); err != nil {
+		logx.Warn("Не удалось захватить вторую клавишу", "err", err)
+		return false
+	}
+	defer func() { go unregister(next) }()
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	for {
+		select {
+		case <-stopCh:
+			return false
+		case <-timer.C:
+			logx.Debug("Вторая клавиша не нажата")
+			return false
+		case _, ok := <-next.Keydown():
+			return ok
+		case _, ok := <-leader.Keyup():
+			if !ok {
+				return false
+			}
+			filter.up()
+		}
+	}
+}
+
+// pressFilter решает, считать ли keydown новым нажатием.
+type pressFilter struct {
+	interval time.Duration
+	repeat   bool
+	lastDown time.Time // последнее принятое нажатие
+	lastUp   time.Time
+	held     bool             // клавиша нажата и ещё не отпущена
+	now      func() time.Time // часы, в тестах подменяются
+}
+
+// down возвращает true для нового нажатия и false для автоповтора или
+// нажатия раньше interval после предыдущего.
+func (f *pressFilter) down() bool {
+	now := f.now()
+	if f.repeat {
+		// Автоповтор: keydown без отпускания (Windows, macOS) или сразу за keyup (X11)
+		isRepeat := f.held || (!f.lastUp.IsZero() && now.Sub(f.lastUp) < repeatGap)
+		f.held = true
+		if isRepeat {
+			return false
+		}
+	}
+	if now.Sub(f.lastDown) < f.interval {
+		return false
+	}
+	f.lastDown = now
+	return true
+}
+
+// up отмечает отпускание клавиши.
+func (f *pressFilter) up() {
+	f.held = false
+	f.lastUp = f.now()
+}
+
 // Unregister отменяет регистрацию горячей клавиши.
 func (h *Handler) Unregister() error {
 	h.mu.Lock()
@@ -164,16 +317,31 @@ func RunOnMainThread(fn func()) {
 	mainthread.Init(fn)
 }
 
+// listenMouse определён в mouse_*.go: захватывает кнопку мыши и вызывает
+// onPress при каждом нажатии, пока не закрыт stopCh.
+
 // modifierMap определён в platform-specific файлах:
 // - modifiers_linux.go
 // - modifiers_darwin.go
 // - modifiers_windows.go
+//
+// platformKeyMap (цифры и пунктуация) аналогично в keys_*.go.
+
+// lookupKey возвращает hotkey.Key для config.Key.
+func lookupKey(k config.Key) (hotkey.Key, bool) {
+	if key, ok := keyMap[k]; ok {
+		return key, true
+	}
+	key, ok := platformKeyMap[k]
+	return key, ok
+}
 
 // keyMap маппинг config.Key -> hotkey.Key
 var keyMap = map[config.Key]hotkey.Key{
 	config.KeySpace:  hotkey.KeySpace,
 	config.KeyReturn: hotkey.KeyReturn,
 	config.KeyTab:    hotkey.KeyTab,
+	config.KeyEscape: hotkey.KeyEscape,
 	config.KeyA:      hotkey.KeyA,
 	config.KeyB:      hotkey.KeyB,
 	config.KeyC:      hotkey.KeyC,
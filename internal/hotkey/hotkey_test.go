@@ -0,0 +1,162 @@
+package hotkey
+
+import (
+	"testing"
+	"time"
+
+	"golang.design/x/hotkey"
+)
+
+// fakeClock - часы, которые двигает тест.
+type fakeClock struct {
+	t time.Time
+}
+
+func (c *fakeClock) now() time.Time { return c.t }
+
+func (c *fakeClock) advance(d time.Duration) { c.t = c.t.Add(d) }
+
+func newTestFilter(repeat bool) (*pressFilter, *fakeClock) {
+	clock := &fakeClock{t: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+	return &pressFilter{interval: DefaultDebounce, repeat: repeat, now: clock.now}, clock
+}
+
+func TestPressFilterBounce(t *testing.T) {
+	f, clock := newTestFilter(false)
+
+	if !f.down() {
+		t.Fatal("первое нажатие отброшено")
+	}
+	f.up()
+
+	// Дребезг контакта: повторное нажатие через 50ms
+	clock.advance(50 * time.Millisecond)
+	if f.down() {
+		t.Error("нажатие раньше интервала принято")
+	}
+	f.up()
+
+	// Отброшенное нажатие не сдвигает интервал
+	clock.advance(DefaultDebounce - 50*time.Millisecond)
+	if !f.down() {
+		t.Error("нажатие после интервала отброшено")
+	}
+}
+
+func TestPressFilterDoublePress(t *testing.T) {
+	f, clock := newTestFilter(true)
+
+	if !f.down() {
+		t.Fatal("первое нажатие отброшено")
+	}
+	clock.advance(80 * time.Millisecond)
+	f.up()
+
+	// Быстрое, но осознанное повторное нажатие (остановка записи)
+	clock.advance(DefaultDebounce)
+	if !f.down() {
+		t.Error("повторное нажатие после интервала отброшено")
+	}
+}
+
+func TestPressFilterRepeat(t *testing.T) {
+	f, clock := newTestFilter(true)
+
+	if !f.down() {
+		t.Fatal("первое нажатие отброшено")
+	}
+
+	// Автоповтор Windows и macOS: keydown без отпускания, даже после интервала
+	clock.advance(DefaultDebounce * 2)
+	if f.down() {
+		t.Error("автоповтор удерживаемой клавиши принят")
+	}
+
+	// Автоповтор X11: keyup и сразу keydown
+	clock.advance(30 * time.Millisecond)
+	f.up()
+	clock.advance(repeatGap / 2)
+	if f.down() {
+		t.Error("автоповтор X11 принят")
+	}
+
+	// Настоящее отпускание и новое нажатие
+	f.up()
+	clock.advance(DefaultDebounce)
+	if !f.down() {
+		t.Error("новое нажатие после отпускания отброшено")
+	}
+}
+
+// fakeGrab - сочетание клавиш без дисплея. Unregister может висеть,
+// как в X11, пока клавишу не отпустят.
+type fakeGrab struct {
+	down, up chan hotkey.Event
+	release  chan struct{} // Unregister ждёт его закрытия, nil - не ждёт
+	released chan struct{}
+}
+
+func newFakeGrab() *fakeGrab {
+	return &fakeGrab{
+		down:     make(chan hotkey.Event, 1),
+		up:       make(chan hotkey.Event, 1),
+		released: make(chan struct{}),
+	}
+}
+
+func (g *fakeGrab) Register() error { return nil }
+
+func (g *fakeGrab) Unregister() error {
+	if g.release != nil {
+		<-g.release
+	}
+	close(g.released)
+	return nil
+}
+
+func (g *fakeGrab) Keydown() <-chan hotkey.Event { return g.down }
+func (g *fakeGrab) Keyup() <-chan hotkey.Event   { return g.up }
+
+func TestAwaitFollowupPressed(t *testing.T) {
+	leader, next := newFakeGrab(), newFakeGrab()
+	f, _ := newTestFilter(false)
+	next.down <- hotkey.Event{}
+
+	if !awaitFollowup(leader, next, time.Second, make(chan struct{}), f) {
+		t.Fatal("нажатая вторая клавиша не принята")
+	}
+	select {
+	case <-next.released:
+	case <-time.After(time.Second):
+		t.Fatal("захват второй клавиши не снят")
+	}
+}
+
+// TestAwaitFollowupTimeoutDoesNotBlock проверяет, что зависшая отмена
+// захвата не задерживает слушатель ведущего сочетания.
+func TestAwaitFollowupTimeoutDoesNotBlock(t *testing.T) {
+	leader, next := newFakeGrab(), newFakeGrab()
+	next.release = make(chan struct{})
+	defer close(next.release)
+	f, _ := newTestFilter(false)
+
+	start := time.Now()
+	if awaitFollowup(leader, next, 20*time.Millisecond, make(chan struct{}), f) {
+		t.Fatal("вторая клавиша принята без нажатия")
+	}
+	if elapsed := time.Since(start); elapsed > unregisterTimeout/2 {
+		t.Errorf("ожидание заняло %v: слушатель ждал отмены захвата", elapsed)
+	}
+}
+
+func TestAwaitFollowupPassesLeaderRelease(t *testing.T) {
+	leader, next := newFakeGrab(), newFakeGrab()
+	f, _ := newTestFilter(true)
+	f.down()
+	leader.up <- hotkey.Event{}
+
+	awaitFollowup(leader, next, 20*time.Millisecond, make(chan struct{}), f)
+	if f.held {
+		t.Error("отпускание ведущего сочетания не передано фильтру")
+	}
+}
@@ -0,0 +1,34 @@
+//go:build darwin
+
+package hotkey
+
+import (
+	"golang.design/x/hotkey"
+	"shofar/internal/config"
+)
+
+// platformKeyMap маппинг цифр и пунктуации -> virtual key code macOS (kVK_ANSI_*).
+var platformKeyMap = map[config.Key]hotkey.Key{
+	config.Key0: hotkey.Key0,
+	config.Key1: hotkey.Key1,
+	config.Key2: hotkey.Key2,
+	config.Key3: hotkey.Key3,
+	config.Key4: hotkey.Key4,
+	config.Key5: hotkey.Key5,
+	config.Key6: hotkey.Key6,
+	config.Key7: hotkey.Key7,
+	config.Key8: hotkey.Key8,
+	config.Key9: hotkey.Key9,
+
+	config.KeyGrave:        0x32, // kVK_ANSI_Grave
+	config.KeyMinus:        0x1B, // kVK_ANSI_Minus
+	config.KeyEqual:        0x18, // kVK_ANSI_Equal
+	config.KeyBracketLeft:  0x21, // kVK_ANSI_LeftBracket
+	config.KeyBracketRight: 0x1E, // kVK_ANSI_RightBracket
+	config.KeySemicolon:    0x29, // kVK_ANSI_Semicolon
+	config.KeyApostrophe:   0x27, // kVK_ANSI_Quote
+	config.KeyComma:        0x2B, // kVK_ANSI_Comma
+	config.KeyPeriod:       0x2F, // kVK_ANSI_Period
+	config.KeySlash:        0x2C, // kVK_ANSI_Slash
+	config.KeyBackslash:    0x2A, // kVK_ANSI_Backslash
+}
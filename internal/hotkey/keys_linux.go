@@ -0,0 +1,36 @@
+//go:build linux
+
+package hotkey
+
+import (
+	"golang.design/x/hotkey"
+	"shofar/internal/config"
+)
+
+// platformKeyMap маппинг цифр и пунктуации -> X11 keysym.
+// Константы hotkey.Key0..Key9 для Linux сдвинуты на единицу (Key1 = XK_0),
+// поэтому цифры задаём напрямую через keysym.
+var platformKeyMap = map[config.Key]hotkey.Key{
+	config.Key0: 0x0030,
+	config.Key1: 0x0031,
+	config.Key2: 0x0032,
+	config.Key3: 0x0033,
+	config.Key4: 0x0034,
+	config.Key5: 0x0035,
+	config.Key6: 0x0036,
+	config.Key7: 0x0037,
+	config.Key8: 0x0038,
+	config.Key9: 0x0039,
+
+	config.KeyGrave:        0x0060, // XK_grave
+	config.KeyMinus:        0x002d, // XK_minus
+	config.KeyEqual:        0x003d, // XK_equal
+	config.KeyBracketLeft:  0x005b, // XK_bracketleft
+	config.KeyBracketRight: 0x005d, // XK_bracketright
+	config.KeySemicolon:    0x003b, // XK_semicolon
+	config.KeyApostrophe:   0x0027, // XK_apostrophe
+	config.KeyComma:        0x002c, // XK_comma
+	config.KeyPeriod:       0x002e, // XK_period
+	config.KeySlash:        0x002f, // XK_slash
+	config.KeyBackslash:    0x005c, // XK_backslash
+}
@@ -0,0 +1,34 @@
+//go:build windows
+
+package hotkey
+
+import (
+	"golang.design/x/hotkey"
+	"shofar/internal/config"
+)
+
+// platformKeyMap маппинг цифр и пунктуации -> virtual-key code Windows (VK_OEM_*).
+var platformKeyMap = map[config.Key]hotkey.Key{
+	config.Key0: hotkey.Key0,
+	config.Key1: hotkey.Key1,
+	config.Key2: hotkey.Key2,
+	config.Key3: hotkey.Key3,
+	config.Key4: hotkey.Key4,
+	config.Key5: hotkey.Key5,
+	config.Key6: hotkey.Key6,
+	config.Key7: hotkey.Key7,
+	config.Key8: hotkey.Key8,
+	config.Key9: hotkey.Key9,
+
+	config.KeyGrave:        0xC0, // VK_OEM_3
+	config.KeyMinus:        0xBD, // VK_OEM_MINUS
+	config.KeyEqual:        0xBB, // VK_OEM_PLUS
+	config.KeyBracketLeft:  0xDB, // VK_OEM_4
+	config.KeyBracketRight: 0xDD, // VK_OEM_6
+	config.KeySemicolon:    0xBA, // VK_OEM_1
+	config.KeyApostrophe:   0xDE, // VK_OEM_7
+	config.KeyComma:        0xBC, // VK_OEM_COMMA
+	config.KeyPeriod:       0xBE, // VK_OEM_PERIOD
+	config.KeySlash:        0xBF, // VK_OEM_2
+	config.KeyBackslash:    0xDC, // VK_OEM_5
+}
@@ -0,0 +1,112 @@
+//go:build linux
+
+package hotkey
+
+/*
+#cgo LDFLAGS: -lX11
+#include <X11/Xlib.h>
+
+static int grabFailed;
+
+static int onGrabError(Display* d, XErrorEvent* e) {
+    grabFailed = 1;
+    return 0;
+}
+
+// grabButton открывает соединение с X сервером и захватывает кнопку мыши
+// на корневом окне с любыми модификаторами. Возвращает NULL, если X
+// недоступен или кнопку уже захватило другое приложение.
+static Display* grabButton(unsigned int button) {
+    Display* d = XOpenDisplay(NULL);
+    if (!d) {
+        return NULL;
+    }
+    grabFailed = 0;
+    XErrorHandler old = XSetErrorHandler(onGrabError);
+    XGrabButton(d, button, AnyModifier, DefaultRootWindow(d), False,
+        ButtonPressMask | ButtonReleaseMask, GrabModeAsync, GrabModeAsync, None, None);
+    XSync(d, False);
+    XSetErrorHandler(old);
+    if (grabFailed) {
+        XCloseDisplay(d);
+        return NULL;
+    }
+    return d;
+}
+
+// pendingPresses разбирает накопившиеся события и возвращает число нажатий.
+static int pendingPresses(Display* d) {
+    int presses = 0;
+    while (XPending(d) > 0) {
+        XEvent ev;
+        XNextEvent(d, &ev);
+        if (ev.type == ButtonPress) {
+            presses++;
+        }
+    }
+    return presses;
+}
+
+static void ungrabButton(Display* d, unsigned int button) {
+    XUngrabButton(d, button, AnyModifier, DefaultRootWindow(d));
+    XCloseDisplay(d);
+}
+*/
+import "C"
+
+import (
+	"errors"
+	"fmt"
+	"runtime"
+	"time"
+
+	"shofar/internal/config"
+)
+
+// mousePollInterval - период опроса событий X сервера
+const mousePollInterval = 10 * time.Millisecond
+
+// x11Buttons - номера кнопок мыши в X11.
+var x11Buttons = map[config.MouseButton]C.uint{
+	config.MouseMiddle:  2,
+	config.MouseBack:    8,
+	config.MouseForward: 9,
+}
+
+// listenMouse захватывает кнопку мыши через XGrabButton. Под Wayland
+// захват работает только над окнами XWayland.
+func listenMouse(button config.MouseButton, stopCh chan struct{}, onPress func()) error {
+	num, ok := x11Buttons[button]
+	if !ok {
+		return fmt.Errorf("неизвестная кнопка мыши: %q", button)
+	}
+
+	// Соединение Xlib используется только из одного потока
+	errCh := make(chan error, 1)
+	go func() {
+		runtime.LockOSThread()
+		defer runtime.UnlockOSThread()
+
+		display := C.grabButton(num)
+		if display == nil {
+			errCh <- errors.New("не удалось захватить кнопку мыши: X сервер недоступен или кнопка занята")
+			return
+		}
+		defer C.ungrabButton(display, num)
+		errCh <- nil
+
+		ticker := time.NewTicker(mousePollInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stopCh:
+				return
+			case <-ticker.C:
+			}
+			if C.pendingPresses(display) > 0 && onPress != nil {
+				onPress()
+			}
+		}
+	}()
+	return <-errCh
+}
@@ -0,0 +1,15 @@
+//go:build !linux
+
+package hotkey
+
+import (
+	"errors"
+
+	"shofar/internal/config"
+)
+
+// listenMouse не поддерживается: кнопки мыши как горячая клавиша
+// пока реализованы только для Linux (X11).
+func listenMouse(button config.MouseButton, stopCh chan struct{}, onPress func()) error {
+	return errors.New("кнопка мыши как горячая клавиша не поддерживается на этой платформе")
+}
@@ -5,9 +5,31 @@ package input
 type Typer interface {
 	// Type вводит текст в текущее активное поле.
 	Type(text string) error
+
+	// SaveFocus запоминает активное окно, чтобы Type вернул в него фокус
+	// перед вводом. Вызывается в начале записи.
+	SaveFocus()
+}
+
+// Options настройки ввода текста.
+type Options struct {
+	// ReplaceSelection - введённый текст заменяет выделение в поле, как при
+	// обычном наборе. Если false, перед вводом нажимается стрелка вправо:
+	// выделение снимается и текст добавляется после него (без выделения
+	// курсор сдвигается на символ). Учитывается на macOS и Windows.
+	ReplaceSelection bool
+
+	// RestoreFocus - перед вводом активировать окно, запомненное SaveFocus.
+	// Учитывается на Linux под X11: под Wayland окна активировать нельзя.
+	RestoreFocus bool
+
+	// TargetClass - WM_CLASS окна, в которое вводится текст независимо от
+	// фокуса: перед вводом оно ищется и активируется. Пусто - активное окно.
+	// Учитывается на Linux под X11.
+	TargetClass string
 }
 
 // New создаёт платформо-специфичный Typer.
-func New() (Typer, error) {
-	return newTyper()
+func New(opts Options) (Typer, error) {
+	return newTyper(opts)
 }
@@ -9,6 +9,23 @@ package input
 #import <Foundation/Foundation.h>
 #include <stdlib.h>
 
+// collapseSelection нажимает стрелку вправо: выделение снимается,
+// курсор встаёт в его конец.
+void collapseSelection() {
+    CGEventRef keyDown = CGEventCreateKeyboardEvent(NULL, 0x7C, true);
+    CGEventRef keyUp = CGEventCreateKeyboardEvent(NULL, 0x7C, false);
+
+    // Не учитываем удерживаемые модификаторы (Shift расширил бы выделение)
+    CGEventSetFlags(keyDown, 0);
+    CGEventSetFlags(keyUp, 0);
+
+    CGEventPost(kCGHIDEventTap, keyDown);
+    CGEventPost(kCGHIDEventTap, keyUp);
+
+    CFRelease(keyDown);
+    CFRelease(keyUp);
+}
+
 void typeText(const char* text) {
     NSString *str = [NSString stringWithUTF8String:text];
 
@@ -32,13 +49,22 @@ void typeText(const char* text) {
 import "C"
 import "unsafe"
 
-type darwinTyper struct{}
+type darwinTyper struct {
+	replaceSelection bool
+}
 
-func newTyper() (Typer, error) {
-	return &darwinTyper{}, nil
+func newTyper(opts Options) (Typer, error) {
+	return &darwinTyper{replaceSelection: opts.ReplaceSelection}, nil
 }
 
+// SaveFocus ничего не делает: macOS возвращает фокус сама.
+func (t *darwinTyper) SaveFocus() {}
+
 func (t *darwinTyper) Type(text string) error {
+	if !t.replaceSelection && text != "" {
+		C.collapseSelection()
+	}
+
 	cstr := C.CString(text)
 	defer C.free(unsafe.Pointer(cstr))
 	C.typeText(cstr)
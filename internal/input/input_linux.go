@@ -3,34 +3,173 @@
 package input
 
 import (
+	"fmt"
+	"log"
 	"os"
 	"os/exec"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"shofar/internal/focus"
+	"shofar/internal/tools"
 )
 
+// retryDelay - пауза перед повторной попыткой ввода.
+// xdotool/wtype иногда падают, если фокус ещё не вернулся в целевое окно.
+const retryDelay = 500 * time.Millisecond
+
 type linuxTyper struct {
-	useWayland bool
+	useWayland   bool
+	restoreFocus bool   // только под X11 и с установленным xdotool
+	targetClass  string // WM_CLASS окна для ввода, "" - активное окно
+
+	mu     sync.Mutex
+	window string // id окна из xdotool getactivewindow
 }
 
-func newTyper() (Typer, error) {
+// newTyper создаёт Typer для X11/Wayland. Программа ввода выбирается
+// tools.Current. ReplaceSelection не учитывается: xdotool, wtype и ydotool
+// вводят текст как обычный набор.
+func newTyper(opts Options) (Typer, error) {
+	useWayland := os.Getenv("WAYLAND_DISPLAY") != ""
 	t := &linuxTyper{
-		useWayland: os.Getenv("WAYLAND_DISPLAY") != "",
+		useWayland:   useWayland,
+		restoreFocus: opts.RestoreFocus && !useWayland && tools.Check("xdotool") == nil,
+	}
+	if opts.TargetClass != "" {
+		if useWayland {
+			log.Printf("Окно для ввода %q не используется: Wayland не даёт активировать окна", opts.TargetClass)
+		} else {
+			t.targetClass = opts.TargetClass
+		}
 	}
 	return t, nil
 }
 
+// findWindow возвращает id видимого окна с WM_CLASS class.
+func findWindow(class string) (string, error) {
+	if err := tools.Check("xdotool"); err != nil {
+		return "", err
+	}
+	// Без совпадений xdotool завершается с ошибкой и пустым выводом
+	out, _ := exec.Command("xdotool", "search", "--onlyvisible", "--class", class).Output()
+	window := firstWindow(string(out))
+	if window == "" {
+		return "", fmt.Errorf("окно %q не найдено", class)
+	}
+	return window, nil
+}
+
+// firstWindow возвращает первый id из вывода xdotool search (по строке
+// на окно) или "", если окон нет.
+func firstWindow(out string) string {
+	for _, line := range strings.Split(out, "\n") {
+		if id := strings.TrimSpace(line); id != "" {
+			return id
+		}
+	}
+	return ""
+}
+
+// activateTarget активирует окно targetClass. Если окна нет, текст не
+// вводится: иначе он ушёл бы в случайное окно.
+func (t *linuxTyper) activateTarget() error {
+	window, err := findWindow(t.targetClass)
+	if err != nil {
+		return err
+	}
+	if err := focus.Activate(window); err != nil {
+		return fmt.Errorf("не удалось активировать окно %q: %w", t.targetClass, err)
+	}
+	return nil
+}
+
+// SaveFocus запоминает активное окно X11.
+func (t *linuxTyper) SaveFocus() {
+	if !t.restoreFocus {
+		return
+	}
+	window, err := focus.ActiveWindow()
+	if err != nil {
+		log.Printf("Не удалось определить активное окно: %v", err)
+	}
+	t.mu.Lock()
+	t.window = window
+	t.mu.Unlock()
+}
+
+// activateSaved возвращает фокус окну, запомненному SaveFocus.
+// Ошибка не мешает вводу: текст уйдёт в текущее активное окно.
+func (t *linuxTyper) activateSaved() {
+	t.mu.Lock()
+	window := t.window
+	t.mu.Unlock()
+	if window == "" {
+		return
+	}
+	if err := focus.Activate(window); err != nil {
+		log.Printf("Не удалось вернуть фокус окну %s: %v", window, err)
+	}
+}
+
 func (t *linuxTyper) Type(text string) error {
-	if t.useWayland {
-		return t.typeWayland(text)
+	// Без программы ввода повтор бессмыслен: сразу говорим, что установить
+	tool, err := tools.Current().Require(tools.FeatureType)
+	if err != nil {
+		return err
+	}
+	// ydotool нажимает клавиши по кодам раскладки US и вместо кириллицы
+	// и других не-ASCII символов вводит мусор: такой текст вводят только
+	// wtype под Wayland и xdotool под X11
+	if tool == "ydotool" && !isASCII(text) {
+		if t.useWayland {
+			return &tools.MissingError{Name: "wtype"}
+		}
+		return &tools.MissingError{Name: "xdotool"}
 	}
-	return t.typeX11(text)
+
+	if t.targetClass != "" {
+		if err := t.activateTarget(); err != nil {
+			return err
+		}
+	} else {
+		t.activateSaved()
+	}
+
+	err = typeWith(tool, text)
+	if err == nil {
+		return nil
+	}
+
+	// Одна повторная попытка после более длинной паузы
+	log.Printf("Ошибка ввода текста (%s), повтор через %v: %v", tool, retryDelay, err)
+	time.Sleep(retryDelay)
+	return typeWith(tool, text)
 }
 
-func (t *linuxTyper) typeX11(text string) error {
-	cmd := exec.Command("xdotool", "type", "--clearmodifiers", "--", text)
-	return cmd.Run()
+// isASCII возвращает true, если текст состоит только из ASCII символов.
+func isASCII(text string) bool {
+	for i := 0; i < len(text); i++ {
+		if text[i] >= utf8.RuneSelf {
+			return false
+		}
+	}
+	return true
 }
 
-func (t *linuxTyper) typeWayland(text string) error {
-	cmd := exec.Command("wtype", text)
+// typeWith вводит текст программой tool.
+func typeWith(tool, text string) error {
+	var cmd *exec.Cmd
+	switch tool {
+	case "wtype":
+		cmd = exec.Command("wtype", text)
+	case "ydotool":
+		// Нужен запущенный ydotoold
+		cmd = exec.Command("ydotool", "type", "--", text)
+	default:
+		cmd = exec.Command("xdotool", "type", "--clearmodifiers", "--", text)
+	}
 	return cmd.Run()
 }
@@ -0,0 +1,23 @@
+//go:build linux
+
+package input
+
+import "testing"
+
+func TestFirstWindow(t *testing.T) {
+	tests := []struct {
+		out  string
+		want string
+	}{
+		{"", ""},
+		{"\n", ""},
+		{"83886087\n", "83886087"},
+		{"83886087\n94371850\n", "83886087"},
+		{"\n  \n83886087  \n94371850\n", "83886087"},
+	}
+	for _, tt := range tests {
+		if got := firstWindow(tt.out); got != tt.want {
+			t.Errorf("firstWindow(%q) = %q, want %q", tt.out, got, tt.want)
+		}
+	}
+}
@@ -3,7 +3,9 @@
 package input
 
 import (
+	"fmt"
 	"syscall"
+	"unicode"
 	"unicode/utf16"
 	"unsafe"
 )
@@ -20,6 +22,11 @@ const (
 	keyEventFUnicode  = 0x0004
 )
 
+const (
+	keyEventFExtendedKey = 0x0001
+	vkRight              = 0x27
+)
+
 type keyboardInput struct {
 	wVk         uint16
 	wScan       uint16
@@ -34,44 +41,105 @@ type input struct {
 	padding   uint64
 }
 
-type windowsTyper struct{}
+type windowsTyper struct {
+	replaceSelection bool
+}
 
-func newTyper() (Typer, error) {
-	return &windowsTyper{}, nil
+func newTyper(opts Options) (Typer, error) {
+	return &windowsTyper{replaceSelection: opts.ReplaceSelection}, nil
 }
 
+// SaveFocus ничего не делает: Windows возвращает фокус сама.
+func (t *windowsTyper) SaveFocus() {}
+
 func (t *windowsTyper) Type(text string) error {
-	runes := utf16.Encode([]rune(text))
-	inputs := make([]input, 0, len(runes)*2)
-
-	for _, r := range runes {
-		// Key down
-		inputs = append(inputs, input{
-			inputType: inputKeyboard,
-			ki: keyboardInput{
-				wScan:   r,
-				dwFlags: keyEventFUnicode,
+	for _, batch := range buildInputs(text, !t.replaceSelection) {
+		sent, _, err := procSendInput.Call(
+			uintptr(len(batch)),
+			uintptr(unsafe.Pointer(&batch[0])),
+			uintptr(unsafe.Sizeof(batch[0])),
+		)
+		// Ввод блокируется, например, в окна с правами администратора (UIPI)
+		if int(sent) != len(batch) {
+			return fmt.Errorf("SendInput: отправлено %d из %d событий: %w", sent, len(batch), err)
+		}
+	}
+	return nil
+}
+
+// maxInputsPerCall - предел событий в одном вызове SendInput. Слишком
+// длинный поток событий некоторые приложения теряют, поэтому большой
+// текст отправляется частями.
+const maxInputsPerCall = 256
+
+// buildInputs превращает текст в пачки событий клавиатуры для SendInput.
+// Символ не разрывается между пачками: суррогатная пара и буква вместе
+// с комбинируемыми знаками всегда уходят одним вызовом.
+func buildInputs(text string, collapseSelection bool) [][]input {
+	var batches [][]input
+	var batch []input
+
+	// Стрелка вправо снимает выделение, чтобы текст не заменил его
+	if collapseSelection && text != "" {
+		batch = append(batch,
+			input{
+				inputType: inputKeyboard,
+				ki:        keyboardInput{wVk: vkRight, dwFlags: keyEventFExtendedKey},
 			},
-		})
-		// Key up
-		inputs = append(inputs, input{
-			inputType: inputKeyboard,
-			ki: keyboardInput{
-				wScan:   r,
-				dwFlags: keyEventFUnicode | keyEventFKeyUp,
+			input{
+				inputType: inputKeyboard,
+				ki:        keyboardInput{wVk: vkRight, dwFlags: keyEventFExtendedKey | keyEventFKeyUp},
 			},
-		})
+		)
 	}
 
-	if len(inputs) == 0 {
-		return nil
+	for _, cluster := range clusters(text) {
+		events := unicodeEvents(cluster)
+		if len(batch) > 0 && len(batch)+len(events) > maxInputsPerCall {
+			batches = append(batches, batch)
+			batch = nil
+		}
+		batch = append(batch, events...)
 	}
+	if len(batch) > 0 {
+		batches = append(batches, batch)
+	}
+	return batches
+}
 
-	procSendInput.Call(
-		uintptr(len(inputs)),
-		uintptr(unsafe.Pointer(&inputs[0])),
-		uintptr(unsafe.Sizeof(inputs[0])),
-	)
+// unicodeEvents возвращает нажатия для символов cluster. Суррогатная пара
+// нажимается целиком: сначала обе половины вниз, затем обе вверх, иначе
+// приложение получает две одиночные половины и выводит мусор.
+func unicodeEvents(cluster []rune) []input {
+	var events []input
+	for _, r := range cluster {
+		units := utf16.Encode([]rune{r})
+		for _, u := range units {
+			events = append(events, input{
+				inputType: inputKeyboard,
+				ki:        keyboardInput{wScan: u, dwFlags: keyEventFUnicode},
+			})
+		}
+		for _, u := range units {
+			events = append(events, input{
+				inputType: inputKeyboard,
+				ki:        keyboardInput{wScan: u, dwFlags: keyEventFUnicode | keyEventFKeyUp},
+			})
+		}
+	}
+	return events
+}
 
-	return nil
+// clusters делит текст на символы: руна вместе со следующими за ней
+// комбинируемыми знаками (ударение, диерезис и т.п.).
+func clusters(text string) [][]rune {
+	var result [][]rune
+	for _, r := range text {
+		if n := len(result); n > 0 && unicode.Is(unicode.M, r) {
+			result[n-1] = append(result[n-1], r)
+			continue
+		}
+		result = append(result, []rune{r})
+	}
+	return result
 }
@@ -0,0 +1,102 @@
+//go:build windows
+
+package input
+
+import (
+	"reflect"
+	"testing"
+)
+
+// event - нажатие из пачки SendInput в удобном для сравнения виде.
+type event struct {
+	scan uint16
+	up   bool
+}
+
+func flatten(batches [][]input) []event {
+	var events []event
+	for _, batch := range batches {
+		for _, in := range batch {
+			if in.ki.dwFlags&keyEventFUnicode == 0 {
+				continue
+			}
+			events = append(events, event{scan: in.ki.wScan, up: in.ki.dwFlags&keyEventFKeyUp != 0})
+		}
+	}
+	return events
+}
+
+func TestBuildInputsUnicode(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want []event
+	}{
+		{
+			name: "ascii",
+			text: "ab",
+			want: []event{{'a', false}, {'a', true}, {'b', false}, {'b', true}},
+		},
+		{
+			// U+1F600 - суррогатная пара D83D DE00: обе половины вниз, затем обе вверх
+			name: "emoji surrogate pair",
+			text: "😀",
+			want: []event{{0xD83D, false}, {0xDE00, false}, {0xD83D, true}, {0xDE00, true}},
+		},
+		{
+			// «й» из «и» и комбинируемой краткой U+0306
+			name: "combining mark",
+			text: "\u0438\u0306",
+			want: []event{{0x0438, false}, {0x0438, true}, {0x0306, false}, {0x0306, true}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := flatten(buildInputs(tt.text, false))
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("buildInputs(%q) = %v, want %v", tt.text, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBuildInputsCollapseSelection(t *testing.T) {
+	batches := buildInputs("a", true)
+	if len(batches) != 1 || len(batches[0]) != 4 {
+		t.Fatalf("buildInputs = %v, want one batch of 4 events", batches)
+	}
+	if batches[0][0].ki.wVk != vkRight || batches[0][1].ki.dwFlags&keyEventFKeyUp == 0 {
+		t.Errorf("first events are not a right arrow press: %v", batches[0][:2])
+	}
+	if got := buildInputs("", true); len(got) != 0 {
+		t.Errorf("empty text produced %d batches", len(got))
+	}
+}
+
+// TestBuildInputsBatchBoundary проверяет, что суррогатная пара и буква
+// с комбинируемым знаком не разрываются между вызовами SendInput.
+func TestBuildInputsBatchBoundary(t *testing.T) {
+	// Одна буква - 2 события: после 127 букв в пачке остаётся место
+	// только для двух, а эмодзи занимает четыре
+	text := ""
+	for range maxInputsPerCall/2 - 1 {
+		text += "a"
+	}
+	text += "😀\u0438\u0306"
+
+	batches := buildInputs(text, false)
+	if len(batches) != 2 {
+		t.Fatalf("got %d batches, want 2", len(batches))
+	}
+	for i, batch := range batches {
+		if len(batch) > maxInputsPerCall {
+			t.Errorf("batch %d has %d events, limit %d", i, len(batch), maxInputsPerCall)
+		}
+	}
+	if got := len(batches[1]); got != 8 {
+		t.Errorf("second batch has %d events, want 8 (emoji and combined letter)", got)
+	}
+	if first := batches[1][0].ki.wScan; first != 0xD83D {
+		t.Errorf("second batch starts with %#x, want high surrogate", first)
+	}
+}
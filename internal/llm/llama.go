@@ -12,6 +12,17 @@ static struct llama_model_params get_default_model_params() {
     return llama_model_default_params();
 }
 
+// Implemented in Go (progress.go)
+extern bool goLlamaProgress(float progress, void * user_data);
+
+// Model params that report load progress to the Go callback identified by handle
+static struct llama_model_params get_progress_model_params(uintptr_t handle) {
+    struct llama_model_params params = llama_model_default_params();
+    params.progress_callback = goLlamaProgress;
+    params.progress_callback_user_data = (void *) handle;
+    return params;
+}
+
 // Helper function to create default context params
 static struct llama_context_params get_default_context_params() {
     return llama_context_default_params();
@@ -22,11 +33,32 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"log"
+	"runtime/cgo"
 	"strings"
 	"sync"
 	"unsafe"
 )
 
+// ErrContextFull is returned when the prompt plus generated text reached the
+// context size (n_ctx) and generation was cut short.
+var ErrContextFull = errors.New("llm context size exceeded")
+
+// ErrMaxTokens is returned when generation produced maxTokens tokens
+// without reaching end of generation.
+var ErrMaxTokens = errors.New("llm output reached the token limit")
+
+// Sampler selects how the next token is picked during generation.
+type Sampler string
+
+const (
+	// SamplerGreedy always picks the most likely token. Corrections stay
+	// deterministic and close to the input.
+	SamplerGreedy Sampler = "greedy"
+	// SamplerBalanced samples with a low temperature after top-k and top-p.
+	SamplerBalanced Sampler = "balanced"
+)
+
 // LlamaModel represents a loaded llama.cpp model.
 type LlamaModel struct {
 	mu      sync.Mutex
@@ -34,22 +66,59 @@ type LlamaModel struct {
 	ctx     *C.struct_llama_context
 	sampler *C.struct_llama_sampler
 	nCtx    int
+
+	// Buffers reused across calls under mu, grown only when too small
+	tokenBuf []C.llama_token // tokenize output
+	pieceBuf []byte          // appendPiece output
 }
 
 // NewLlamaModel loads a GGUF model from file.
-func NewLlamaModel(modelPath string, nCtx int) (*LlamaModel, error) {
+// nThreads sets n_threads and n_threads_batch; 0 keeps the llama.cpp default.
+// gpuLayers sets n_gpu_layers: 0 runs on the CPU only, a negative value
+// keeps the llama.cpp default (offload as much as the build supports).
+// If loading with GPU offload fails, the model is loaded again on the CPU.
+func NewLlamaModel(modelPath string, nCtx, nThreads, gpuLayers int) (*LlamaModel, error) {
+	return NewLlamaModelCtx(context.Background(), modelPath, nCtx, nThreads, gpuLayers, nil)
+}
+
+// NewLlamaModelCtx is like NewLlamaModel but reports load progress (0..1)
+// to progress, which may be nil, and aborts the load once ctx is done,
+// returning ctx.Err(). Loading a large GGUF file can take many seconds.
+func NewLlamaModelCtx(ctx context.Context, modelPath string, nCtx, nThreads, gpuLayers int, progress func(float32)) (*LlamaModel, error) {
 	if nCtx <= 0 {
 		nCtx = 2048
 	}
 
+	m, err := loadModel(ctx, modelPath, nCtx, nThreads, gpuLayers, progress)
+	if err == nil || gpuLayers == 0 || ctx.Err() != nil {
+		return m, err
+	}
+	// A GPU build fails here when the device is missing or out of memory;
+	// the CPU is slower but keeps correction working
+	log.Printf("LLM: loading with GPU offload failed (%v), retrying on CPU only", err)
+	return loadModel(ctx, modelPath, nCtx, nThreads, 0, progress)
+}
+
+// loadModel loads the model and creates its context with the given
+// n_gpu_layers (negative keeps the llama.cpp default).
+func loadModel(ctx context.Context, modelPath string, nCtx, nThreads, gpuLayers int, progress func(float32)) (*LlamaModel, error) {
 	cPath := C.CString(modelPath)
 	defer C.free(unsafe.Pointer(cPath))
 
-	// Model params
-	mparams := C.get_default_model_params()
+	// Model params; llama.cpp calls the progress callback while reading
+	// tensors and stops loading when it returns false
+	handle := cgo.NewHandle(&loadProgress{ctx: ctx, report: progress})
+	defer handle.Delete()
+	mparams := C.get_progress_model_params(C.uintptr_t(handle))
+	if gpuLayers >= 0 {
+		mparams.n_gpu_layers = C.int32_t(gpuLayers)
+	}
 
 	model := C.llama_model_load_from_file(cPath, mparams)
 	if model == nil {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
 		return nil, errors.New("failed to load model")
 	}
 
@@ -57,33 +126,66 @@ func NewLlamaModel(modelPath string, nCtx int) (*LlamaModel, error) {
 	cparams := C.get_default_context_params()
 	cparams.n_ctx = C.uint32_t(nCtx)
 	cparams.n_batch = C.uint32_t(512)
+	if nThreads > 0 {
+		cparams.n_threads = C.int32_t(nThreads)
+		cparams.n_threads_batch = C.int32_t(nThreads)
+	}
 
-	ctx := C.llama_init_from_model(model, cparams)
-	if ctx == nil {
+	lctx := C.llama_init_from_model(model, cparams)
+	if lctx == nil {
 		C.llama_model_free(model)
 		return nil, errors.New("failed to create context")
 	}
 
-	// Create sampler chain
-	sparams := C.llama_sampler_chain_default_params()
-	sampler := C.llama_sampler_chain_init(sparams)
-
-	// Add samplers: temp -> top_k -> top_p -> greedy
-	C.llama_sampler_chain_add(sampler, C.llama_sampler_init_temp(0.1))
-	C.llama_sampler_chain_add(sampler, C.llama_sampler_init_top_k(40))
-	C.llama_sampler_chain_add(sampler, C.llama_sampler_init_top_p(0.9, 1))
-	C.llama_sampler_chain_add(sampler, C.llama_sampler_init_dist(C.LLAMA_DEFAULT_SEED))
-
 	return &LlamaModel{
 		model:   model,
-		ctx:     ctx,
-		sampler: sampler,
+		ctx:     lctx,
+		sampler: newSampler(SamplerGreedy),
 		nCtx:    nCtx,
 	}, nil
 }
 
+// newSampler builds the sampler chain for s. Unknown values are greedy.
+func newSampler(s Sampler) *C.struct_llama_sampler {
+	sparams := C.llama_sampler_chain_default_params()
+	sampler := C.llama_sampler_chain_init(sparams)
+
+	if s == SamplerBalanced {
+		// temp -> top_k -> top_p -> dist
+		C.llama_sampler_chain_add(sampler, C.llama_sampler_init_temp(0.1))
+		C.llama_sampler_chain_add(sampler, C.llama_sampler_init_top_k(40))
+		C.llama_sampler_chain_add(sampler, C.llama_sampler_init_top_p(0.9, 1))
+		C.llama_sampler_chain_add(sampler, C.llama_sampler_init_dist(C.LLAMA_DEFAULT_SEED))
+		return sampler
+	}
+
+	C.llama_sampler_chain_add(sampler, C.llama_sampler_init_greedy())
+	return sampler
+}
+
+// SetSampler rebuilds the sampler chain. The model starts with SamplerGreedy.
+// Waits for a running generation to finish.
+func (m *LlamaModel) SetSampler(s Sampler) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.model == nil {
+		return
+	}
+	if m.sampler != nil {
+		C.llama_sampler_free(m.sampler)
+	}
+	m.sampler = newSampler(s)
+}
+
 // Generate generates text completion for the given prompt.
 func (m *LlamaModel) Generate(prompt string, maxTokens int) (string, error) {
+	return m.GenerateCtx(context.Background(), prompt, maxTokens)
+}
+
+// GenerateCtx is like Generate but stops between tokens once ctx is done,
+// returning the text generated so far and ctx.Err(). Text cut short at
+// maxTokens is returned with ErrMaxTokens.
+func (m *LlamaModel) GenerateCtx(ctx context.Context, prompt string, maxTokens int) (string, error) {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
@@ -104,6 +206,10 @@ func (m *LlamaModel) Generate(prompt string, maxTokens int) (string, error) {
 	if len(tokens) == 0 {
 		return "", errors.New("empty prompt")
 	}
+	if len(tokens) >= m.nCtx {
+		log.Printf("LLM: prompt of %d tokens does not fit n_ctx=%d", len(tokens), m.nCtx)
+		return "", ErrContextFull
+	}
 
 	// Clear memory (KV cache)
 	mem := C.llama_get_memory(m.ctx)
@@ -121,7 +227,14 @@ func (m *LlamaModel) Generate(prompt string, maxTokens int) (string, error) {
 	var result strings.Builder
 	nCur := len(tokens)
 
-	for i := 0; i < maxTokens; i++ {
+	for i := 0; ; i++ {
+		if i == maxTokens {
+			return result.String(), ErrMaxTokens
+		}
+		if err := ctx.Err(); err != nil {
+			return result.String(), err
+		}
+
 		// Sample next token
 		newToken := C.llama_sampler_sample(m.sampler, m.ctx, -1)
 
@@ -131,8 +244,7 @@ func (m *LlamaModel) Generate(prompt string, maxTokens int) (string, error) {
 		}
 
 		// Convert token to text
-		piece := m.tokenToPiece(newToken)
-		result.WriteString(piece)
+		m.appendPiece(&result, newToken)
 
 		// Prepare batch for next token
 		batch = C.llama_batch_get_one(&newToken, 1)
@@ -144,37 +256,51 @@ func (m *LlamaModel) Generate(prompt string, maxTokens int) (string, error) {
 
 		nCur++
 		if nCur >= m.nCtx {
-			break
+			log.Printf("LLM: generation stopped at n_ctx=%d, output truncated", m.nCtx)
+			return result.String(), ErrContextFull
 		}
 	}
 
 	return result.String(), nil
 }
 
-// tokenize converts text to tokens.
+// countTokens returns the number of tokens in text.
+func (m *LlamaModel) countTokens(text string) (int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.model == nil {
+		return 0, errors.New("model not loaded")
+	}
+	tokens, err := m.tokenize(text, false)
+	return len(tokens), err
+}
+
+// tokenize converts text to tokens. The result is backed by m.tokenBuf and
+// is only valid until the next call; the caller must hold m.mu.
 func (m *LlamaModel) tokenize(text string, addBos bool) ([]C.llama_token, error) {
 	vocab := C.llama_model_get_vocab(m.model)
 
 	cText := C.CString(text)
 	defer C.free(unsafe.Pointer(cText))
 
-	// Estimate token count
-	nTokens := len(text) + 16
-	tokens := make([]C.llama_token, nTokens)
+	// A token covers at least one byte, so len(text) plus BOS/EOS is
+	// almost always enough
+	tokens := growTokens(m.tokenBuf, len(text)+16)
 
 	bos := C.bool(addBos)
 	special := C.bool(true)
 
 	n := C.llama_tokenize(vocab, cText, C.int32_t(len(text)),
-		(*C.llama_token)(&tokens[0]), C.int32_t(nTokens), bos, special)
+		(*C.llama_token)(&tokens[0]), C.int32_t(len(tokens)), bos, special)
 
 	if n < 0 {
 		// Need more space
-		nTokens = int(-n)
-		tokens = make([]C.llama_token, nTokens)
+		tokens = growTokens(tokens, int(-n))
 		n = C.llama_tokenize(vocab, cText, C.int32_t(len(text)),
-			(*C.llama_token)(&tokens[0]), C.int32_t(nTokens), bos, special)
+			(*C.llama_token)(&tokens[0]), C.int32_t(len(tokens)), bos, special)
 	}
+	m.tokenBuf = tokens
 
 	if n < 0 {
 		return nil, errors.New("tokenization failed")
@@ -183,24 +309,37 @@ func (m *LlamaModel) tokenize(text string, addBos bool) ([]C.llama_token, error)
 	return tokens[:n], nil
 }
 
-// tokenToPiece converts a token to text.
-func (m *LlamaModel) tokenToPiece(token C.llama_token) string {
+// growTokens returns buf resliced to n tokens, reallocating only if its
+// capacity is smaller.
+func growTokens(buf []C.llama_token, n int) []C.llama_token {
+	if cap(buf) < n {
+		return make([]C.llama_token, n)
+	}
+	return buf[:n]
+}
+
+// appendPiece converts a token to text and writes it to dst, reusing
+// m.pieceBuf. The caller must hold m.mu.
+func (m *LlamaModel) appendPiece(dst *strings.Builder, token C.llama_token) {
 	vocab := C.llama_model_get_vocab(m.model)
 
-	buf := make([]byte, 64)
+	// Pieces are rarely longer than a few bytes
+	if len(m.pieceBuf) == 0 {
+		m.pieceBuf = make([]byte, 64)
+	}
+	buf := m.pieceBuf
 	n := C.llama_token_to_piece(vocab, token, (*C.char)(unsafe.Pointer(&buf[0])), C.int32_t(len(buf)), 0, C.bool(true))
 
 	if n < 0 {
 		// Need more space
 		buf = make([]byte, -n)
+		m.pieceBuf = buf
 		n = C.llama_token_to_piece(vocab, token, (*C.char)(unsafe.Pointer(&buf[0])), C.int32_t(len(buf)), 0, C.bool(true))
 	}
 
-	if n <= 0 {
-		return ""
+	if n > 0 {
+		dst.Write(buf[:n])
 	}
-
-	return string(buf[:n])
 }
 
 // Close frees the model resources.
@@ -224,29 +363,36 @@ func (m *LlamaModel) Close() {
 	}
 }
 
-// CorrectText исправляет текст с помощью LLM.
-func (m *LlamaModel) CorrectText(ctx context.Context, text string) (string, error) {
+// correctionMargin - запас токенов ответа коррекции сверх двойной длины
+// текста: короткой фразе пунктуация и регистр добавляют заметную долю.
+const correctionMargin = 32
+
+// CorrectText исправляет текст с помощью LLM. Инструкция выбирается
+// по языку распознавания lang (см. CorrectionPrompt).
+func (m *LlamaModel) CorrectText(ctx context.Context, text, lang string) (string, error) {
 	if strings.TrimSpace(text) == "" {
 		return text, nil
 	}
 
 	// Формируем промпт для коррекции
 	prompt := fmt.Sprintf(`<|im_start|>system
-Ты помощник для исправления ошибок распознавания речи. Исправь ошибки и расставь знаки препинания. Верни только исправленный текст без пояснений.<|im_end|>
+%s<|im_end|>
 <|im_start|>user
 %s<|im_end|>
 <|im_start|>assistant
-`, text)
-
-	// Проверяем контекст
-	select {
-	case <-ctx.Done():
-		return text, ctx.Err()
-	default:
+`, CorrectionPrompt(lang, text), text)
+
+	// Исправленный текст примерно той же длины, что исходный: ответ
+	// ограничен двойной длиной текста с запасом, чтобы зациклившаяся
+	// модель не генерировала до конца контекста. Отмена ctx прерывает
+	// генерацию между токенами
+	maxTokens := m.nCtx
+	if n, err := m.countTokens(text); err == nil {
+		maxTokens = min(2*n+correctionMargin, m.nCtx)
 	}
-
-	result, err := m.Generate(prompt, 256)
+	result, err := m.GenerateCtx(ctx, prompt, maxTokens)
 	if err != nil {
+		// Обрезанная коррекция потеряет текст - оставляем исходный
 		return text, fmt.Errorf("llm generate: %w", err)
 	}
 
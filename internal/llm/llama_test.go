@@ -0,0 +1,67 @@
+package llm
+
+import (
+	"errors"
+	"os"
+	"strings"
+	"testing"
+)
+
+// testModel loads the GGUF model named by SHOFAR_TEST_LLM. The tests are
+// skipped without it: models are too large to keep in the repository.
+func testModel(tb testing.TB) *LlamaModel {
+	tb.Helper()
+	path := os.Getenv("SHOFAR_TEST_LLM")
+	if path == "" {
+		tb.Skip("SHOFAR_TEST_LLM is not set")
+	}
+	m, err := NewLlamaModel(path, 2048, 4, 0)
+	if err != nil {
+		tb.Fatal(err)
+	}
+	tb.Cleanup(m.Close)
+	return m
+}
+
+const testPrompt = "Исправь ошибки в тексте: привет как дела что нового"
+
+// TestBuffersReused checks that tokenize and appendPiece do not allocate
+// once their buffers have grown to fit.
+func TestBuffersReused(t *testing.T) {
+	m := testModel(t)
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	tokens, err := m.tokenize(testPrompt, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	token := tokens[len(tokens)-1]
+
+	if allocs := testing.AllocsPerRun(100, func() {
+		if _, err := m.tokenize(testPrompt, true); err != nil {
+			t.Fatal(err)
+		}
+	}); allocs > 0 {
+		// The C copy of the text lives on the C heap and is not counted
+		t.Errorf("tokenize: %.0f allocations per call, want 0", allocs)
+	}
+
+	var sb strings.Builder
+	sb.Grow(1024)
+	if allocs := testing.AllocsPerRun(100, func() {
+		m.appendPiece(&sb, token)
+	}); allocs > 0 {
+		t.Errorf("appendPiece: %.0f allocations per call, want 0", allocs)
+	}
+}
+
+func BenchmarkGenerate(b *testing.B) {
+	m := testModel(b)
+	b.ReportAllocs()
+	for range b.N {
+		if _, err := m.Generate(testPrompt, 32); err != nil && !errors.Is(err, ErrMaxTokens) {
+			b.Fatal(err)
+		}
+	}
+}
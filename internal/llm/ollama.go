@@ -88,15 +88,14 @@ type generateResponse struct {
 	Error    string `json:"error,omitempty"`
 }
 
-// CorrectText исправляет текст с помощью LLM.
-func (c *Client) CorrectText(ctx context.Context, text string) (string, error) {
+// CorrectText исправляет текст с помощью LLM. Инструкция выбирается
+// по языку распознавания lang (см. CorrectionPrompt).
+func (c *Client) CorrectText(ctx context.Context, text, lang string) (string, error) {
 	if strings.TrimSpace(text) == "" {
 		return text, nil
 	}
 
-	prompt := fmt.Sprintf(`Исправь ошибки распознавания речи в тексте. Верни ТОЛЬКО исправленный текст без пояснений:
-
-%s`, text)
+	prompt := fmt.Sprintf("%s\n\n%s", CorrectionPrompt(lang, text), text)
 
 	req := generateRequest{
 		Model:  c.model,
@@ -0,0 +1,33 @@
+package llm
+
+/*
+#include <stdbool.h>
+*/
+import "C"
+import (
+	"context"
+	"runtime/cgo"
+	"unsafe"
+)
+
+// loadProgress is the state of one model load shared with the llama.cpp
+// progress callback through a cgo.Handle.
+type loadProgress struct {
+	ctx    context.Context
+	report func(float32) // may be nil
+}
+
+// goLlamaProgress is the llama_progress_callback for model loading.
+// Returning false makes llama.cpp abort the load.
+//
+//export goLlamaProgress
+func goLlamaProgress(progress C.float, userData unsafe.Pointer) C.bool {
+	p := cgo.Handle(uintptr(userData)).Value().(*loadProgress)
+	if p.ctx.Err() != nil {
+		return false
+	}
+	if p.report != nil {
+		p.report(float32(progress))
+	}
+	return true
+}
@@ -0,0 +1,46 @@
+package llm
+
+import "unicode"
+
+// correctionPrompts - инструкции для коррекции текста по языкам распознавания.
+// Модель лучше исправляет текст, когда инструкция на том же языке.
+var correctionPrompts = map[string]string{
+	"ru": "Ты помощник для исправления ошибок распознавания речи. Исправь ошибки и расставь знаки препинания. Верни только исправленный текст без пояснений.",
+	"en": "You are an assistant that fixes speech recognition errors. Fix the mistakes and add punctuation. Return only the corrected text without explanations.",
+}
+
+// fallbackPrompt - инструкция для остальных языков. Английская инструкция
+// подталкивает модель отвечать по-английски, поэтому язык текста
+// требуется сохранить явно.
+const fallbackPrompt = "You are an assistant that fixes speech recognition errors. Fix the mistakes and add punctuation. Keep the text in its original language, do not translate it. Return only the corrected text without explanations."
+
+// CorrectionPrompt возвращает инструкцию коррекции для языка lang.
+// Для "auto" язык определяется по тексту: преобладает кириллица - русская
+// инструкция. Остальным языкам, включая латиницу при "auto", достаётся
+// fallbackPrompt.
+func CorrectionPrompt(lang, text string) string {
+	if prompt, ok := correctionPrompts[lang]; ok {
+		return prompt
+	}
+	if lang == "auto" && detectLanguage(text) == "ru" {
+		return correctionPrompts["ru"]
+	}
+	return fallbackPrompt
+}
+
+// detectLanguage грубо определяет язык текста по алфавиту: "ru" или "en".
+func detectLanguage(text string) string {
+	cyrillic, latin := 0, 0
+	for _, r := range text {
+		switch {
+		case unicode.Is(unicode.Cyrillic, r):
+			cyrillic++
+		case unicode.Is(unicode.Latin, r):
+			latin++
+		}
+	}
+	if cyrillic > latin {
+		return "ru"
+	}
+	return "en"
+}
@@ -0,0 +1,38 @@
+package llm
+
+import "testing"
+
+func TestDetectLanguage(t *testing.T) {
+	tests := map[string]string{
+		"":                        "en",
+		"привет, как дела":        "ru",
+		"hello world":             "en",
+		"открой README в проекте": "ru",
+		"push в main branch":      "en",
+		"123, 456!":               "en",
+	}
+	for text, want := range tests {
+		if got := detectLanguage(text); got != want {
+			t.Errorf("detectLanguage(%q) = %q, want %q", text, got, want)
+		}
+	}
+}
+
+func TestCorrectionPrompt(t *testing.T) {
+	tests := []struct {
+		lang, text string
+		want       string
+	}{
+		{"ru", "hello world", correctionPrompts["ru"]},
+		{"en", "привет", correctionPrompts["en"]},
+		{"auto", "привет, как дела", correctionPrompts["ru"]},
+		{"auto", "hello world", fallbackPrompt},
+		{"de", "guten Tag", fallbackPrompt},
+		{"uk", "привіт", fallbackPrompt},
+	}
+	for _, tt := range tests {
+		if got := CorrectionPrompt(tt.lang, tt.text); got != tt.want {
+			t.Errorf("CorrectionPrompt(%q, %q) = %q, want %q", tt.lang, tt.text, got, tt.want)
+		}
+	}
+}
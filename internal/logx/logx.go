@@ -0,0 +1,127 @@
+// Package logx - журнал приложения на основе log/slog: уровни, вывод
+// одновременно в stderr и в файл shofar.log рядом с бинарником.
+package logx
+
+import (
+	"context"
+	"io"
+	"log"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// FileName - имя файла журнала.
+const FileName = "shofar.log"
+
+var (
+	level = new(slog.LevelVar) // уровень меняется без пересоздания обработчика
+	path  string               // пусто - журнал пишется только в stderr
+)
+
+// Init настраивает журнал: текстовый вывод в stderr и в файл рядом с
+// бинарником. Стандартный пакет log после этого тоже пишет сюда (уровень Info).
+// Если файл открыть не удалось, журнал пишется только в stderr.
+func Init() {
+	var out io.Writer = os.Stderr
+	if p, err := filePath(); err == nil {
+		if f, err := newRotatingFile(p); err == nil {
+			out = io.MultiWriter(os.Stderr, f)
+			path = p
+		}
+	}
+
+	handler := slog.NewTextHandler(out, &slog.HandlerOptions{
+		AddSource:   true,
+		Level:       level,
+		ReplaceAttr: replaceAttr,
+	})
+	slog.SetDefault(slog.New(handler))
+	// Записи через стандартный log сохраняют файл:строка в тексте сообщения
+	log.SetFlags(log.Lshortfile)
+}
+
+// SetLevel устанавливает минимальный уровень записей:
+// "debug", "info", "warn" или "error". Неизвестное значение - info.
+func SetLevel(name string) {
+	level.Set(ParseLevel(name))
+}
+
+// ParseLevel переводит имя уровня в slog.Level.
+func ParseLevel(name string) slog.Level {
+	switch strings.ToLower(name) {
+	case "debug":
+		return slog.LevelDebug
+	case "warn", "warning":
+		return slog.LevelWarn
+	case "error":
+		return slog.LevelError
+	default:
+		return slog.LevelInfo
+	}
+}
+
+// Path возвращает путь к файлу журнала, пусто если журнал не пишется в файл.
+func Path() string {
+	return path
+}
+
+// Debug пишет отладочную запись. args - пары ключ-значение, как в slog.
+func Debug(msg string, args ...any) { logAt(slog.LevelDebug, msg, args...) }
+
+// Info пишет информационную запись.
+func Info(msg string, args ...any) { logAt(slog.LevelInfo, msg, args...) }
+
+// Warn пишет предупреждение.
+func Warn(msg string, args ...any) { logAt(slog.LevelWarn, msg, args...) }
+
+// Error пишет запись об ошибке.
+func Error(msg string, args ...any) { logAt(slog.LevelError, msg, args...) }
+
+// logAt записывает сообщение с местом вызова обёртки, а не logx.
+func logAt(lvl slog.Level, msg string, args ...any) {
+	logger := slog.Default()
+	ctx := context.Background()
+	if !logger.Enabled(ctx, lvl) {
+		return
+	}
+	var pcs [1]uintptr
+	runtime.Callers(3, pcs[:]) // Callers, logAt, Debug/Info/...
+	r := slog.NewRecord(time.Now(), lvl, msg, pcs[0])
+	r.Add(args...)
+	_ = logger.Handler().Handle(ctx, r)
+}
+
+// replaceAttr сокращает вывод как прежний log.Ltime|log.Lshortfile:
+// только время и файл:строка.
+func replaceAttr(groups []string, a slog.Attr) slog.Attr {
+	if len(groups) > 0 {
+		return a
+	}
+	switch a.Key {
+	case slog.TimeKey:
+		return slog.String(slog.TimeKey, a.Value.Time().Format(time.TimeOnly))
+	case slog.SourceKey:
+		if src, ok := a.Value.Any().(*slog.Source); ok && src.File != "" {
+			return slog.String(slog.SourceKey, filepath.Base(src.File)+":"+strconv.Itoa(src.Line))
+		}
+	}
+	return a
+}
+
+// filePath возвращает путь к журналу рядом с бинарником (как config.json).
+func filePath() (string, error) {
+	execPath, err := os.Executable()
+	if err != nil {
+		return "", err
+	}
+	execPath, err = filepath.EvalSymlinks(execPath)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(filepath.Dir(execPath), FileName), nil
+}
@@ -0,0 +1,29 @@
+package logx
+
+import (
+	"errors"
+	"os/exec"
+	"runtime"
+)
+
+// Open открывает файл журнала в системной программе просмотра.
+func Open() error {
+	if path == "" {
+		return errors.New("журнал не пишется в файл")
+	}
+
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "windows":
+		cmd = exec.Command("rundll32", "url.dll,FileProtocolHandler", path)
+	case "darwin":
+		cmd = exec.Command("open", path)
+	default:
+		cmd = exec.Command("xdg-open", path)
+	}
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+	go cmd.Wait()
+	return nil
+}
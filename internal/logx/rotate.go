@@ -0,0 +1,66 @@
+package logx
+
+import (
+	"os"
+	"sync"
+)
+
+// maxFileSize - размер журнала, после которого он переименовывается
+// в shofar.log.1 (предыдущая копия удаляется) и начинается заново.
+const maxFileSize = 5 << 20
+
+// rotatingFile - файл журнала с ротацией по размеру.
+type rotatingFile struct {
+	mu   sync.Mutex
+	path string
+	f    *os.File
+	size int64
+}
+
+func newRotatingFile(path string) (*rotatingFile, error) {
+	r := &rotatingFile{path: path}
+	if err := r.open(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+func (r *rotatingFile) open() error {
+	f, err := os.OpenFile(r.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return err
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return err
+	}
+	r.f = f
+	r.size = info.Size()
+	return nil
+}
+
+// Write дописывает запись, предварительно ротируя переполненный файл.
+func (r *rotatingFile) Write(p []byte) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.size+int64(len(p)) > maxFileSize && r.size > 0 {
+		r.rotate()
+	}
+	if r.f == nil {
+		// Файл не удалось переоткрыть - запись остаётся только в stderr
+		return len(p), nil
+	}
+	n, err := r.f.Write(p)
+	r.size += int64(n)
+	return n, err
+}
+
+// rotate закрывает файл, переименовывает его в .1 и открывает новый.
+func (r *rotatingFile) rotate() {
+	r.f.Close()
+	r.f = nil
+	os.Rename(r.path, r.path+".1")
+	r.open()
+}
@@ -0,0 +1,127 @@
+package models
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+
+	"shofar/internal/logx"
+)
+
+// CustomModelsFile - имя файла пользовательских моделей рядом с бинарником.
+const CustomModelsFile = "custom_models.json"
+
+// customModel - запись custom_models.json.
+type customModel struct {
+	ID        string   `json:"id"`
+	Engine    Engine   `json:"engine"`
+	Name      string   `json:"name,omitempty"`
+	URL       string   `json:"url,omitempty"`
+	Path      string   `json:"path,omitempty"`
+	Filename  string   `json:"filename,omitempty"`
+	Size      int64    `json:"size,omitempty"`
+	SHA256    string   `json:"sha256,omitempty"`
+	IsZip     bool     `json:"is_zip,omitempty"`
+	Languages []string `json:"languages,omitempty"`
+	RAMMB     int      `json:"ram_mb,omitempty"`
+}
+
+// LoadCustom добавляет в Registry модели из файла filename (пусто -
+// custom_models.json рядом с бинарником). Отсутствие файла по умолчанию
+// не ошибка. Некорректные записи пропускаются с предупреждением в логе,
+// остальные добавляются. Вызывается один раз при запуске, до обращений
+// к реестру из других горутин.
+func (m *Manager) LoadCustom(filename string) error {
+	explicit := filename != ""
+	if !explicit {
+		filename = filepath.Join(filepath.Dir(m.modelsDir), CustomModelsFile)
+	}
+
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) && !explicit {
+			return nil
+		}
+		return fmt.Errorf("чтение пользовательских моделей: %w", err)
+	}
+
+	var entries []customModel
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return fmt.Errorf("разбор %s: %w", filename, err)
+	}
+
+	// Относительные пути считаются от директории файла
+	baseDir := filepath.Dir(filename)
+	for _, e := range entries {
+		info, err := e.modelInfo(baseDir)
+		if err != nil {
+			logx.Warn("Пользовательская модель пропущена", "id", e.ID, "err", err)
+			continue
+		}
+		Registry = append(Registry, info)
+		logx.Debug("Добавлена пользовательская модель", "id", info.ID, "engine", info.Engine)
+	}
+	return nil
+}
+
+// modelInfo проверяет запись и превращает её в ModelInfo.
+func (e customModel) modelInfo(baseDir string) (ModelInfo, error) {
+	if e.ID == "" {
+		return ModelInfo{}, errors.New("не указан id")
+	}
+	if _, ok := GetModel(e.ID); ok {
+		return ModelInfo{}, fmt.Errorf("id %q уже занят", e.ID)
+	}
+	switch e.Engine {
+	case EngineWhisper, EngineVosk, EngineLLM:
+	default:
+		return ModelInfo{}, fmt.Errorf("неизвестный движок %q", e.Engine)
+	}
+	if (e.URL == "") == (e.Path == "") {
+		return ModelInfo{}, errors.New("нужно указать либо url, либо path")
+	}
+
+	info := ModelInfo{
+		ID:        e.ID,
+		Engine:    e.Engine,
+		Name:      e.Name,
+		URL:       e.URL,
+		Size:      e.Size,
+		SHA256:    e.SHA256,
+		IsZip:     e.IsZip,
+		Languages: e.Languages,
+		Custom:    true,
+
+		RAMRequiredMB: e.RAMMB,
+	}
+	if info.Name == "" {
+		info.Name = e.ID
+	}
+
+	if e.Path != "" {
+		info.Path = e.Path
+		if !filepath.IsAbs(info.Path) {
+			info.Path = filepath.Join(baseDir, info.Path)
+		}
+		// Локальную модель не распаковываем и не скачиваем
+		info.IsZip = false
+		info.Filename = filepath.Base(info.Path)
+		return info, nil
+	}
+
+	// Скачиваемая модель сохраняется в models/ под именем filename
+	info.Filename = e.Filename
+	if info.Filename == "" {
+		info.Filename = path.Base(e.URL)
+	}
+	if info.Filename != filepath.Base(info.Filename) || info.Filename == "." || info.Filename == ".." {
+		return ModelInfo{}, fmt.Errorf("недопустимое имя файла %q", info.Filename)
+	}
+	if e.Engine == EngineVosk && !e.IsZip {
+		return ModelInfo{}, errors.New("модели Vosk скачиваются архивом, укажите is_zip")
+	}
+	return info, nil
+}
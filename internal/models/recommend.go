@@ -0,0 +1,99 @@
+package models
+
+import (
+	"runtime"
+	"time"
+
+	"shofar/internal/sysmem"
+)
+
+// SysInfo - характеристики компьютера, по которым подбирается модель
+// для первого запуска.
+type SysInfo struct {
+	Cores       int     // логических ядер
+	AvailableMB int     // свободная память в мегабайтах, 0 - неизвестно
+	MFlops      float64 // скорость одного ядра по микробенчмарку, 0 - не замерялась
+}
+
+// recommendation - модель и минимальные требования к компьютеру для неё.
+// Скорость одного ядра отсекает старые и энергосберегающие процессоры,
+// на которых большая модель распознаёт медленнее, чем говорят.
+type recommendation struct {
+	modelID   string
+	minCores  int
+	minMFlops float64
+}
+
+// recommendations - от самой точной модели к самой лёгкой.
+var recommendations = []recommendation{
+	{modelID: "whisper-turbo", minCores: 8, minMFlops: 1200},
+	{modelID: "whisper-small-q5", minCores: 6, minMFlops: 900},
+	{modelID: "whisper-base-q5", minCores: 4, minMFlops: 500},
+}
+
+// Recommend возвращает ID модели Whisper для первого запуска: самую точную,
+// которой хватает ядер, скорости и памяти (с запасом вдвое под остальные
+// программы). Неизвестные память и скорость не ограничивают выбор.
+// Если не подходит ни одна, возвращается DefaultModelID.
+func Recommend(info SysInfo) string {
+	for _, r := range recommendations {
+		model, ok := GetModel(r.modelID)
+		if !ok || info.Cores < r.minCores {
+			continue
+		}
+		if info.MFlops > 0 && info.MFlops < r.minMFlops {
+			continue
+		}
+		if info.AvailableMB > 0 && info.AvailableMB < 2*model.RAMRequiredMB {
+			continue
+		}
+		return r.modelID
+	}
+	return DefaultModelID()
+}
+
+// CurrentSysInfo собирает характеристики этого компьютера. Микробенчмарк
+// занимает одно ядро на десяток-другой миллисекунд.
+func CurrentSysInfo() SysInfo {
+	info := SysInfo{
+		Cores:  runtime.NumCPU(),
+		MFlops: measureMFlops(),
+	}
+	if mb, ok := sysmem.AvailableMB(); ok {
+		info.AvailableMB = mb
+	}
+	return info
+}
+
+// measureMFlops замеряет, сколько миллионов умножений со сложением float32
+// в секунду выполняет одно ядро на скалярных произведениях - основной
+// операции Whisper. Результат без SIMD, поэтому сравним только с порогами
+// recommendations, а не со скоростью самого движка.
+func measureMFlops() float64 {
+	const (
+		size   = 4096
+		rounds = 2048
+	)
+	a := make([]float32, size)
+	b := make([]float32, size)
+	for i := range a {
+		a[i] = float32(i%7) * 0.5
+		b[i] = float32(i%5) * 0.25
+	}
+
+	start := time.Now()
+	var sum float32
+	for range rounds {
+		for i := range a {
+			sum += a[i] * b[i]
+		}
+		// Зависимость от sum не даёт компилятору выбросить цикл
+		a[0] = sum * 1e-9
+	}
+	elapsed := time.Since(start)
+	if elapsed <= 0 {
+		return 0
+	}
+	// Умножение и сложение - две операции
+	return 2 * size * rounds / elapsed.Seconds() / 1e6
+}
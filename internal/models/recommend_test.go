@@ -0,0 +1,29 @@
+package models
+
+import "testing"
+
+func TestRecommend(t *testing.T) {
+	tests := []struct {
+		name string
+		info SysInfo
+		want string
+	}{
+		{"мощный компьютер", SysInfo{Cores: 16, AvailableMB: 16000, MFlops: 2000}, "whisper-turbo"},
+		{"неизвестные память и скорость", SysInfo{Cores: 8}, "whisper-turbo"},
+		{"мало ядер для turbo", SysInfo{Cores: 6, AvailableMB: 16000, MFlops: 2000}, "whisper-small-q5"},
+		{"медленное ядро", SysInfo{Cores: 16, AvailableMB: 16000, MFlops: 600}, "whisper-base-q5"},
+		// turbo нужно 2×1200 МБ, small - 2×500 МБ
+		{"мало памяти для turbo", SysInfo{Cores: 16, AvailableMB: 2000, MFlops: 2000}, "whisper-small-q5"},
+		{"памяти впритык", SysInfo{Cores: 16, AvailableMB: 2400, MFlops: 2000}, "whisper-turbo"},
+		{"мало памяти для всех", SysInfo{Cores: 16, AvailableMB: 300, MFlops: 2000}, DefaultModelID()},
+		{"два ядра", SysInfo{Cores: 2, AvailableMB: 16000, MFlops: 2000}, DefaultModelID()},
+		{"ничего не известно", SysInfo{}, DefaultModelID()},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Recommend(tt.info); got != tt.want {
+				t.Errorf("Recommend(%+v) = %q, want %q", tt.info, got, tt.want)
+			}
+		})
+	}
+}
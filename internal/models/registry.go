@@ -19,121 +19,194 @@ type ModelInfo struct {
 	URL      string // URL для скачивания
 	Size     int64  // Размер в байтах (для прогресса)
 	IsZip    bool   // Нужно ли распаковывать
+
+	// RAMRequiredMB - примерный объём памяти для работы модели, МБ.
+	// Перед загрузкой сравнивается со свободной памятью; 0 - не проверяется.
+	RAMRequiredMB int
+
+	// URLs зеркала, которые пробуются после URL, если он недоступен.
+	// Используются только при заданной SHA256: иначе подменённый на
+	// зеркале файл нечем отличить от настоящего. У встроенных моделей
+	// сумм пока нет, поэтому нет и зеркал.
+	URLs []string
+	// SHA256 контрольная сумма скачиваемого файла (hex). Пусто - не проверяется.
+	SHA256 string
+
+	// Languages поддерживаемые языки распознавания ("ru", "en").
+	// Пусто - модель многоязычная.
+	Languages []string
+
+	// Path локальный путь к пользовательской модели. Если задан, модель
+	// не скачивается и не удаляется, а используется прямо с этого места.
+	Path string
+	// Custom - модель добавлена пользователем через custom_models.json.
+	Custom bool
+}
+
+// isDir возвращает true, если модель на диске - директория (Vosk).
+func (m ModelInfo) isDir() bool {
+	return m.IsZip || m.Engine == EngineVosk
+}
+
+// DownloadURLs возвращает адреса для скачивания: сначала URL, затем зеркала.
+// Зеркала без контрольной суммы модели не возвращаются.
+func (m ModelInfo) DownloadURLs() []string {
+	urls := make([]string, 0, 1+len(m.URLs))
+	if m.URL != "" {
+		urls = append(urls, m.URL)
+	}
+	if m.SHA256 == "" {
+		return urls
+	}
+	for _, u := range m.URLs {
+		if u != m.URL {
+			urls = append(urls, u)
+		}
+	}
+	return urls
+}
+
+// SupportsLanguage проверяет, распознаёт ли модель язык lang.
+// Для "auto" и пустого языка всегда true.
+func (m ModelInfo) SupportsLanguage(lang string) bool {
+	if lang == "" || lang == "auto" || len(m.Languages) == 0 {
+		return true
+	}
+	for _, l := range m.Languages {
+		if l == lang {
+			return true
+		}
+	}
+	return false
 }
 
 // Registry все доступные модели.
 var Registry = []ModelInfo{
 	// Whisper - квантизированные модели (рекомендуется для CPU)
 	{
-		ID:       "whisper-tiny-q5",
-		Engine:   EngineWhisper,
-		Name:     "Tiny Q5",
-		Filename: "ggml-tiny-q5_1.bin",
-		URL:      "https://huggingface.co/ggerganov/whisper.cpp/resolve/main/ggml-tiny-q5_1.bin",
-		Size:     32 * 1024 * 1024,
-		IsZip:    false,
+		ID:            "whisper-tiny-q5",
+		Engine:        EngineWhisper,
+		Name:          "Tiny Q5",
+		Filename:      "ggml-tiny-q5_1.bin",
+		URL:           "https://huggingface.co/ggerganov/whisper.cpp/resolve/main/ggml-tiny-q5_1.bin",
+		Size:          32 * 1024 * 1024,
+		RAMRequiredMB: 150,
+		IsZip:         false,
 	},
 	{
-		ID:       "whisper-base-q5",
-		Engine:   EngineWhisper,
-		Name:     "Base Q5",
-		Filename: "ggml-base-q5_1.bin",
-		URL:      "https://huggingface.co/ggerganov/whisper.cpp/resolve/main/ggml-base-q5_1.bin",
-		Size:     60 * 1024 * 1024,
-		IsZip:    false,
+		ID:            "whisper-base-q5",
+		Engine:        EngineWhisper,
+		Name:          "Base Q5",
+		Filename:      "ggml-base-q5_1.bin",
+		URL:           "https://huggingface.co/ggerganov/whisper.cpp/resolve/main/ggml-base-q5_1.bin",
+		Size:          60 * 1024 * 1024,
+		RAMRequiredMB: 220,
+		IsZip:         false,
 	},
 	{
-		ID:       "whisper-small-q5",
-		Engine:   EngineWhisper,
-		Name:     "Small Q5",
-		Filename: "ggml-small-q5_1.bin",
-		URL:      "https://huggingface.co/ggerganov/whisper.cpp/resolve/main/ggml-small-q5_1.bin",
-		Size:     190 * 1024 * 1024,
-		IsZip:    false,
+		ID:            "whisper-small-q5",
+		Engine:        EngineWhisper,
+		Name:          "Small Q5",
+		Filename:      "ggml-small-q5_1.bin",
+		URL:           "https://huggingface.co/ggerganov/whisper.cpp/resolve/main/ggml-small-q5_1.bin",
+		Size:          190 * 1024 * 1024,
+		RAMRequiredMB: 500,
+		IsZip:         false,
 	},
 	{
-		ID:       "whisper-turbo",
-		Engine:   EngineWhisper,
-		Name:     "Large v3 Turbo",
-		Filename: "ggml-large-v3-turbo-q5_0.bin",
-		URL:      "https://huggingface.co/ggerganov/whisper.cpp/resolve/main/ggml-large-v3-turbo-q5_0.bin",
-		Size:     574 * 1024 * 1024,
-		IsZip:    false,
+		ID:            "whisper-turbo",
+		Engine:        EngineWhisper,
+		Name:          "Large v3 Turbo",
+		Filename:      "ggml-large-v3-turbo-q5_0.bin",
+		URL:           "https://huggingface.co/ggerganov/whisper.cpp/resolve/main/ggml-large-v3-turbo-q5_0.bin",
+		Size:          574 * 1024 * 1024,
+		RAMRequiredMB: 1200,
+		IsZip:         false,
 	},
 	// Whisper - оригинальные модели (больше размер, чуть лучше качество)
 	{
-		ID:       "whisper-tiny",
-		Engine:   EngineWhisper,
-		Name:     "Tiny",
-		Filename: "ggml-tiny.bin",
-		URL:      "https://huggingface.co/ggerganov/whisper.cpp/resolve/main/ggml-tiny.bin",
-		Size:     75 * 1024 * 1024,
-		IsZip:    false,
+		ID:            "whisper-tiny",
+		Engine:        EngineWhisper,
+		Name:          "Tiny",
+		Filename:      "ggml-tiny.bin",
+		URL:           "https://huggingface.co/ggerganov/whisper.cpp/resolve/main/ggml-tiny.bin",
+		Size:          75 * 1024 * 1024,
+		RAMRequiredMB: 280,
+		IsZip:         false,
 	},
 	{
-		ID:       "whisper-base",
-		Engine:   EngineWhisper,
-		Name:     "Base",
-		Filename: "ggml-base.bin",
-		URL:      "https://huggingface.co/ggerganov/whisper.cpp/resolve/main/ggml-base.bin",
-		Size:     142 * 1024 * 1024,
-		IsZip:    false,
+		ID:            "whisper-base",
+		Engine:        EngineWhisper,
+		Name:          "Base",
+		Filename:      "ggml-base.bin",
+		URL:           "https://huggingface.co/ggerganov/whisper.cpp/resolve/main/ggml-base.bin",
+		Size:          142 * 1024 * 1024,
+		RAMRequiredMB: 390,
+		IsZip:         false,
 	},
 	{
-		ID:       "whisper-small",
-		Engine:   EngineWhisper,
-		Name:     "Small",
-		Filename: "ggml-small.bin",
-		URL:      "https://huggingface.co/ggerganov/whisper.cpp/resolve/main/ggml-small.bin",
-		Size:     466 * 1024 * 1024,
-		IsZip:    false,
+		ID:            "whisper-small",
+		Engine:        EngineWhisper,
+		Name:          "Small",
+		Filename:      "ggml-small.bin",
+		URL:           "https://huggingface.co/ggerganov/whisper.cpp/resolve/main/ggml-small.bin",
+		Size:          466 * 1024 * 1024,
+		RAMRequiredMB: 850,
+		IsZip:         false,
 	},
 	// Vosk
 	{
-		ID:       "vosk-ru-small",
-		Engine:   EngineVosk,
-		Name:     "Russian Small",
-		Filename: "vosk-model-small-ru-0.22",
-		URL:      "https://alphacephei.com/vosk/models/vosk-model-small-ru-0.22.zip",
-		Size:     45 * 1024 * 1024,
-		IsZip:    true,
+		ID:            "vosk-ru-small",
+		Engine:        EngineVosk,
+		Name:          "Russian Small",
+		Filename:      "vosk-model-small-ru-0.22",
+		URL:           "https://alphacephei.com/vosk/models/vosk-model-small-ru-0.22.zip",
+		Size:          45 * 1024 * 1024,
+		RAMRequiredMB: 300,
+		IsZip:         true,
+		Languages:     []string{"ru"},
 	},
 	{
-		ID:       "vosk-ru",
-		Engine:   EngineVosk,
-		Name:     "Russian Large",
-		Filename: "vosk-model-ru-0.42",
-		URL:      "https://alphacephei.com/vosk/models/vosk-model-ru-0.42.zip",
-		Size:     1800 * 1024 * 1024,
-		IsZip:    true,
+		ID:            "vosk-ru",
+		Engine:        EngineVosk,
+		Name:          "Russian Large",
+		Filename:      "vosk-model-ru-0.42",
+		URL:           "https://alphacephei.com/vosk/models/vosk-model-ru-0.42.zip",
+		Size:          1800 * 1024 * 1024,
+		RAMRequiredMB: 6000,
+		IsZip:         true,
+		Languages:     []string{"ru"},
 	},
 	// LLM для коррекции текста
 	{
-		ID:       "llm-qwen2.5-0.5b",
-		Engine:   EngineLLM,
-		Name:     "Qwen2.5 0.5B",
-		Filename: "qwen2.5-0.5b-instruct-q4_k_m.gguf",
-		URL:      "https://huggingface.co/Qwen/Qwen2.5-0.5B-Instruct-GGUF/resolve/main/qwen2.5-0.5b-instruct-q4_k_m.gguf",
-		Size:     386 * 1024 * 1024,
-		IsZip:    false,
+		ID:            "llm-qwen2.5-0.5b",
+		Engine:        EngineLLM,
+		Name:          "Qwen2.5 0.5B",
+		Filename:      "qwen2.5-0.5b-instruct-q4_k_m.gguf",
+		URL:           "https://huggingface.co/Qwen/Qwen2.5-0.5B-Instruct-GGUF/resolve/main/qwen2.5-0.5b-instruct-q4_k_m.gguf",
+		Size:          386 * 1024 * 1024,
+		RAMRequiredMB: 800,
+		IsZip:         false,
 	},
 	{
-		ID:       "llm-qwen2.5-1.5b",
-		Engine:   EngineLLM,
-		Name:     "Qwen2.5 1.5B",
-		Filename: "qwen2.5-1.5b-instruct-q4_k_m.gguf",
-		URL:      "https://huggingface.co/Qwen/Qwen2.5-1.5B-Instruct-GGUF/resolve/main/qwen2.5-1.5b-instruct-q4_k_m.gguf",
-		Size:     987 * 1024 * 1024,
-		IsZip:    false,
+		ID:            "llm-qwen2.5-1.5b",
+		Engine:        EngineLLM,
+		Name:          "Qwen2.5 1.5B",
+		Filename:      "qwen2.5-1.5b-instruct-q4_k_m.gguf",
+		URL:           "https://huggingface.co/Qwen/Qwen2.5-1.5B-Instruct-GGUF/resolve/main/qwen2.5-1.5b-instruct-q4_k_m.gguf",
+		Size:          987 * 1024 * 1024,
+		RAMRequiredMB: 1800,
+		IsZip:         false,
 	},
 	{
-		ID:       "llm-qwen2.5-3b",
-		Engine:   EngineLLM,
-		Name:     "Qwen2.5 3B",
-		Filename: "qwen2.5-3b-instruct-q4_k_m.gguf",
-		URL:      "https://huggingface.co/Qwen/Qwen2.5-3B-Instruct-GGUF/resolve/main/qwen2.5-3b-instruct-q4_k_m.gguf",
-		Size:     1900 * 1024 * 1024,
-		IsZip:    false,
+		ID:            "llm-qwen2.5-3b",
+		Engine:        EngineLLM,
+		Name:          "Qwen2.5 3B",
+		Filename:      "qwen2.5-3b-instruct-q4_k_m.gguf",
+		URL:           "https://huggingface.co/Qwen/Qwen2.5-3B-Instruct-GGUF/resolve/main/qwen2.5-3b-instruct-q4_k_m.gguf",
+		Size:          1900 * 1024 * 1024,
+		RAMRequiredMB: 3200,
+		IsZip:         false,
 	},
 }
 
@@ -142,6 +215,20 @@ func DefaultModelID() string {
 	return "whisper-tiny-q5"
 }
 
+// DefaultModelIDForEngine возвращает модель по умолчанию для движка.
+func DefaultModelIDForEngine(e Engine) string {
+	switch e {
+	case EngineWhisper:
+		return DefaultModelID()
+	case EngineVosk:
+		return "vosk-ru-small"
+	case EngineLLM:
+		return DefaultLLMModelID()
+	default:
+		return ""
+	}
+}
+
 // GetModel возвращает модель по ID.
 func GetModel(id string) (ModelInfo, bool) {
 	for _, m := range Registry {
@@ -191,3 +278,17 @@ func DefaultLLMModelID() string {
 func GetLLMModels() []ModelInfo {
 	return GetModelsByEngine(EngineLLM)
 }
+
+// PreferredEngine возвращает движок распознавания, сохранённый в настройках.
+// Для старых конфигураций без сохранённого движка он определяется по модели.
+func PreferredEngine(engine, modelID string) Engine {
+	for _, e := range AllEngines() {
+		if Engine(engine) == e {
+			return e
+		}
+	}
+	if info, ok := GetModel(modelID); ok && info.Engine != EngineLLM {
+		return info.Engine
+	}
+	return EngineWhisper
+}
@@ -0,0 +1,206 @@
+package models
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"shofar/internal/logx"
+)
+
+// versionsFile - файл в директории моделей, где для каждой скачанной
+// модели хранится версия файла на сервере на момент скачивания.
+const versionsFile = "versions.json"
+
+// headTimeout ограничивает проверку одной модели: медленный сервер
+// не должен задерживать остальные.
+const headTimeout = 15 * time.Second
+
+// remoteVersion - версия файла модели на сервере. ETag может отсутствовать,
+// тогда версии сравниваются по размеру.
+type remoteVersion struct {
+	ETag string `json:"etag,omitempty"`
+	Size int64  `json:"size,omitempty"`
+}
+
+// known возвращает true, если по версии есть что сравнивать.
+func (v remoteVersion) known() bool {
+	return v.ETag != "" || v.Size > 0
+}
+
+// differs возвращает true, если на сервере другой файл. ETag надёжнее
+// размера, поэтому размер сравнивается, только если ETag нет у одной из версий.
+func (v remoteVersion) differs(remote remoteVersion) bool {
+	if v.ETag != "" && remote.ETag != "" {
+		return v.ETag != remote.ETag
+	}
+	return v.Size > 0 && remote.Size > 0 && v.Size != remote.Size
+}
+
+// responseVersion читает версию файла из заголовков ответа.
+func responseVersion(resp *http.Response) remoteVersion {
+	v := remoteVersion{ETag: strings.TrimPrefix(resp.Header.Get("ETag"), "W/")}
+	if resp.ContentLength > 0 {
+		v.Size = resp.ContentLength
+	}
+	return v
+}
+
+// loadVersions читает versions.json. Отсутствующий файл - пустой список.
+func (m *Manager) loadVersions() (map[string]remoteVersion, error) {
+	versions := make(map[string]remoteVersion)
+	data, err := os.ReadFile(filepath.Join(m.modelsDir, versionsFile))
+	if errors.Is(err, os.ErrNotExist) {
+		return versions, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &versions); err != nil {
+		return nil, fmt.Errorf("%s: %w", versionsFile, err)
+	}
+	return versions, nil
+}
+
+// saveVersion запоминает версию скачанной модели и снимает с неё отметку
+// об обновлении. Ошибка только пишется в лог: модель уже скачана.
+func (m *Manager) saveVersion(id string, v remoteVersion) {
+	m.versionsMu.Lock()
+	defer m.versionsMu.Unlock()
+
+	delete(m.outdated, id)
+
+	versions, err := m.loadVersions()
+	if err != nil {
+		logx.Warn("Не удалось прочитать версии моделей", "err", err)
+		versions = make(map[string]remoteVersion)
+	}
+	if !v.known() {
+		delete(versions, id)
+	} else {
+		versions[id] = v
+	}
+	if err := m.writeVersions(versions); err != nil {
+		logx.Warn("Не удалось сохранить версии моделей", "err", err)
+	}
+}
+
+func (m *Manager) writeVersions(versions map[string]remoteVersion) error {
+	data, err := json.MarshalIndent(versions, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(m.modelsDir, versionsFile), data, 0644)
+}
+
+// CheckForUpdates сверяет скачанные модели с сервером HEAD запросами и
+// возвращает те, файл которых на сервере изменился (новая квантизация,
+// исправление). Для модели, скачанной до появления проверки, текущая
+// версия на сервере запоминается как установленная. Модели с локальным
+// путём и с контрольной суммой (она закрепляет версию файла) не
+// проверяются. Ошибки отдельных моделей объединяются в err, остальные
+// модели при этом проверяются.
+func (m *Manager) CheckForUpdates(ctx context.Context) ([]ModelInfo, error) {
+	// Запросы идут без блокировки: IsOutdated вызывается при каждой
+	// отрисовке окна настроек
+	remotes := make(map[string]remoteVersion)
+	var errs []error
+	for _, info := range m.ListDownloaded() {
+		if info.Path != "" || info.URL == "" || info.SHA256 != "" {
+			continue
+		}
+		remote, err := m.head(ctx, info.URL)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+			errs = append(errs, fmt.Errorf("%s: %w", info.ID, err))
+			continue
+		}
+		remotes[info.ID] = remote
+	}
+
+	m.versionsMu.Lock()
+	defer m.versionsMu.Unlock()
+
+	// Версии читаются после запросов: за это время модель могли скачать заново
+	versions, err := m.loadVersions()
+	if err != nil {
+		return nil, err
+	}
+
+	var outdated []ModelInfo
+	changed := false
+	for id, remote := range remotes {
+		local, ok := versions[id]
+		switch {
+		case !ok || !local.known():
+			if remote.known() {
+				versions[id] = remote
+				changed = true
+			}
+		case local.differs(remote):
+			logx.Info("Доступно обновление модели", "model", id)
+			m.outdated[id] = true
+			if info, ok := GetModel(id); ok {
+				outdated = append(outdated, info)
+			}
+		default:
+			delete(m.outdated, id)
+		}
+	}
+
+	if changed {
+		if err := m.writeVersions(versions); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return outdated, errors.Join(errs...)
+}
+
+// head запрашивает версию файла по rawURL без скачивания.
+func (m *Manager) head(ctx context.Context, rawURL string) (remoteVersion, error) {
+	ctx, cancel := context.WithTimeout(ctx, headTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, rawURL, nil)
+	if err != nil {
+		return remoteVersion{}, err
+	}
+	resp, err := m.httpClient().Do(req)
+	if err != nil {
+		return remoteVersion{}, err
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return remoteVersion{}, fmt.Errorf("HTTP ошибка: %s", resp.Status)
+	}
+	return responseVersion(resp), nil
+}
+
+// IsOutdated возвращает true, если последняя проверка нашла для модели
+// обновление, которое ещё не скачано.
+func (m *Manager) IsOutdated(id string) bool {
+	m.versionsMu.Lock()
+	defer m.versionsMu.Unlock()
+	return m.outdated[id]
+}
+
+// Update скачивает модель заново поверх установленной. Нужна для моделей,
+// у которых CheckForUpdates нашла обновление; прежний файл заменяется
+// только после успешного скачивания.
+func (m *Manager) Update(ctx context.Context, info ModelInfo, progress chan<- Progress) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if info.Path != "" {
+		return sendError(progress, info, fmt.Errorf("модель %s задана локальным путём и не обновляется", info.ID))
+	}
+	return m.download(ctx, info, progress)
+}
@@ -0,0 +1,165 @@
+package models
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+)
+
+func TestRemoteVersionDiffers(t *testing.T) {
+	tests := []struct {
+		name          string
+		local, remote remoteVersion
+		want          bool
+	}{
+		{"тот же ETag", remoteVersion{ETag: `"a"`, Size: 10}, remoteVersion{ETag: `"a"`, Size: 20}, false},
+		{"другой ETag", remoteVersion{ETag: `"a"`, Size: 10}, remoteVersion{ETag: `"b"`, Size: 10}, true},
+		{"нет ETag, тот же размер", remoteVersion{Size: 10}, remoteVersion{ETag: `"b"`, Size: 10}, false},
+		{"нет ETag, другой размер", remoteVersion{ETag: `"a"`, Size: 10}, remoteVersion{Size: 20}, true},
+		{"размер неизвестен", remoteVersion{Size: 10}, remoteVersion{}, false},
+		{"ничего не известно", remoteVersion{}, remoteVersion{}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.local.differs(tt.remote); got != tt.want {
+				t.Errorf("%+v.differs(%+v) = %v, want %v", tt.local, tt.remote, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestResponseVersion(t *testing.T) {
+	tests := []struct {
+		etag string
+		size int64
+		want remoteVersion
+	}{
+		{`"abc"`, 100, remoteVersion{ETag: `"abc"`, Size: 100}},
+		// Слабый ETag сравнивается как сильный: сервер меняет только префикс
+		{`W/"abc"`, 100, remoteVersion{ETag: `"abc"`, Size: 100}},
+		{"", 100, remoteVersion{Size: 100}},
+		// -1 - длина неизвестна
+		{`"abc"`, -1, remoteVersion{ETag: `"abc"`}},
+	}
+	for _, tt := range tests {
+		resp := &http.Response{Header: http.Header{}, ContentLength: tt.size}
+		if tt.etag != "" {
+			resp.Header.Set("ETag", tt.etag)
+		}
+		if got := responseVersion(resp); got != tt.want {
+			t.Errorf("responseVersion(%q, %d) = %+v, want %+v", tt.etag, tt.size, got, tt.want)
+		}
+	}
+}
+
+// updateServer отдаёт HEAD ответы с ETag и статусом, которые меняет тест.
+type updateServer struct {
+	mu     sync.Mutex
+	etag   string
+	status int
+}
+
+func (s *updateServer) set(etag string, status int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.etag, s.status = etag, status
+}
+
+func (s *updateServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	w.Header().Set("ETag", s.etag)
+	w.WriteHeader(s.status)
+}
+
+// newUpdateTest подменяет реестр одной скачанной моделью, которая
+// скачивается с тестового сервера.
+func newUpdateTest(t *testing.T) (*Manager, *updateServer, ModelInfo) {
+	t.Helper()
+	srv := &updateServer{etag: `"v1"`, status: http.StatusOK}
+	ts := httptest.NewServer(srv)
+	t.Cleanup(ts.Close)
+
+	dir := t.TempDir()
+	info := ModelInfo{ID: "test-model", Engine: EngineWhisper, Filename: "test.bin", URL: ts.URL + "/test.bin"}
+	if err := os.MkdirAll(filepath.Join(dir, "whisper"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "whisper", info.Filename), []byte("model"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	registry := Registry
+	Registry = []ModelInfo{info}
+	t.Cleanup(func() { Registry = registry })
+
+	m := &Manager{modelsDir: dir, client: ts.Client(), outdated: make(map[string]bool)}
+	return m, srv, info
+}
+
+func TestCheckForUpdates(t *testing.T) {
+	m, srv, info := newUpdateTest(t)
+	ctx := context.Background()
+
+	// Первая проверка запоминает текущую версию как установленную
+	outdated, err := m.CheckForUpdates(ctx)
+	if err != nil || len(outdated) != 0 {
+		t.Fatalf("первая проверка: %v, %v", outdated, err)
+	}
+	versions, err := m.loadVersions()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := versions[info.ID].ETag; got != `"v1"` {
+		t.Fatalf("запомнен ETag %q, ожидался \"v1\"", got)
+	}
+
+	// Файл на сервере заменили
+	srv.set(`"v2"`, http.StatusOK)
+	outdated, err = m.CheckForUpdates(ctx)
+	if err != nil || len(outdated) != 1 || outdated[0].ID != info.ID {
+		t.Fatalf("после смены ETag: %v, %v", outdated, err)
+	}
+	if !m.IsOutdated(info.ID) {
+		t.Error("IsOutdated = false после найденного обновления")
+	}
+
+	// Скачанная заново модель больше не устаревшая
+	m.saveVersion(info.ID, remoteVersion{ETag: `"v2"`})
+	outdated, err = m.CheckForUpdates(ctx)
+	if err != nil || len(outdated) != 0 || m.IsOutdated(info.ID) {
+		t.Fatalf("после обновления: %v, %v, outdated %v", outdated, err, m.IsOutdated(info.ID))
+	}
+}
+
+func TestCheckForUpdatesHTTPError(t *testing.T) {
+	m, srv, _ := newUpdateTest(t)
+	srv.set(`"v1"`, http.StatusNotFound)
+
+	outdated, err := m.CheckForUpdates(context.Background())
+	if err == nil {
+		t.Error("ошибка сервера не возвращена")
+	}
+	if len(outdated) != 0 {
+		t.Errorf("при ошибке найдены обновления: %v", outdated)
+	}
+	versions, _ := m.loadVersions()
+	if len(versions) != 0 {
+		t.Errorf("при ошибке запомнены версии: %v", versions)
+	}
+}
+
+func TestCheckForUpdatesCancelled(t *testing.T) {
+	m, _, _ := newUpdateTest(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if _, err := m.CheckForUpdates(ctx); !errors.Is(err, context.Canceled) {
+		t.Errorf("CheckForUpdates с отменённым контекстом: %v, ожидалась context.Canceled", err)
+	}
+}
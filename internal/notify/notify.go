@@ -2,71 +2,154 @@
 package notify
 
 import (
-	"github.com/gen2brain/beeep"
+	"sync"
+	"unicode/utf8"
+
+	"shofar/internal/config"
 	"shofar/internal/i18n"
 )
 
 const appName = "Shofar"
 
+// Style - какие уведомления показываются.
+type Style string
+
+const (
+	// StyleSystem - все уведомления.
+	StyleSystem Style = "system"
+	// StyleMinimal - без промежуточных "Запись"/"Обработка", только результат и ошибки.
+	StyleMinimal Style = "minimal"
+	// StyleOff - уведомления не показываются.
+	StyleOff Style = "off"
+)
+
 // Notifier отправляет системные уведомления.
+// Уведомления одной записи (запись -> обработка -> результат) заменяют
+// друг друга, если система это поддерживает (Linux), а не копятся стопкой.
 type Notifier struct {
-	enabled bool
+	mu        sync.Mutex
+	enabled   bool
+	style     Style
+	maxChars  int    // 0 - текст не обрезается
+	sessionID uint32 // id уведомления текущей записи, 0 - нет
 }
 
-// New создаёт новый Notifier.
+// New создаёт новый Notifier. Текст длиннее config.DefaultNotifyMaxChars
+// обрезается, пока SetMaxChars не задаст длину из настроек.
 func New(enabled bool) *Notifier {
-	return &Notifier{enabled: enabled}
+	return &Notifier{enabled: enabled, style: StyleSystem, maxChars: config.DefaultNotifyMaxChars}
+}
+
+// SetMaxChars устанавливает длину текста уведомления в символах.
+// 0 - текст не обрезается.
+func (n *Notifier) SetMaxChars(maxChars int) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.maxChars = max(maxChars, 0)
 }
 
 // SetEnabled включает/выключает уведомления.
 func (n *Notifier) SetEnabled(enabled bool) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
 	n.enabled = enabled
 }
 
+// SetStyle устанавливает стиль уведомлений.
+func (n *Notifier) SetStyle(style Style) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.style = style
+}
+
 // Recording показывает уведомление о начале записи.
+// Начинает новую цепочку уведомлений записи.
 func (n *Notifier) Recording() {
-	n.notify(i18n.T("notify_recording"), i18n.T("notify_recording_hint"))
+	n.mu.Lock()
+	n.sessionID = 0
+	n.mu.Unlock()
+	n.interim(i18n.T("notify_recording"), i18n.T("notify_recording_hint"))
 }
 
 // Processing показывает уведомление об обработке.
 func (n *Notifier) Processing() {
-	n.notify(i18n.T("notify_processing"), i18n.T("notify_processing_hint"))
+	n.interim(i18n.T("notify_processing"), i18n.T("notify_processing_hint"))
 }
 
 // Success показывает уведомление об успешном распознавании.
 func (n *Notifier) Success(text string) {
-	if len(text) > 100 {
-		text = text[:100] + "..."
-	}
-	n.notify(i18n.T("notify_done"), text)
+	n.final(i18n.T("notify_done"), n.truncate(text))
 }
 
 // Empty показывает уведомление о пустом результате.
 func (n *Notifier) Empty() {
-	n.notify(i18n.T("notify_empty"), i18n.T("notify_empty_hint"))
+	n.final(i18n.T("notify_empty"), i18n.T("notify_empty_hint"))
+}
+
+// Muted показывает уведомление о записи без звука - вероятно, микрофон выключен.
+func (n *Notifier) Muted() {
+	n.final(i18n.T("notify_muted"), i18n.T("notify_muted_hint"))
 }
 
 // Error показывает уведомление об ошибке.
 func (n *Notifier) Error(msg string) {
-	n.notify(i18n.T("notify_error"), msg)
+	n.final(i18n.T("notify_error"), msg)
 }
 
 // Info показывает информационное уведомление (для streaming).
 func (n *Notifier) Info(msg string) {
-	if len(msg) > 100 {
-		msg = msg[:100] + "..."
+	n.notify("", n.truncate(msg))
+}
+
+// truncate обрезает текст до maxChars символов. Режет по символам,
+// а не байтам, чтобы не разорвать кириллицу посередине.
+func (n *Notifier) truncate(text string) string {
+	n.mu.Lock()
+	limit := n.maxChars
+	n.mu.Unlock()
+	if limit == 0 || utf8.RuneCountInString(text) <= limit {
+		return text
+	}
+	return string([]rune(text)[:limit]) + "..."
+}
+
+// interim показывает промежуточное уведомление записи: оно заменяет
+// предыдущее и не сохраняется в истории. В стиле minimal не показывается.
+func (n *Notifier) interim(title, message string) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if !n.enabled || n.style != StyleSystem {
+		return
 	}
-	n.notify("", msg)
+	n.sessionID = send(title, message, n.sessionID, true)
+}
+
+// final показывает итоговое уведомление записи на месте промежуточного
+// и завершает цепочку.
+func (n *Notifier) final(title, message string) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	id := n.sessionID
+	n.sessionID = 0
+	if !n.enabled || n.style == StyleOff {
+		return
+	}
+	send(title, message, id, false)
 }
 
 func (n *Notifier) notify(title, message string) {
-	if !n.enabled {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if !n.enabled || n.style == StyleOff {
 		return
 	}
-	// Игнорируем ошибки уведомлений - они не критичны
-	if title != "" {
-		_ = beeep.Notify(appName+": "+title, message, "")
-	} else {
-		_ = beeep.Notify(appName, message, "")
+	send(title, message, 0, false)
+}
+
+// formatTitle добавляет имя приложения к заголовку.
+func formatTitle(title string) string {
+	if title == "" {
+		return appName
 	}
+	return appName + ": " + title
 }
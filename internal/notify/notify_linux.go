@@ -0,0 +1,46 @@
+//go:build linux
+
+package notify
+
+import (
+	"github.com/gen2brain/beeep"
+	"github.com/godbus/dbus/v5"
+)
+
+// urgencyNormal - уровень срочности по спецификации freedesktop.
+// Critical показывался бы поверх режима "Не беспокоить", normal - нет.
+const urgencyNormal = byte(1)
+
+// send показывает уведомление через org.freedesktop.Notifications.
+// replaces - id заменяемого уведомления (0 - новое). transient уведомления
+// не остаются в истории. Возвращает id показанного уведомления или 0,
+// если D-Bus недоступен и использован запасной вариант.
+func send(title, message string, replaces uint32, transient bool) uint32 {
+	conn, err := dbus.SessionBus()
+	if err != nil {
+		// Игнорируем ошибки уведомлений - они не критичны
+		_ = beeep.Notify(formatTitle(title), message, "")
+		return 0
+	}
+
+	hints := map[string]dbus.Variant{
+		"urgency": dbus.MakeVariant(urgencyNormal),
+	}
+	if transient {
+		hints["transient"] = dbus.MakeVariant(true)
+	}
+
+	obj := conn.Object("org.freedesktop.Notifications", "/org/freedesktop/Notifications")
+	call := obj.Call("org.freedesktop.Notifications.Notify", 0,
+		appName, replaces, "", formatTitle(title), message, []string{}, hints, int32(-1))
+	if call.Err != nil {
+		_ = beeep.Notify(formatTitle(title), message, "")
+		return 0
+	}
+
+	var id uint32
+	if err := call.Store(&id); err != nil {
+		return 0
+	}
+	return id
+}
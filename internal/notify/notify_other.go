@@ -0,0 +1,13 @@
+//go:build !linux
+
+package notify
+
+import "github.com/gen2brain/beeep"
+
+// send показывает уведомление. Замена по id здесь не поддерживается,
+// поэтому replaces и transient игнорируются, а возвращается 0.
+func send(title, message string, replaces uint32, transient bool) uint32 {
+	// Игнорируем ошибки уведомлений - они не критичны
+	_ = beeep.Notify(formatTitle(title), message, "")
+	return 0
+}
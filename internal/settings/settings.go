@@ -3,7 +3,9 @@ package settings
 
 import (
 	"context"
-	"log"
+	"errors"
+	"runtime"
+	"strings"
 	"sync"
 	"time"
 
@@ -17,8 +19,11 @@ import (
 	"gioui.org/widget"
 
 	"shofar/internal/config"
+	"shofar/internal/focus"
 	"shofar/internal/i18n"
+	"shofar/internal/logx"
 	"shofar/internal/models"
+	"shofar/internal/theme"
 )
 
 // Colors are defined in widgets.go
@@ -43,17 +48,36 @@ type Window struct {
 	hotkeyModifiers map[config.Modifier]bool
 	hotkeyKey       config.Key
 
+	// UI state - Cancel recording hotkey
+	cancelHotkeyMods map[config.Modifier]bool
+	cancelHotkeyKey  config.Key
+
 	// Download state
 	downloading    bool
 	downloadCtx    context.Context
 	downloadCancel context.CancelFunc
-	progress       float64
+	progress       downloadProgress
 	progressModel  string
 
+	// Last failed download, with the reason; offers a retry
+	downloadErrID string
+	downloadErr   string
+	retryBtn      widget.Clickable
+
 	// Model loading state
 	loadingModel   bool
 	loadingModelID string
 
+	// First run: no recognition model downloaded yet
+	onboarding    bool
+	onboardModel  string // model recommended for this computer on first run
+	onboardingBtn widget.Clickable
+
+	// Model that failed to load, with the reason; offers a re-download
+	modelErrID    string
+	modelErr      string
+	redownloadBtn widget.Clickable
+
 	// Widgets - Engine/Model
 	engineEnum    widget.Enum
 	engineButtons map[models.Engine]*widget.Clickable
@@ -73,28 +97,146 @@ type Window struct {
 	recordingHotkey bool
 	recordedMods    map[config.Modifier]bool
 	recordedKey     config.Key
+	recordingCancel bool           // recording targets the cancel hotkey
 	hotkeyFilters   []event.Filter // cached filters for hotkey recording
 
+	// Widgets - Hotkey presets
+	presetButtons    map[string]*widget.Clickable
+	presetList       widget.List
+	presetNameEditor widget.Editor
+	savePresetBtn    widget.Clickable
+
+	// Widgets - Cancel recording hotkey
+	cancelHotkeyEditBtn widget.Clickable
+
 	// Widgets - Buttons
 	applyBtn  widget.Clickable
 	cancelBtn widget.Clickable
 
 	// Widgets - LLM
-	llmEnabled widget.Bool
+	llmEnabled     widget.Bool
+	llmContextSize int
+	ctxDecBtn      widget.Clickable
+	ctxIncBtn      widget.Clickable
+	llmSampler     config.LLMSampler // pending token sampler
+	samplerBtns    map[config.LLMSampler]*widget.Clickable
+
+	// Widgets - Advanced
+	insertDelayMs int
+	delayDecBtn   widget.Clickable
+	delayIncBtn   widget.Clickable
+	minRecordMs   int
+	minRecDecBtn  widget.Clickable
+	minRecIncBtn  widget.Clickable
+	proxyEditor   widget.Editor
+	threads       int
+	threadsDecBtn widget.Clickable
+	threadsIncBtn widget.Clickable
+	dictationMode widget.Bool
+	express       widget.Bool
+	soundCues     widget.Bool
+	clickThrough  widget.Bool
+	trimSilence   widget.Bool
+	denoise       widget.Bool
+	retryOnEmpty  widget.Bool
+	voskPunct     widget.Bool
+	partials      widget.Bool
+	newlines      config.NewlineHandling // pending newline handling for insert/copy
+	newlineBtns   map[config.NewlineHandling]*widget.Clickable
+	notifyStyle   config.NotificationStyle // pending notification style
+	notifyBtns    map[config.NotificationStyle]*widget.Clickable
+	recPreset     config.RecognitionPreset // pending Whisper speed/accuracy preset
+	recPresetBtns map[config.RecognitionPreset]*widget.Clickable
+	promptEditor  widget.Editor // Whisper initial prompt, multiline
+	grammarEditor widget.Editor // path to the Vosk grammar file
+
+	// Widgets - Microphone test
+	mic        MicTester
+	micTestBtn widget.Clickable
+	micTestEnd time.Time // when the running test stops automatically
 
 	// Widgets - UI Language
 	selectedUILang i18n.Language
 	langButtons    map[i18n.Language]*widget.Clickable
 
+	// Widgets - Theme
+	themeButtons map[string]*widget.Clickable
+
 	// Scroll state
 	modelList   widget.List
 	contentList widget.List // Main scrollable content
 
 	// Callbacks
-	onApply        func(modelID string)
-	onHotkeyChange func(config.HotkeyConfig)
-	onLLMChange    func(enabled bool, modelID string)
-	onUILangChange func(lang i18n.Language)
+	onApply              func(modelID string) error
+	onOnboarded          func(modelID string)
+	onHotkeyChange       func(config.HotkeyConfig)
+	onCancelHotkeyChange func(config.HotkeyConfig)
+	onThreadsChange      func(threads int)
+	onPromptChange       func(prompt string)
+	onGrammarChange      func(path string)
+	onLLMChange          func(enabled bool, modelID string)
+	onUILangChange       func(lang i18n.Language)
+	onThemeChange        func(name string)
+	onNotifyStyleChange  func(style config.NotificationStyle)
+	onRecPresetChange    func(preset config.RecognitionPreset)
+	onLLMSamplerChange   func(sampler config.LLMSampler)
+}
+
+// micTestDuration is how long the microphone test runs unless stopped.
+const micTestDuration = 10 * time.Second
+
+// MicTester opens a short-lived microphone stream for the level meter.
+type MicTester interface {
+	StartMonitor() error
+	StopMonitor()
+	IsMonitoring() bool
+	GetSamples() []float32
+}
+
+// downloadProgress is the model download state shown under the progress bar.
+type downloadProgress struct {
+	fraction   float64       // 0..1
+	speed      float64       // bytes per second, 0 until measured
+	remaining  time.Duration // estimated time left, 0 if unknown
+	extracting bool
+}
+
+// speedSampleInterval is the minimum time between download speed samples.
+const speedSampleInterval = 500 * time.Millisecond
+
+// speedMeter estimates download speed from the Progress stream.
+type speedMeter struct {
+	lastTime  time.Time
+	lastBytes int64
+	speed     float64 // smoothed bytes per second
+}
+
+// update records the downloaded byte count and returns the current speed
+// estimate in bytes per second (0 until the first sample interval passes).
+func (m *speedMeter) update(downloaded int64, now time.Time) float64 {
+	if m.lastTime.IsZero() || downloaded < m.lastBytes {
+		// First sample, or the download restarted from another mirror
+		m.lastTime = now
+		m.lastBytes = downloaded
+		m.speed = 0
+		return 0
+	}
+
+	elapsed := now.Sub(m.lastTime)
+	if elapsed < speedSampleInterval {
+		return m.speed
+	}
+
+	current := float64(downloaded-m.lastBytes) / elapsed.Seconds()
+	if m.speed == 0 {
+		m.speed = current
+	} else {
+		// Exponential smoothing keeps the ETA from jumping around
+		m.speed = 0.7*m.speed + 0.3*current
+	}
+	m.lastTime = now
+	m.lastBytes = downloaded
+	return m.speed
 }
 
 // New creates a new settings window.
@@ -109,13 +251,7 @@ func New(manager *models.Manager, cfg *config.Config) *Window {
 	}
 
 	// Load current model selection from config
-	currentModelID := cfg.ModelID()
-	if currentModelID != "" {
-		if info, ok := models.GetModel(currentModelID); ok {
-			w.selectedEngine = info.Engine
-			w.selectedModel = currentModelID
-		}
-	}
+	w.loadModelSelection()
 
 	// Load current hotkey from config
 	currentHotkey := cfg.Hotkey()
@@ -142,8 +278,33 @@ func New(manager *models.Manager, cfg *config.Config) *Window {
 	// Set key enum value
 	w.keyEnum.Value = string(w.hotkeyKey)
 
+	// Load cancel recording hotkey
+	w.loadCancelHotkey()
+
 	// Initialize LLM toggle
 	w.llmEnabled.Value = cfg.LLMEnabled()
+	w.llmContextSize = cfg.LLMContextSize()
+	w.llmSampler = cfg.LLMSampler()
+
+	// Initialize advanced settings
+	w.insertDelayMs = cfg.InsertDelayMs()
+	w.minRecordMs = cfg.MinRecordingMs()
+	w.threads = cfg.Threads()
+	w.dictationMode.Value = cfg.DictationMode()
+	w.express.Value = cfg.Express()
+	w.soundCues.Value = cfg.SoundCues()
+	w.clickThrough.Value = cfg.WaveformClickThrough()
+	w.trimSilence.Value = cfg.TrimSilence()
+	w.denoise.Value = cfg.Denoise()
+	w.retryOnEmpty.Value = cfg.RetryOnEmpty()
+	w.voskPunct.Value = cfg.VoskAutoPunctuate()
+	w.partials.Value = cfg.PartialResults()
+	w.newlines = cfg.NewlineHandling()
+	w.notifyStyle = cfg.NotificationStyle()
+	w.recPreset = cfg.RecognitionPreset()
+	w.promptEditor.SetText(cfg.WhisperPrompt())
+	w.grammarEditor.SetText(cfg.VoskGrammarFile())
+	w.proxyEditor.SetText(cfg.ProxyURL())
 
 	// Initialize UI language selector
 	w.langButtons = make(map[i18n.Language]*widget.Clickable)
@@ -155,6 +316,10 @@ func New(manager *models.Manager, cfg *config.Config) *Window {
 	// Initialize lists
 	w.modelList.Axis = layout.Vertical
 	w.keyList.Axis = layout.Horizontal
+	w.presetList.Axis = layout.Horizontal
+	w.presetNameEditor.SingleLine = true
+	w.proxyEditor.SingleLine = true
+	w.grammarEditor.SingleLine = true
 	w.contentList.Axis = layout.Vertical
 
 	// Initialize hotkey filters once
@@ -163,6 +328,21 @@ func New(manager *models.Manager, cfg *config.Config) *Window {
 	return w
 }
 
+// punctuationKeys maps Gio key names of punctuation keys to config keys.
+var punctuationKeys = map[key.Name]config.Key{
+	"`":  config.KeyGrave,
+	"-":  config.KeyMinus,
+	"=":  config.KeyEqual,
+	"[":  config.KeyBracketLeft,
+	"]":  config.KeyBracketRight,
+	";":  config.KeySemicolon,
+	"'":  config.KeyApostrophe,
+	",":  config.KeyComma,
+	".":  config.KeyPeriod,
+	"/":  config.KeySlash,
+	"\\": config.KeyBackslash,
+}
+
 func (w *Window) initHotkeyFilters() {
 	modifiers := key.ModCtrl | key.ModShift | key.ModAlt | key.ModSuper
 
@@ -188,6 +368,14 @@ func (w *Window) initHotkeyFilters() {
 	for c := 'A'; c <= 'Z'; c++ {
 		filters = append(filters, key.Filter{Name: key.Name(string(c)), Optional: modifiers})
 	}
+	// Add digits 0-9
+	for c := '0'; c <= '9'; c++ {
+		filters = append(filters, key.Filter{Name: key.Name(string(c)), Optional: modifiers})
+	}
+	// Add punctuation keys
+	for name := range punctuationKeys {
+		filters = append(filters, key.Filter{Name: name, Optional: modifiers})
+	}
 	// Also capture modifier-only events
 	filters = append(filters, key.Filter{Optional: modifiers})
 
@@ -198,12 +386,21 @@ func (w *Window) initHotkeyFilters() {
 }
 
 // OnApply sets the callback for when user applies model changes.
-func (w *Window) OnApply(fn func(modelID string)) {
+// If it returns an error, the window stays open and shows it.
+func (w *Window) OnApply(fn func(modelID string) error) {
 	w.mu.Lock()
 	defer w.mu.Unlock()
 	w.onApply = fn
 }
 
+// OnOnboarded sets the callback for when the first speech model finishes
+// downloading on first run and becomes the configured model.
+func (w *Window) OnOnboarded(fn func(modelID string)) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.onOnboarded = fn
+}
+
 // OnHotkeyChange sets the callback for when user changes hotkey.
 func (w *Window) OnHotkeyChange(fn func(config.HotkeyConfig)) {
 	w.mu.Lock()
@@ -211,6 +408,41 @@ func (w *Window) OnHotkeyChange(fn func(config.HotkeyConfig)) {
 	w.onHotkeyChange = fn
 }
 
+// OnCancelHotkeyChange sets the callback for when user changes the cancel recording hotkey.
+func (w *Window) OnCancelHotkeyChange(fn func(config.HotkeyConfig)) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.onCancelHotkeyChange = fn
+}
+
+// OnThreadsChange sets the callback for when user changes the recognition thread count.
+func (w *Window) OnThreadsChange(fn func(threads int)) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.onThreadsChange = fn
+}
+
+// OnPromptChange sets the callback for when user changes the Whisper initial prompt.
+func (w *Window) OnPromptChange(fn func(prompt string)) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.onPromptChange = fn
+}
+
+// OnGrammarChange sets the callback for when user changes the Vosk grammar file.
+func (w *Window) OnGrammarChange(fn func(path string)) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.onGrammarChange = fn
+}
+
+// SetMicTester sets the microphone used by the "test microphone" section.
+func (w *Window) SetMicTester(m MicTester) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.mic = m
+}
+
 // OnLLMChange sets the callback for when user changes LLM settings.
 func (w *Window) OnLLMChange(fn func(enabled bool, modelID string)) {
 	w.mu.Lock()
@@ -225,34 +457,74 @@ func (w *Window) OnUILangChange(fn func(lang i18n.Language)) {
 	w.onUILangChange = fn
 }
 
+// OnThemeChange sets the callback for when user changes the color theme.
+func (w *Window) OnThemeChange(fn func(name string)) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.onThemeChange = fn
+}
+
+// OnNotifyStyleChange sets the callback for when user changes the notification style.
+func (w *Window) OnNotifyStyleChange(fn func(style config.NotificationStyle)) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.onNotifyStyleChange = fn
+}
+
+// OnRecognitionPresetChange sets the callback for when user changes the Whisper recognition preset.
+func (w *Window) OnRecognitionPresetChange(fn func(preset config.RecognitionPreset)) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.onRecPresetChange = fn
+}
+
+// OnLLMSamplerChange sets the callback for when user changes the LLM token sampler.
+func (w *Window) OnLLMSamplerChange(fn func(sampler config.LLMSampler)) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.onLLMSamplerChange = fn
+}
+
 // Show displays the settings window (non-blocking).
 func (w *Window) Show() {
 	w.mu.Lock()
 	defer w.mu.Unlock()
 
 	if w.running {
+		// Already open: bring it to the front, otherwise the click
+		// looks like it did nothing
+		if w.window != nil {
+			// Not under w.mu: Perform waits for the window event loop
+			go w.window.Perform(system.ActionRaise)
+		}
 		return
 	}
 
+	// Pick up the active theme
+	usePalette(theme.Current())
+
 	// Reload current settings
-	currentModelID := w.config.ModelID()
-	if currentModelID != "" {
-		if info, ok := models.GetModel(currentModelID); ok {
-			w.selectedEngine = info.Engine
-			w.selectedModel = currentModelID
-		}
-	}
+	w.loadModelSelection()
 
 	// Auto-select first downloaded model if none selected
 	if w.selectedModel == "" {
-		for _, m := range models.GetModelsByEngine(w.selectedEngine) {
-			if w.manager.IsDownloaded(m) {
-				w.selectedModel = m.ID
-				break
-			}
+		w.selectedModel = w.firstDownloadedModel(w.selectedEngine)
+	}
+
+	// Onboarding shows the engine of the recommended model and selects it
+	if w.onboarding {
+		w.selectedEngine = models.EngineWhisper
+		if w.onboardModel != "" {
+			w.selectedModel = w.onboardModel
 		}
 	}
 
+	// A failed model is selected so it can be re-downloaded
+	if info, ok := models.GetModel(w.modelErrID); ok {
+		w.selectedEngine = info.Engine
+		w.selectedModel = info.ID
+	}
+
 	w.engineEnum.Value = string(w.selectedEngine)
 
 	currentHotkey := w.config.Hotkey()
@@ -266,9 +538,32 @@ func (w *Window) Show() {
 	w.modAlt.Value = w.hotkeyModifiers[config.ModAlt]
 	w.modSuper.Value = w.hotkeyModifiers[config.ModSuper]
 	w.keyEnum.Value = string(w.hotkeyKey)
+	w.loadCancelHotkey()
 
 	// Reload LLM setting
 	w.llmEnabled.Value = w.config.LLMEnabled()
+	w.llmContextSize = w.config.LLMContextSize()
+	w.llmSampler = w.config.LLMSampler()
+
+	// Reload advanced settings
+	w.insertDelayMs = w.config.InsertDelayMs()
+	w.minRecordMs = w.config.MinRecordingMs()
+	w.threads = w.config.Threads()
+	w.dictationMode.Value = w.config.DictationMode()
+	w.express.Value = w.config.Express()
+	w.soundCues.Value = w.config.SoundCues()
+	w.clickThrough.Value = w.config.WaveformClickThrough()
+	w.trimSilence.Value = w.config.TrimSilence()
+	w.denoise.Value = w.config.Denoise()
+	w.retryOnEmpty.Value = w.config.RetryOnEmpty()
+	w.voskPunct.Value = w.config.VoskAutoPunctuate()
+	w.partials.Value = w.config.PartialResults()
+	w.newlines = w.config.NewlineHandling()
+	w.notifyStyle = w.config.NotificationStyle()
+	w.recPreset = w.config.RecognitionPreset()
+	w.promptEditor.SetText(w.config.WhisperPrompt())
+	w.grammarEditor.SetText(w.config.VoskGrammarFile())
+	w.proxyEditor.SetText(w.config.ProxyURL())
 
 	w.running = true
 	w.stopCh = make(chan struct{})
@@ -277,14 +572,51 @@ func (w *Window) Show() {
 	go w.runEventLoop()
 }
 
+// ShowWithOnboarding displays the settings window with a call to action to
+// download modelID, the model recommended for this computer. Used on first
+// run when no model is installed.
+func (w *Window) ShowWithOnboarding(modelID string) {
+	w.mu.Lock()
+	w.onboarding = true
+	w.onboardModel = modelID
+	w.mu.Unlock()
+	w.Show()
+}
+
+// ShowModelError displays the settings window with modelID selected, the
+// reason it failed to load and a button to download it again.
+func (w *Window) ShowModelError(modelID string, err error) {
+	w.mu.Lock()
+	w.modelErrID = modelID
+	w.modelErr = err.Error()
+	w.mu.Unlock()
+	w.Show()
+}
+
 // Hide closes the settings window.
 func (w *Window) Hide() {
+	doneCh := w.release()
+	if doneCh != nil {
+		select {
+		case <-doneCh:
+		case <-time.After(time.Second):
+		}
+	}
+}
+
+// release marks the window as closed and stops its background work.
+// Returns the channel closed when the event loop exits, nil if the
+// window was not running.
+func (w *Window) release() chan struct{} {
 	w.mu.Lock()
 	if !w.running {
 		w.mu.Unlock()
-		return
+		return nil
 	}
 	w.running = false
+	w.onboarding = false
+	w.modelErrID = ""
+	w.modelErr = ""
 	stopCh := w.stopCh
 	doneCh := w.doneCh
 	w.stopCh = nil
@@ -293,18 +625,18 @@ func (w *Window) Hide() {
 	if w.downloadCancel != nil {
 		w.downloadCancel()
 	}
+	mic := w.mic
 	w.mu.Unlock()
 
-	if stopCh != nil {
-		close(stopCh)
+	// Release the microphone test stream
+	if mic != nil {
+		mic.StopMonitor()
 	}
 
-	if doneCh != nil {
-		select {
-		case <-doneCh:
-		case <-time.After(time.Second):
-		}
+	if stopCh != nil {
+		close(stopCh)
 	}
+	return doneCh
 }
 
 // IsVisible returns true if window is currently shown.
@@ -317,9 +649,15 @@ func (w *Window) IsVisible() bool {
 func (w *Window) runEventLoop() {
 	defer close(w.doneCh)
 
+	title := "Shofar - " + i18n.T("settings_title")
+	if w.config.NoStealFocus() {
+		// Focus stays in the current window until the user clicks here
+		go focus.Keep()(title)
+	}
+
 	w.window = new(app.Window)
 	w.window.Option(
-		app.Title("Shofar - "+i18n.T("settings_title")),
+		app.Title(title),
 		app.Size(unit.Dp(450), unit.Dp(600)),
 		app.MinSize(unit.Dp(400), unit.Dp(500)),
 	)
@@ -348,6 +686,9 @@ func (w *Window) runEventLoop() {
 	for {
 		switch e := w.window.Event().(type) {
 		case app.DestroyEvent:
+			// Closed by the window manager rather than Hide: release it
+			// so the next Show opens a new window
+			w.release()
 			return
 		case app.FrameEvent:
 			gtx := app.NewContext(&ops, e)
@@ -359,28 +700,25 @@ func (w *Window) runEventLoop() {
 }
 
 func (w *Window) handleEvents(gtx layout.Context) {
-	// Handle hotkey edit button
+	// Handle hotkey edit buttons
 	if w.hotkeyEditBtn.Clicked(gtx) {
-		w.mu.Lock()
-		w.recordingHotkey = true
-		w.recordedMods = make(map[config.Modifier]bool)
-		w.recordedKey = ""
-		w.mu.Unlock()
+		w.startHotkeyRecording(false)
+	}
+	if w.cancelHotkeyEditBtn.Clicked(gtx) {
+		w.startHotkeyRecording(true)
 	}
 
-	// Handle hotkey recording
+	// Handle hotkey recording; otherwise keyboard shortcuts are active
 	if w.recordingHotkey {
 		w.handleHotkeyRecording(gtx)
+	} else {
+		w.handleKeyboard(gtx)
 	}
 
 	// Handle engine selection change
 	if w.engineEnum.Update(gtx) {
 		w.mu.Lock()
-		newEngine := models.Engine(w.engineEnum.Value)
-		if newEngine != w.selectedEngine {
-			w.selectedEngine = newEngine
-			w.selectedModel = "" // Reset model selection
-		}
+		w.selectEngine(models.Engine(w.engineEnum.Value))
 		w.mu.Unlock()
 	}
 
@@ -388,16 +726,39 @@ func (w *Window) handleEvents(gtx layout.Context) {
 	for id, btn := range w.modelButtons {
 		// Skip LLM models - they are handled separately in drawLLMModelItem
 		// Check BEFORE calling Clicked() to avoid consuming the event
-		if info, ok := models.GetModel(id); ok && info.Engine == models.EngineLLM {
+		info, ok := models.GetModel(id)
+		if ok && info.Engine == models.EngineLLM {
 			continue
 		}
-		if btn.Clicked(gtx) {
+		// Only downloaded models can be selected, like LLM items;
+		// the others offer just the download button
+		if btn.Clicked(gtx) && ok && w.manager.IsDownloaded(info) {
 			w.mu.Lock()
 			w.selectedModel = id
 			w.mu.Unlock()
 		}
 	}
 
+	// Handle onboarding call to action
+	if w.onboardingBtn.Clicked(gtx) {
+		w.startDownload(w.getOnboardModel())
+	}
+
+	// Handle re-download of a model that failed to load
+	if w.redownloadBtn.Clicked(gtx) {
+		w.redownload()
+	}
+
+	// Handle retry of a failed download
+	if w.retryBtn.Clicked(gtx) {
+		w.mu.Lock()
+		id := w.downloadErrID
+		w.mu.Unlock()
+		if id != "" {
+			w.startDownload(id)
+		}
+	}
+
 	// Handle download buttons
 	for id, btn := range w.downloadBtns {
 		if btn.Clicked(gtx) {
@@ -424,6 +785,99 @@ func (w *Window) handleEvents(gtx layout.Context) {
 		}
 	}
 
+	// Handle theme buttons - apply immediately
+	for name, btn := range w.themeButtons {
+		if btn.Clicked(gtx) && name != theme.Name() {
+			theme.Set(name)
+			usePalette(theme.Current())
+			w.config.SetTheme(name)
+			w.mu.Lock()
+			callback := w.onThemeChange
+			w.mu.Unlock()
+			if callback != nil {
+				callback(name)
+			}
+		}
+	}
+
+	// Handle newline handling buttons
+	for mode, btn := range w.newlineBtns {
+		if btn.Clicked(gtx) {
+			w.mu.Lock()
+			w.newlines = mode
+			w.mu.Unlock()
+		}
+	}
+
+	// Handle notification style buttons
+	for style, btn := range w.notifyBtns {
+		if btn.Clicked(gtx) {
+			w.mu.Lock()
+			w.notifyStyle = style
+			w.mu.Unlock()
+		}
+	}
+
+	// Handle LLM sampler buttons
+	for sampler, btn := range w.samplerBtns {
+		if btn.Clicked(gtx) {
+			w.mu.Lock()
+			w.llmSampler = sampler
+			w.mu.Unlock()
+		}
+	}
+
+	// Handle recognition preset buttons
+	for preset, btn := range w.recPresetBtns {
+		if btn.Clicked(gtx) {
+			w.mu.Lock()
+			w.recPreset = preset
+			w.mu.Unlock()
+		}
+	}
+
+	// Handle insert delay stepper
+	if w.delayDecBtn.Clicked(gtx) {
+		w.stepInsertDelay(-insertDelayStep)
+	}
+	if w.delayIncBtn.Clicked(gtx) {
+		w.stepInsertDelay(insertDelayStep)
+	}
+
+	// Handle minimum recording duration stepper
+	if w.minRecDecBtn.Clicked(gtx) {
+		w.stepMinRecording(-minRecordStep)
+	}
+	if w.minRecIncBtn.Clicked(gtx) {
+		w.stepMinRecording(minRecordStep)
+	}
+
+	// Handle LLM context size stepper
+	if w.ctxDecBtn.Clicked(gtx) {
+		w.stepContextSize(false)
+	}
+	if w.ctxIncBtn.Clicked(gtx) {
+		w.stepContextSize(true)
+	}
+
+	// Handle threads stepper
+	if w.threadsDecBtn.Clicked(gtx) {
+		w.stepThreads(-1)
+	}
+	if w.threadsIncBtn.Clicked(gtx) {
+		w.stepThreads(1)
+	}
+
+	// Handle microphone test button
+	if w.micTestBtn.Clicked(gtx) {
+		w.toggleMicTest()
+	}
+
+	// Handle save preset button
+	if w.savePresetBtn.Clicked(gtx) {
+		w.savePreset()
+	}
+
 	// Handle cancel button
 	if w.cancelBtn.Clicked(gtx) {
 		w.Hide()
@@ -435,6 +889,92 @@ func (w *Window) handleEvents(gtx layout.Context) {
 	}
 }
 
+// handleKeyboard handles window-wide keys: Esc closes the window, Ctrl+Enter
+// applies settings. Arrows move focus between model buttons (Up/Down) and
+// engine buttons (Left/Right); Tab/Shift+Tab traversal is provided by Gio.
+func (w *Window) handleKeyboard(gtx layout.Context) {
+	modelOrder := w.modelFocusOrder()
+	engineOrder := w.engineFocusOrder()
+
+	filters := []event.Filter{
+		key.Filter{Name: key.NameEscape},
+		key.Filter{Name: key.NameReturn, Required: key.ModShortcut},
+	}
+	// Arrows only while a button of the group is focused, so text fields keep them
+	for _, btn := range modelOrder {
+		filters = append(filters,
+			key.Filter{Focus: btn, Name: key.NameUpArrow},
+			key.Filter{Focus: btn, Name: key.NameDownArrow})
+	}
+	for _, btn := range engineOrder {
+		filters = append(filters,
+			key.Filter{Focus: btn, Name: key.NameLeftArrow},
+			key.Filter{Focus: btn, Name: key.NameRightArrow})
+	}
+
+	for {
+		ev, ok := gtx.Event(filters...)
+		if !ok {
+			break
+		}
+		e, ok := ev.(key.Event)
+		if !ok || e.State != key.Press {
+			continue
+		}
+
+		switch e.Name {
+		case key.NameEscape:
+			w.Hide()
+			return
+		case key.NameReturn:
+			w.applySettings()
+		case key.NameUpArrow:
+			w.moveFocus(gtx, modelOrder, -1)
+		case key.NameDownArrow:
+			w.moveFocus(gtx, modelOrder, 1)
+		case key.NameLeftArrow:
+			w.moveFocus(gtx, engineOrder, -1)
+		case key.NameRightArrow:
+			w.moveFocus(gtx, engineOrder, 1)
+		}
+	}
+}
+
+// modelFocusOrder returns the model buttons of the selected engine in display order.
+func (w *Window) modelFocusOrder() []*widget.Clickable {
+	w.mu.Lock()
+	engine := w.selectedEngine
+	w.mu.Unlock()
+
+	var order []*widget.Clickable
+	for _, m := range models.GetModelsByEngine(engine) {
+		if btn := w.modelButtons[m.ID]; btn != nil {
+			order = append(order, btn)
+		}
+	}
+	return order
+}
+
+// engineFocusOrder returns the engine buttons in display order.
+func (w *Window) engineFocusOrder() []*widget.Clickable {
+	return []*widget.Clickable{
+		w.getEngineButton(models.EngineWhisper),
+		w.getEngineButton(models.EngineVosk),
+	}
+}
+
+// moveFocus moves focus from the focused button in order to the next
+// (delta > 0) or previous one, stopping at the ends.
+func (w *Window) moveFocus(gtx layout.Context, order []*widget.Clickable, delta int) {
+	for i, btn := range order {
+		if gtx.Focused(btn) {
+			next := min(max(i+delta, 0), len(order)-1)
+			gtx.Execute(key.FocusCmd{Tag: order[next]})
+			return
+		}
+	}
+}
+
 func (w *Window) handleHotkeyRecording(gtx layout.Context) {
 	// Track modifiers at the time of key press (not release)
 	var pressedMods map[config.Modifier]bool
@@ -468,7 +1008,11 @@ func (w *Window) handleHotkeyRecording(gtx layout.Context) {
 				case key.NameTab:
 					w.recordedKey = config.KeyTab
 				case key.NameEscape:
-					// Cancel recording
+					if e.Modifiers&(key.ModCtrl|key.ModShift|key.ModAlt|key.ModSuper) != 0 {
+						w.recordedKey = config.KeyEscape
+						break
+					}
+					// Plain Escape cancels recording
 					w.recordingHotkey = false
 					w.mu.Unlock()
 					return
@@ -482,27 +1026,50 @@ func (w *Window) handleHotkeyRecording(gtx layout.Context) {
 					w.recordedKey = config.KeyF4
 				case key.NameF5:
 					w.recordedKey = config.KeyF5
+				case key.NameF6:
+					w.recordedKey = config.KeyF6
+				case key.NameF7:
+					w.recordedKey = config.KeyF7
+				case key.NameF8:
+					w.recordedKey = config.KeyF8
+				case key.NameF9:
+					w.recordedKey = config.KeyF9
+				case key.NameF10:
+					w.recordedKey = config.KeyF10
+				case key.NameF11:
+					w.recordedKey = config.KeyF11
+				case key.NameF12:
+					w.recordedKey = config.KeyF12
 				default:
-					// Letter keys (A-Z)
 					if len(e.Name) == 1 && e.Name >= "A" && e.Name <= "Z" {
+						// Letter keys (A-Z)
 						w.recordedKey = config.Key(string(e.Name[0] + 32)) // lowercase
+					} else if len(e.Name) == 1 && e.Name >= "0" && e.Name <= "9" {
+						// Digit keys (0-9)
+						w.recordedKey = config.Key(e.Name)
+					} else if k, ok := punctuationKeys[e.Name]; ok {
+						w.recordedKey = k
 					}
 				}
 			}
 
-			// Check if we have modifiers + key
-			hasModifiers := w.recordedMods[config.ModCtrl] || w.recordedMods[config.ModShift] ||
-				w.recordedMods[config.ModAlt] || w.recordedMods[config.ModSuper]
-			hasKey := w.recordedKey != ""
+			// Check if we have a valid combination (modifiers + key, or a function key)
+			recorded := buildHotkey(w.recordedMods, w.recordedKey)
 
-			// On key release, if we have modifiers + key, finish recording
-			if e.State == key.Release && hasModifiers && hasKey {
+			// On key release, if the combination is valid, finish recording
+			if e.State == key.Release && recorded.IsValid() {
 				// Apply the recorded hotkey
-				w.hotkeyModifiers = make(map[config.Modifier]bool)
+				mods := make(map[config.Modifier]bool)
 				for k, v := range w.recordedMods {
-					w.hotkeyModifiers[k] = v
+					mods[k] = v
+				}
+				if w.recordingCancel {
+					w.cancelHotkeyMods = mods
+					w.cancelHotkeyKey = w.recordedKey
+				} else {
+					w.hotkeyModifiers = mods
+					w.hotkeyKey = w.recordedKey
 				}
-				w.hotkeyKey = w.recordedKey
 				w.recordingHotkey = false
 			}
 
@@ -519,9 +1086,23 @@ func (w *Window) applySettings() {
 		return
 	}
 
+	selectedEngine := w.selectedEngine
 	selectedModel := w.selectedModel
 	modelCallback := w.onApply
 	hotkeyCallback := w.onHotkeyChange
+	cancelHotkeyCallback := w.onCancelHotkeyChange
+	threadsCallback := w.onThreadsChange
+	notifyStyleCallback := w.onNotifyStyleChange
+	notifyStyle := w.notifyStyle
+	recPresetCallback := w.onRecPresetChange
+	recPreset := w.recPreset
+	samplerCallback := w.onLLMSamplerChange
+	sampler := w.llmSampler
+	threads := w.threads
+	promptCallback := w.onPromptChange
+	prompt := strings.TrimSpace(w.promptEditor.Text())
+	grammarCallback := w.onGrammarChange
+	grammar := strings.TrimSpace(w.grammarEditor.Text())
 	llmCallback := w.onLLMChange
 	llmEnabled := w.llmEnabled.Value
 	llmModelID := w.config.LLMModelID()
@@ -531,37 +1112,114 @@ func (w *Window) applySettings() {
 
 	// Save LLM setting immediately
 	w.config.SetLLMEnabled(llmEnabled)
+	w.config.SetLLMContextSize(w.llmContextSize)
+	samplerChanged := sampler != w.config.LLMSampler()
+	if samplerChanged {
+		w.config.SetLLMSampler(sampler)
+	}
 
-	// Build hotkey config
-	var mods []config.Modifier
-	if w.hotkeyModifiers[config.ModCtrl] {
-		mods = append(mods, config.ModCtrl)
+	// Remember the engine even if none of its models is downloaded yet
+	w.config.SetEngine(string(selectedEngine))
+
+	// Save advanced settings
+	w.config.SetInsertDelayMs(w.insertDelayMs)
+	if w.express.Value != w.config.Express() {
+		w.config.SetExpress(w.express.Value)
 	}
-	if w.hotkeyModifiers[config.ModShift] {
-		mods = append(mods, config.ModShift)
+	w.config.SetMinRecordingMs(w.minRecordMs)
+	w.config.SetDictationMode(w.dictationMode.Value)
+	w.config.SetSoundCues(w.soundCues.Value)
+	w.config.SetWaveformClickThrough(w.clickThrough.Value)
+	w.config.SetTrimSilence(w.trimSilence.Value)
+	w.config.SetDenoise(w.denoise.Value)
+	w.config.SetRetryOnEmpty(w.retryOnEmpty.Value)
+	w.config.SetVoskAutoPunctuate(w.voskPunct.Value)
+	w.config.SetPartialResults(w.partials.Value)
+	w.config.SetNewlineHandling(w.newlines)
+	notifyStyleChanged := notifyStyle != w.config.NotificationStyle()
+	if notifyStyleChanged {
+		w.config.SetNotificationStyle(notifyStyle)
 	}
-	if w.hotkeyModifiers[config.ModAlt] {
-		mods = append(mods, config.ModAlt)
+	recPresetChanged := recPreset != w.config.RecognitionPreset()
+	if recPresetChanged {
+		w.config.SetRecognitionPreset(recPreset)
 	}
-	if w.hotkeyModifiers[config.ModSuper] {
-		mods = append(mods, config.ModSuper)
+	w.applyProxy(strings.TrimSpace(w.proxyEditor.Text()))
+	threadsChanged := threads != w.config.Threads()
+	if threadsChanged {
+		w.config.SetThreads(threads)
 	}
-	newHotkey := config.HotkeyConfig{
-		Modifiers: mods,
-		Key:       w.hotkeyKey,
+	promptChanged := prompt != w.config.WhisperPrompt()
+	if promptChanged {
+		w.config.SetWhisperPrompt(prompt)
 	}
+	grammarChanged := grammar != w.config.VoskGrammarFile()
+	if grammarChanged {
+		w.config.SetVoskGrammarFile(grammar)
+	}
+
+	// Build hotkey config
+	newHotkey := buildHotkey(w.hotkeyModifiers, w.hotkeyKey)
+	newCancelHotkey := buildHotkey(w.cancelHotkeyMods, w.cancelHotkeyKey)
 	w.mu.Unlock()
 
-	// Apply hotkey if changed (this is fast, do it synchronously)
+	// Apply hotkey if changed (this is fast, do it synchronously).
+	// The follow-up key is set in the config file only: keep it while
+	// the leader combination stays the same
 	currentHotkey := w.config.Hotkey()
+	leader := currentHotkey
+	leader.FollowupKey = ""
+	if newHotkey.String() == leader.String() {
+		newHotkey.FollowupKey = currentHotkey.FollowupKey
+	}
 	if newHotkey.String() != currentHotkey.String() {
-		if len(mods) > 0 && newHotkey.Key != "" {
+		if newHotkey.IsValid() {
 			if hotkeyCallback != nil {
 				hotkeyCallback(newHotkey)
 			}
 		}
 	}
 
+	// Apply cancel hotkey if changed; it must not shadow the main hotkey
+	currentCancelHotkey := w.config.CancelHotkey()
+	if newCancelHotkey.String() != currentCancelHotkey.String() {
+		if newCancelHotkey.IsValid() && newCancelHotkey.String() != newHotkey.String() {
+			if cancelHotkeyCallback != nil {
+				cancelHotkeyCallback(newCancelHotkey)
+			}
+		}
+	}
+
+	// Apply thread count change
+	if threadsChanged && threadsCallback != nil {
+		threadsCallback(threads)
+	}
+
+	// Apply notification style change
+	if notifyStyleChanged && notifyStyleCallback != nil {
+		notifyStyleCallback(notifyStyle)
+	}
+
+	// Apply recognition preset change
+	if recPresetChanged && recPresetCallback != nil {
+		recPresetCallback(recPreset)
+	}
+
+	// Apply LLM sampler change
+	if samplerChanged && samplerCallback != nil {
+		samplerCallback(sampler)
+	}
+
+	// Apply Whisper prompt change
+	if promptChanged && promptCallback != nil {
+		promptCallback(prompt)
+	}
+
+	// Apply Vosk grammar change
+	if grammarChanged && grammarCallback != nil {
+		grammarCallback(grammar)
+	}
+
 	// Apply LLM settings change
 	if llmCallback != nil {
 		llmCallback(llmEnabled, llmModelID)
@@ -591,11 +1249,18 @@ func (w *Window) applySettings() {
 
 	go func() {
 		// Call the callback (this is the slow part - loading model into memory)
-		modelCallback(selectedModel)
+		err := modelCallback(selectedModel)
 
 		w.mu.Lock()
 		w.loadingModel = false
 		w.loadingModelID = ""
+		if err != nil {
+			// Keep the window open with the reason and a re-download offer
+			w.modelErrID = selectedModel
+			w.modelErr = err.Error()
+			w.mu.Unlock()
+			return
+		}
 		w.mu.Unlock()
 
 		// Hide window after loading is complete
@@ -603,6 +1268,262 @@ func (w *Window) applySettings() {
 	}()
 }
 
+// Insert delay stepper bounds (ms).
+const (
+	insertDelayStep = 50
+	insertDelayMax  = 2000
+)
+
+// stepInsertDelay changes the pending insert delay by delta, clamped to [0, insertDelayMax].
+func (w *Window) stepInsertDelay(delta int) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.insertDelayMs += delta
+	if w.insertDelayMs < 0 {
+		w.insertDelayMs = 0
+	}
+	if w.insertDelayMs > insertDelayMax {
+		w.insertDelayMs = insertDelayMax
+	}
+}
+
+func (w *Window) getInsertDelay() int {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.insertDelayMs
+}
+
+// Minimum recording duration stepper bounds (ms).
+const (
+	minRecordStep = 100
+	minRecordMax  = 2000
+)
+
+// stepMinRecording changes the pending minimum recording duration by delta,
+// clamped to [0, minRecordMax].
+func (w *Window) stepMinRecording(delta int) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.minRecordMs = max(0, min(minRecordMax, w.minRecordMs+delta))
+}
+
+func (w *Window) getMinRecording() int {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.minRecordMs
+}
+
+// LLM context size stepper bounds (tokens); each step doubles or halves.
+const (
+	contextSizeMin = 512
+	contextSizeMax = 32768
+)
+
+// stepContextSize doubles or halves the pending LLM context size,
+// clamped to [contextSizeMin, contextSizeMax].
+func (w *Window) stepContextSize(up bool) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if up {
+		w.llmContextSize *= 2
+	} else {
+		w.llmContextSize /= 2
+	}
+	w.llmContextSize = min(max(w.llmContextSize, contextSizeMin), contextSizeMax)
+}
+
+func (w *Window) getContextSize() int {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.llmContextSize
+}
+
+// stepThreads changes the pending thread count by delta, clamped to
+// [0, runtime.NumCPU()]. 0 means the library default.
+func (w *Window) stepThreads(delta int) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.threads += delta
+	if w.threads < 0 {
+		w.threads = 0
+	}
+	if w.threads > runtime.NumCPU() {
+		w.threads = runtime.NumCPU()
+	}
+}
+
+func (w *Window) getThreads() int {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.threads
+}
+
+// toggleMicTest starts the microphone test or stops the running one.
+// The test stops by itself after micTestDuration.
+func (w *Window) toggleMicTest() {
+	w.mu.Lock()
+	mic := w.mic
+	w.mu.Unlock()
+	if mic == nil {
+		return
+	}
+
+	if mic.IsMonitoring() {
+		mic.StopMonitor()
+		return
+	}
+
+	if err := mic.StartMonitor(); err != nil {
+		logx.Warn("Settings: microphone test failed", "err", err)
+		return
+	}
+
+	end := time.Now().Add(micTestDuration)
+	w.mu.Lock()
+	w.micTestEnd = end
+	w.mu.Unlock()
+
+	go func() {
+		time.Sleep(micTestDuration)
+		w.mu.Lock()
+		// A newer test was started meanwhile
+		current := w.micTestEnd.Equal(end)
+		w.mu.Unlock()
+		if current {
+			mic.StopMonitor()
+		}
+	}()
+}
+
+// getMicTestState returns current microphone samples while the test runs.
+func (w *Window) getMicTestState() (testing bool, samples []float32, remaining time.Duration) {
+	w.mu.Lock()
+	mic := w.mic
+	end := w.micTestEnd
+	w.mu.Unlock()
+	if mic == nil || !mic.IsMonitoring() {
+		return false, nil, 0
+	}
+	return true, mic.GetSamples(), time.Until(end)
+}
+
+// loadModelSelection restores the engine and model from config. The engine
+// is kept even when none of its models is downloaded yet; the configured model
+// is selected only if it belongs to that engine and is still downloaded.
+// Must be called with w.mu held (or before the window is shared).
+func (w *Window) loadModelSelection() {
+	w.selectedEngine = models.PreferredEngine(w.config.Engine(), w.config.ModelID())
+	w.selectedModel = ""
+	info, ok := models.GetModel(w.config.ModelID())
+	if ok && info.Engine == w.selectedEngine && w.manager.IsDownloaded(info) {
+		w.selectedModel = info.ID
+	}
+}
+
+// selectEngine switches to engine and selects its first downloaded model,
+// or nothing if none is downloaded. Must be called with w.mu held.
+func (w *Window) selectEngine(engine models.Engine) {
+	if engine == w.selectedEngine {
+		return
+	}
+	w.selectedEngine = engine
+	w.selectedModel = w.firstDownloadedModel(engine)
+}
+
+// firstDownloadedModel returns the first downloaded model of engine, or "".
+func (w *Window) firstDownloadedModel(engine models.Engine) string {
+	for _, m := range models.GetModelsByEngine(engine) {
+		if w.manager.IsDownloaded(m) {
+			return m.ID
+		}
+	}
+	return ""
+}
+
+// applyProxy switches model downloads to the given proxy and saves it.
+// An invalid address is logged and the previous proxy is kept.
+func (w *Window) applyProxy(proxyURL string) {
+	if proxyURL == w.config.ProxyURL() {
+		return
+	}
+	if err := w.manager.SetProxy(proxyURL); err != nil {
+		logx.Warn("Settings: proxy not applied", "err", err)
+		return
+	}
+	w.config.SetProxyURL(proxyURL)
+}
+
+// buildHotkey converts modifier flags and key into a hotkey config
+// with modifiers in canonical order.
+func buildHotkey(mods map[config.Modifier]bool, k config.Key) config.HotkeyConfig {
+	var result []config.Modifier
+	for _, m := range config.AvailableModifiers() {
+		if mods[m] {
+			result = append(result, m)
+		}
+	}
+	return config.HotkeyConfig{
+		Modifiers: result,
+		Key:       k,
+	}
+}
+
+// startHotkeyRecording begins capturing a key combination for the main
+// hotkey or, if cancel is set, for the cancel recording hotkey. Clicking the
+// button of the hotkey being recorded stops recording.
+func (w *Window) startHotkeyRecording(cancel bool) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.recordingHotkey && w.recordingCancel == cancel {
+		w.recordingHotkey = false
+		return
+	}
+	w.recordingHotkey = true
+	w.recordingCancel = cancel
+	w.recordedMods = make(map[config.Modifier]bool)
+	w.recordedKey = ""
+}
+
+// loadCancelHotkey loads the cancel recording hotkey from config into the pending state.
+func (w *Window) loadCancelHotkey() {
+	hk := w.config.CancelHotkey()
+	w.cancelHotkeyMods = make(map[config.Modifier]bool)
+	for _, m := range hk.Modifiers {
+		w.cancelHotkeyMods[m] = true
+	}
+	w.cancelHotkeyKey = hk.Key
+}
+
+// applyPreset loads the preset hotkey into the pending state.
+// It is registered on Apply like a manually recorded hotkey.
+func (w *Window) applyPreset(preset config.HotkeyPreset) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.hotkeyModifiers = make(map[config.Modifier]bool)
+	for _, m := range preset.Hotkey.Modifiers {
+		w.hotkeyModifiers[m] = true
+	}
+	w.hotkeyKey = preset.Hotkey.Key
+	w.keyEnum.Value = string(w.hotkeyKey)
+	w.recordingHotkey = false
+}
+
+// savePreset stores the pending hotkey as a named user preset.
+func (w *Window) savePreset() {
+	mods, k := w.getHotkeyState()
+	hk := buildHotkey(mods, k)
+	if !hk.IsValid() {
+		return
+	}
+
+	name := strings.TrimSpace(w.presetNameEditor.Text())
+	if name == "" {
+		name = strings.Join(buildHotkeyParts(mods, k), "+")
+	}
+	w.config.SaveHotkeyPreset(config.HotkeyPreset{Name: name, Hotkey: hk})
+	w.presetNameEditor.SetText("")
+}
+
 func (w *Window) startDownload(modelID string) {
 	w.mu.Lock()
 	if w.downloading {
@@ -618,7 +1539,9 @@ func (w *Window) startDownload(modelID string) {
 
 	w.downloading = true
 	w.progressModel = modelID
-	w.progress = 0
+	w.progress = downloadProgress{}
+	w.downloadErrID = ""
+	w.downloadErr = ""
 	w.downloadCtx, w.downloadCancel = context.WithCancel(context.Background())
 	ctx := w.downloadCtx
 	w.mu.Unlock()
@@ -627,36 +1550,126 @@ func (w *Window) startDownload(modelID string) {
 		progressCh := make(chan models.Progress, 10)
 
 		go func() {
+			var meter speedMeter
 			for p := range progressCh {
+				if p.Error != nil {
+					// Cancellation is not a failure, the bar just goes away
+					if !errors.Is(p.Error, context.Canceled) {
+						w.mu.Lock()
+						w.downloadErrID = p.ModelID
+						w.downloadErr = p.Error.Error()
+						w.mu.Unlock()
+					}
+					continue
+				}
+				speed := meter.update(p.Downloaded, time.Now())
 				w.mu.Lock()
 				if p.Total > 0 {
-					w.progress = float64(p.Downloaded) / float64(p.Total)
+					w.progress.fraction = float64(p.Downloaded) / float64(p.Total)
+				}
+				w.progress.extracting = p.Extracting
+				w.progress.speed = 0
+				w.progress.remaining = 0
+				if !p.Extracting && speed > 0 {
+					w.progress.speed = speed
+					if left := p.Total - p.Downloaded; left > 0 {
+						w.progress.remaining = time.Duration(float64(left) / speed * float64(time.Second))
+					}
 				}
 				w.mu.Unlock()
 			}
 		}()
 
-		err := w.manager.Download(ctx, info, progressCh)
+		download := w.manager.Download
+		if w.manager.IsOutdated(modelID) && w.manager.IsDownloaded(info) {
+			download = w.manager.Update
+		}
+		err := download(ctx, info, progressCh)
 		close(progressCh)
 
 		w.mu.Lock()
 		w.downloading = false
 		w.downloadCancel = nil
+		var onboarded func(modelID string)
 		if err == nil {
 			w.selectedModel = modelID
-		} else if err != context.Canceled {
-			log.Printf("Settings: download error: %v", err)
+			if info.Engine != models.EngineLLM {
+				// The first model becomes the one loaded on start
+				if w.onboarding && w.config.ModelID() == "" {
+					w.config.SetModelID(modelID)
+					onboarded = w.onOnboarded
+				}
+				w.onboarding = false
+				w.config.SetOnboarded()
+			}
+		} else if !errors.Is(err, context.Canceled) {
+			logx.Error("Settings: download error", "err", err)
 		}
 		w.mu.Unlock()
+
+		if onboarded != nil {
+			onboarded(modelID)
+		}
 	}()
 }
 
-func (w *Window) getState() (engine models.Engine, selectedModel string, downloading bool, progress float64, progressModel string) {
+// getDownloadError returns the model whose download failed and the reason.
+func (w *Window) getDownloadError() (modelID, msg string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.downloadErrID, w.downloadErr
+}
+
+func (w *Window) getState() (engine models.Engine, selectedModel string, downloading bool, progress downloadProgress, progressModel string) {
 	w.mu.Lock()
 	defer w.mu.Unlock()
 	return w.selectedEngine, w.selectedModel, w.downloading, w.progress, w.progressModel
 }
 
+// redownload deletes the model that failed to load and downloads it again.
+func (w *Window) redownload() {
+	w.mu.Lock()
+	id := w.modelErrID
+	w.mu.Unlock()
+
+	info, ok := models.GetModel(id)
+	if !ok {
+		return
+	}
+	if err := w.manager.Delete(info); err != nil {
+		logx.Error("Settings: delete failed", "model", id, "err", err)
+		return
+	}
+
+	w.mu.Lock()
+	w.modelErrID = ""
+	w.modelErr = ""
+	w.mu.Unlock()
+	w.startDownload(id)
+}
+
+func (w *Window) getModelError() (modelID, msg string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.modelErrID, w.modelErr
+}
+
+// getOnboardModel returns the model offered on first run.
+func (w *Window) getOnboardModel() string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if _, ok := models.GetModel(w.onboardModel); !ok {
+		return models.DefaultModelID()
+	}
+	return w.onboardModel
+}
+
+func (w *Window) isOnboarding() bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.onboarding
+}
+
 func (w *Window) getLoadingState() (loading bool, modelID string) {
 	w.mu.Lock()
 	defer w.mu.Unlock()
@@ -674,10 +1687,26 @@ func (w *Window) getHotkeyState() (mods map[config.Modifier]bool, key config.Key
 	return modsCopy, w.hotkeyKey
 }
 
+func (w *Window) getCancelHotkeyState() (mods map[config.Modifier]bool, key config.Key) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	modsCopy := make(map[config.Modifier]bool)
+	for k, v := range w.cancelHotkeyMods {
+		modsCopy[k] = v
+	}
+	return modsCopy, w.cancelHotkeyKey
+}
+
 func (w *Window) isRecordingHotkey() bool {
 	w.mu.Lock()
 	defer w.mu.Unlock()
-	return w.recordingHotkey
+	return w.recordingHotkey && !w.recordingCancel
+}
+
+func (w *Window) isRecordingCancelHotkey() bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.recordingHotkey && w.recordingCancel
 }
 
 func (w *Window) getRecordingState() (mods map[config.Modifier]bool, key config.Key) {
@@ -696,6 +1725,90 @@ func (w *Window) getSelectedUILang() i18n.Language {
 	return w.selectedUILang
 }
 
+func (w *Window) getPresetButton(id string) *widget.Clickable {
+	if w.presetButtons == nil {
+		w.presetButtons = make(map[string]*widget.Clickable)
+	}
+	if w.presetButtons[id] == nil {
+		w.presetButtons[id] = new(widget.Clickable)
+	}
+	return w.presetButtons[id]
+}
+
+func (w *Window) getNewlineButton(mode config.NewlineHandling) *widget.Clickable {
+	if w.newlineBtns == nil {
+		w.newlineBtns = make(map[config.NewlineHandling]*widget.Clickable)
+	}
+	if w.newlineBtns[mode] == nil {
+		w.newlineBtns[mode] = new(widget.Clickable)
+	}
+	return w.newlineBtns[mode]
+}
+
+func (w *Window) getNewlineHandling() config.NewlineHandling {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.newlines
+}
+
+func (w *Window) getNotifyStyleButton(style config.NotificationStyle) *widget.Clickable {
+	if w.notifyBtns == nil {
+		w.notifyBtns = make(map[config.NotificationStyle]*widget.Clickable)
+	}
+	if w.notifyBtns[style] == nil {
+		w.notifyBtns[style] = new(widget.Clickable)
+	}
+	return w.notifyBtns[style]
+}
+
+func (w *Window) getNotifyStyle() config.NotificationStyle {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.notifyStyle
+}
+
+func (w *Window) getRecPresetButton(preset config.RecognitionPreset) *widget.Clickable {
+	if w.recPresetBtns == nil {
+		w.recPresetBtns = make(map[config.RecognitionPreset]*widget.Clickable)
+	}
+	if w.recPresetBtns[preset] == nil {
+		w.recPresetBtns[preset] = new(widget.Clickable)
+	}
+	return w.recPresetBtns[preset]
+}
+
+func (w *Window) getRecPreset() config.RecognitionPreset {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.recPreset
+}
+
+func (w *Window) getSamplerButton(sampler config.LLMSampler) *widget.Clickable {
+	if w.samplerBtns == nil {
+		w.samplerBtns = make(map[config.LLMSampler]*widget.Clickable)
+	}
+	if w.samplerBtns[sampler] == nil {
+		w.samplerBtns[sampler] = new(widget.Clickable)
+	}
+	return w.samplerBtns[sampler]
+}
+
+func (w *Window) getLLMSampler() config.LLMSampler {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.llmSampler
+}
+
+func (w *Window) getThemeButton(name string) *widget.Clickable {
+	if w.themeButtons == nil {
+		w.themeButtons = make(map[string]*widget.Clickable)
+	}
+	if w.themeButtons[name] == nil {
+		w.themeButtons[name] = new(widget.Clickable)
+	}
+	return w.themeButtons[name]
+}
+
 func (w *Window) getLangButton(lang i18n.Language) *widget.Clickable {
 	if w.langButtons == nil {
 		w.langButtons = make(map[i18n.Language]*widget.Clickable)
@@ -5,6 +5,7 @@ import (
 	"image"
 	"image/color"
 	"math"
+	"path/filepath"
 	"time"
 
 	"gioui.org/font"
@@ -19,21 +20,44 @@ import (
 	"shofar/internal/config"
 	"shofar/internal/i18n"
 	"shofar/internal/models"
+	"shofar/internal/theme"
+	"shofar/internal/waveform"
 )
 
-// Color palette - modern dark theme
+// Color palette, set from the active theme by usePalette
 var (
-	colorBG         = color.NRGBA{R: 30, G: 30, B: 34, A: 255}
-	colorPanel      = color.NRGBA{R: 45, G: 45, B: 50, A: 255}
-	colorPanelLight = color.NRGBA{R: 55, G: 55, B: 62, A: 255}
-	colorText       = color.NRGBA{R: 240, G: 240, B: 245, A: 255}
-	colorTextDim    = color.NRGBA{R: 140, G: 140, B: 150, A: 255}
-	colorAccent     = color.NRGBA{R: 88, G: 166, B: 255, A: 255}
-	colorSuccess    = color.NRGBA{R: 80, G: 200, B: 120, A: 255}
-	colorWarning    = color.NRGBA{R: 255, G: 180, B: 0, A: 255}
-	colorSelected   = color.NRGBA{R: 60, G: 100, B: 160, A: 255}
+	colorBG         color.NRGBA
+	colorPanel      color.NRGBA
+	colorPanelLight color.NRGBA
+	colorButton     color.NRGBA
+	colorOverlay    color.NRGBA
+	colorText       color.NRGBA
+	colorTextDim    color.NRGBA
+	colorAccent     color.NRGBA
+	colorSuccess    color.NRGBA
+	colorWarning    color.NRGBA
+	colorWarningBG  color.NRGBA
+	colorError      color.NRGBA
+	colorSelected   color.NRGBA
 )
 
+// usePalette switches the settings window colors to the given palette.
+func usePalette(p theme.Palette) {
+	colorBG = p.BG
+	colorPanel = p.Panel
+	colorPanelLight = p.PanelLight
+	colorButton = p.Button
+	colorOverlay = p.Overlay
+	colorText = p.Text
+	colorTextDim = p.TextDim
+	colorAccent = p.Accent
+	colorSuccess = p.Success
+	colorWarning = p.Warning
+	colorWarningBG = p.WarningBG
+	colorError = p.Error
+	colorSelected = p.Selected
+}
+
 func (w *Window) draw(gtx layout.Context) layout.Dimensions {
 	// Fill background
 	rect := clip.Rect{Max: gtx.Constraints.Max}
@@ -57,6 +81,27 @@ func (w *Window) draw(gtx layout.Context) layout.Dimensions {
 				th := material.NewTheme()
 				return material.List(th, &w.contentList).Layout(gtx, 1, func(gtx layout.Context, _ int) layout.Dimensions {
 					return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+						// First run call to action
+						layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+							if !w.isOnboarding() {
+								return layout.Dimensions{}
+							}
+							return layout.Inset{Bottom: unit.Dp(12)}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+								return w.drawOnboarding(gtx, downloading && progressModel == w.getOnboardModel())
+							})
+						}),
+
+						// Model that failed to load
+						layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+							modelID, msg := w.getModelError()
+							if modelID == "" {
+								return layout.Dimensions{}
+							}
+							return layout.Inset{Bottom: unit.Dp(12)}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+								return w.drawModelError(gtx, modelID, msg, downloading)
+							})
+						}),
+
 						// UI Language section
 						layout.Rigid(func(gtx layout.Context) layout.Dimensions {
 							return w.drawUILanguageSection(gtx)
@@ -64,6 +109,13 @@ func (w *Window) draw(gtx layout.Context) layout.Dimensions {
 
 						layout.Rigid(layout.Spacer{Height: unit.Dp(12)}.Layout),
 
+						// Theme section
+						layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+							return w.drawThemeSection(gtx)
+						}),
+
+						layout.Rigid(layout.Spacer{Height: unit.Dp(12)}.Layout),
+
 						// Hotkey section
 						layout.Rigid(func(gtx layout.Context) layout.Dimensions {
 							return w.drawHotkeySection(gtx)
@@ -78,6 +130,20 @@ func (w *Window) draw(gtx layout.Context) layout.Dimensions {
 
 						layout.Rigid(layout.Spacer{Height: unit.Dp(12)}.Layout),
 
+						// Microphone test section
+						layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+							return w.drawMicTestSection(gtx)
+						}),
+
+						layout.Rigid(layout.Spacer{Height: unit.Dp(12)}.Layout),
+
+						// Advanced section
+						layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+							return w.drawAdvancedSection(gtx)
+						}),
+
+						layout.Rigid(layout.Spacer{Height: unit.Dp(12)}.Layout),
+
 						// Recognition section (Engine + Model)
 						layout.Rigid(func(gtx layout.Context) layout.Dimensions {
 							return w.drawSectionHeader(gtx, i18n.T("settings_recognition"))
@@ -103,7 +169,13 @@ func (w *Window) draw(gtx layout.Context) layout.Dimensions {
 			// Progress bar (fixed, always visible when downloading)
 			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
 				if !downloading {
-					return layout.Dimensions{}
+					modelID, msg := w.getDownloadError()
+					if modelID == "" {
+						return layout.Dimensions{}
+					}
+					return layout.Inset{Top: unit.Dp(12), Bottom: unit.Dp(4)}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+						return w.drawDownloadError(gtx, modelID, msg)
+					})
 				}
 				return layout.Inset{Top: unit.Dp(12), Bottom: unit.Dp(4)}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
 					return w.drawProgressBar(gtx, progress, progressModel)
@@ -130,7 +202,7 @@ func (w *Window) draw(gtx layout.Context) layout.Dimensions {
 func (w *Window) drawLoadingOverlay(gtx layout.Context, modelID string) {
 	// Semi-transparent overlay
 	rect := clip.Rect{Max: gtx.Constraints.Max}
-	paint.FillShape(gtx.Ops, color.NRGBA{R: 20, G: 20, B: 24, A: 220}, rect.Op())
+	paint.FillShape(gtx.Ops, colorOverlay, rect.Op())
 
 	// Center content
 	layout.Center.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
@@ -219,6 +291,7 @@ func (w *Window) drawSectionHeader(gtx layout.Context, text string) layout.Dimen
 
 func (w *Window) drawHotkeySection(gtx layout.Context) layout.Dimensions {
 	isRecording := w.isRecordingHotkey()
+	mods, key := w.getHotkeyState()
 
 	return w.drawPanel(gtx, func(gtx layout.Context) layout.Dimensions {
 		return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
@@ -234,146 +307,945 @@ func (w *Window) drawHotkeySection(gtx layout.Context) layout.Dimensions {
 				return layout.Flex{Axis: layout.Horizontal, Alignment: layout.Middle}.Layout(gtx,
 					// Current hotkey preview
 					layout.Flexed(1, func(gtx layout.Context) layout.Dimensions {
-						return w.drawHotkeyPreview(gtx, isRecording)
+						return w.drawHotkeyPreview(gtx, isRecording, mods, key)
+					}),
+
+					layout.Rigid(layout.Spacer{Width: unit.Dp(12)}.Layout),
+
+					// Edit button
+					layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+						if isRecording {
+							return w.drawButton(gtx, &w.hotkeyEditBtn, i18n.T("settings_hotkey_cancel"), colorWarning, colorText, true)
+						}
+						return w.drawButton(gtx, &w.hotkeyEditBtn, i18n.T("settings_hotkey_edit"), colorAccent, colorText, true)
+					}),
+				)
+			}),
+
+			layout.Rigid(layout.Spacer{Height: unit.Dp(12)}.Layout),
+
+			// Presets
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				return w.drawHotkeyPresets(gtx)
+			}),
+
+			layout.Rigid(layout.Spacer{Height: unit.Dp(8)}.Layout),
+
+			// Save current hotkey as preset
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				return w.drawSavePreset(gtx)
+			}),
+
+			layout.Rigid(layout.Spacer{Height: unit.Dp(12)}.Layout),
+
+			// Cancel recording hotkey
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				return w.drawCancelHotkey(gtx)
+			}),
+		)
+	})
+}
+
+func (w *Window) drawCancelHotkey(gtx layout.Context) layout.Dimensions {
+	isRecording := w.isRecordingCancelHotkey()
+	mods, key := w.getCancelHotkeyState()
+
+	return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			th := material.NewTheme()
+			th.Palette.Fg = colorTextDim
+			return material.Label(th, unit.Sp(13), i18n.T("settings_cancel_hotkey")).Layout(gtx)
+		}),
+
+		layout.Rigid(layout.Spacer{Height: unit.Dp(6)}.Layout),
+
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			return layout.Flex{Axis: layout.Horizontal, Alignment: layout.Middle}.Layout(gtx,
+				layout.Flexed(1, func(gtx layout.Context) layout.Dimensions {
+					return w.drawHotkeyPreview(gtx, isRecording, mods, key)
+				}),
+
+				layout.Rigid(layout.Spacer{Width: unit.Dp(12)}.Layout),
+
+				layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+					if isRecording {
+						return w.drawButton(gtx, &w.cancelHotkeyEditBtn, i18n.T("settings_hotkey_cancel"), colorWarning, colorText, true)
+					}
+					return w.drawButton(gtx, &w.cancelHotkeyEditBtn, i18n.T("settings_hotkey_edit"), colorAccent, colorText, true)
+				}),
+			)
+		}),
+	)
+}
+
+func (w *Window) drawHotkeyPresets(gtx layout.Context) layout.Dimensions {
+	type presetItem struct {
+		id     string
+		preset config.HotkeyPreset
+	}
+
+	var items []presetItem
+	for _, p := range config.BuiltinHotkeyPresets() {
+		items = append(items, presetItem{id: "builtin:" + p.Name, preset: p})
+	}
+	for _, p := range w.config.HotkeyPresets() {
+		items = append(items, presetItem{id: "user:" + p.Name, preset: p})
+	}
+
+	mods, key := w.getHotkeyState()
+	current := buildHotkey(mods, key).String()
+
+	th := material.NewTheme()
+	return layout.Flex{Axis: layout.Horizontal, Alignment: layout.Middle}.Layout(gtx,
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			lbl := material.Label(th, unit.Sp(14), i18n.T("settings_hotkey_presets"))
+			lbl.Color = colorTextDim
+			return lbl.Layout(gtx)
+		}),
+		layout.Rigid(layout.Spacer{Width: unit.Dp(12)}.Layout),
+		layout.Flexed(1, func(gtx layout.Context) layout.Dimensions {
+			// Constrain height for horizontal list
+			gtx.Constraints.Max.Y = gtx.Dp(unit.Dp(32))
+			gtx.Constraints.Min.Y = gtx.Constraints.Max.Y
+			return material.List(th, &w.presetList).Layout(gtx, len(items), func(gtx layout.Context, i int) layout.Dimensions {
+				item := items[i]
+				return layout.Inset{Right: unit.Dp(4)}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+					return w.drawPresetButton(gtx, item.id, item.preset, item.preset.Hotkey.String() == current)
+				})
+			})
+		}),
+	)
+}
+
+func (w *Window) drawPresetButton(gtx layout.Context, id string, preset config.HotkeyPreset, selected bool) layout.Dimensions {
+	btn := w.getPresetButton(id)
+	if btn.Clicked(gtx) {
+		w.applyPreset(preset)
+	}
+
+	bgColor := colorButton
+	if selected {
+		bgColor = colorAccent
+	}
+
+	// Record content to measure size
+	macro := op.Record(gtx.Ops)
+	dims := material.Clickable(gtx, btn, func(gtx layout.Context) layout.Dimensions {
+		return layout.Inset{
+			Top: unit.Dp(6), Bottom: unit.Dp(6),
+			Left: unit.Dp(10), Right: unit.Dp(10),
+		}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+			th := material.NewTheme()
+			th.Palette.Fg = colorText
+			lbl := material.Label(th, unit.Sp(12), preset.Name)
+			lbl.Font.Weight = font.Medium
+			return lbl.Layout(gtx)
+		})
+	})
+	call := macro.Stop()
+
+	// Draw background
+	rr := gtx.Dp(unit.Dp(4))
+	rect := clip.RRect{
+		Rect: image.Rectangle{Max: dims.Size},
+		NE:   rr, NW: rr, SE: rr, SW: rr,
+	}
+	paint.FillShape(gtx.Ops, bgColor, rect.Op(gtx.Ops))
+
+	// Replay content
+	call.Add(gtx.Ops)
+
+	return dims
+}
+
+func (w *Window) drawSavePreset(gtx layout.Context) layout.Dimensions {
+	return layout.Flex{Axis: layout.Horizontal, Alignment: layout.Middle}.Layout(gtx,
+		// Preset name input
+		layout.Flexed(1, func(gtx layout.Context) layout.Dimensions {
+			return w.drawTextField(gtx, &w.presetNameEditor, i18n.T("settings_hotkey_preset_name"))
+		}),
+		layout.Rigid(layout.Spacer{Width: unit.Dp(12)}.Layout),
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			return w.drawButton(gtx, &w.savePresetBtn, i18n.T("settings_hotkey_preset_save"), colorPanelLight, colorText, true)
+		}),
+	)
+}
+
+// drawTextField draws a single-line editor on a rounded background.
+func (w *Window) drawTextField(gtx layout.Context, editor *widget.Editor, hint string) layout.Dimensions {
+	// Record content to measure size
+	macro := op.Record(gtx.Ops)
+	dims := layout.UniformInset(unit.Dp(8)).Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+		th := material.NewTheme()
+		ed := material.Editor(th, editor, hint)
+		ed.TextSize = unit.Sp(13)
+		ed.Color = colorText
+		ed.HintColor = colorTextDim
+		return ed.Layout(gtx)
+	})
+	call := macro.Stop()
+
+	// Draw background
+	rr := gtx.Dp(unit.Dp(6))
+	rect := clip.RRect{
+		Rect: image.Rectangle{Max: dims.Size},
+		NE:   rr, NW: rr, SE: rr, SW: rr,
+	}
+	paint.FillShape(gtx.Ops, colorPanelLight, rect.Op(gtx.Ops))
+
+	// Replay content
+	call.Add(gtx.Ops)
+
+	return dims
+}
+
+func (w *Window) drawUILanguageSection(gtx layout.Context) layout.Dimensions {
+	selectedLang := w.getSelectedUILang()
+
+	return w.drawPanel(gtx, func(gtx layout.Context) layout.Dimensions {
+		return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+			// Section header
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				return w.drawSectionHeader(gtx, i18n.T("settings_ui_language"))
+			}),
+
+			layout.Rigid(layout.Spacer{Height: unit.Dp(12)}.Layout),
+
+			// Language buttons
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				return layout.Flex{Axis: layout.Horizontal, Alignment: layout.Middle}.Layout(gtx,
+					layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+						return w.drawLangButton(gtx, i18n.RU, "Русский", selectedLang == i18n.RU)
+					}),
+					layout.Rigid(layout.Spacer{Width: unit.Dp(8)}.Layout),
+					layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+						return w.drawLangButton(gtx, i18n.EN, "English", selectedLang == i18n.EN)
 					}),
+				)
+			}),
+		)
+	})
+}
+
+func (w *Window) drawLangButton(gtx layout.Context, lang i18n.Language, label string, selected bool) layout.Dimensions {
+	return w.drawChoiceButton(gtx, w.getLangButton(lang), label, selected)
+}
+
+func (w *Window) drawThemeSection(gtx layout.Context) layout.Dimensions {
+	selectedTheme := theme.Name()
+
+	return w.drawPanel(gtx, func(gtx layout.Context) layout.Dimensions {
+		return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+			// Section header
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				return w.drawSectionHeader(gtx, i18n.T("settings_theme"))
+			}),
+
+			layout.Rigid(layout.Spacer{Height: unit.Dp(12)}.Layout),
+
+			// Theme buttons
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				names := theme.Names()
+				children := make([]layout.FlexChild, 0, len(names)*2)
+				for i, name := range names {
+					if i > 0 {
+						children = append(children, layout.Rigid(layout.Spacer{Width: unit.Dp(8)}.Layout))
+					}
+					children = append(children, layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+						return w.drawChoiceButton(gtx, w.getThemeButton(name), i18n.T("theme_"+name), selectedTheme == name)
+					}))
+				}
+				return layout.Flex{Axis: layout.Horizontal, Alignment: layout.Middle}.Layout(gtx, children...)
+			}),
+		)
+	})
+}
+
+// drawChoiceButton draws one option of a segmented selector.
+func (w *Window) drawChoiceButton(gtx layout.Context, btn *widget.Clickable, label string, selected bool) layout.Dimensions {
+	bgColor := colorPanel
+	textColor := colorTextDim
+	if selected {
+		bgColor = colorAccent
+		textColor = colorText
+	}
+
+	// Record content to measure size
+	macro := op.Record(gtx.Ops)
+	dims := material.Clickable(gtx, btn, func(gtx layout.Context) layout.Dimensions {
+		return layout.Inset{
+			Top: unit.Dp(8), Bottom: unit.Dp(8),
+			Left: unit.Dp(16), Right: unit.Dp(16),
+		}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+			th := material.NewTheme()
+			th.Palette.Fg = textColor
+			lbl := material.Label(th, unit.Sp(14), label)
+			lbl.Font.Weight = font.Medium
+			return lbl.Layout(gtx)
+		})
+	})
+	call := macro.Stop()
+
+	// Draw background
+	rr := gtx.Dp(unit.Dp(6))
+	rect := clip.RRect{
+		Rect: image.Rectangle{Max: dims.Size},
+		NE:   rr, NW: rr, SE: rr, SW: rr,
+	}
+	paint.FillShape(gtx.Ops, bgColor, rect.Op(gtx.Ops))
+
+	// Replay content
+	call.Add(gtx.Ops)
+
+	return dims
+}
+
+func (w *Window) drawLLMSection(gtx layout.Context) layout.Dimensions {
+	return w.drawPanel(gtx, func(gtx layout.Context) layout.Dimensions {
+		return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+			// Section header
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				return w.drawSectionHeader(gtx, i18n.T("settings_llm"))
+			}),
+
+			layout.Rigid(layout.Spacer{Height: unit.Dp(12)}.Layout),
+
+			// Toggle and description
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				return layout.Flex{Axis: layout.Horizontal, Alignment: layout.Middle}.Layout(gtx,
+					// Toggle
+					layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+						return w.drawToggle(gtx, &w.llmEnabled)
+					}),
+
+					layout.Rigid(layout.Spacer{Width: unit.Dp(12)}.Layout),
+
+					// Description
+					layout.Flexed(1, func(gtx layout.Context) layout.Dimensions {
+						return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+							layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+								th := material.NewTheme()
+								th.Palette.Fg = colorText
+								lbl := material.Label(th, unit.Sp(14), i18n.T("settings_llm_enable"))
+								lbl.Font.Weight = font.Medium
+								return lbl.Layout(gtx)
+							}),
+							layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+								th := material.NewTheme()
+								th.Palette.Fg = colorTextDim
+								lbl := material.Label(th, unit.Sp(11), i18n.T("settings_llm_hint"))
+								return lbl.Layout(gtx)
+							}),
+						)
+					}),
+				)
+			}),
+
+			// LLM context size (if LLM enabled)
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				if !w.llmEnabled.Value {
+					return layout.Dimensions{}
+				}
+				return layout.Inset{Top: unit.Dp(12)}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+					value := fmt.Sprintf("%d %s", w.getContextSize(), i18n.T("unit_tokens"))
+					return w.drawStepper(gtx, i18n.T("settings_llm_context"), value, &w.ctxDecBtn, &w.ctxIncBtn)
+				})
+			}),
+
+			// LLM token sampler (if LLM enabled)
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				if !w.llmEnabled.Value {
+					return layout.Dimensions{}
+				}
+				return layout.Inset{Top: unit.Dp(12)}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+					return w.drawSamplerChoice(gtx)
+				})
+			}),
+
+			// LLM model list (if LLM enabled)
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				if !w.llmEnabled.Value {
+					return layout.Dimensions{}
+				}
+				return layout.Inset{Top: unit.Dp(12)}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+					return w.drawLLMModelList(gtx)
+				})
+			}),
+		)
+	})
+}
+
+// drawSamplerChoice draws the greedy/balanced switch for LLM correction.
+func (w *Window) drawSamplerChoice(gtx layout.Context) layout.Dimensions {
+	return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			th := material.NewTheme()
+			th.Palette.Fg = colorText
+			return material.Label(th, unit.Sp(14), i18n.T("settings_llm_sampler")).Layout(gtx)
+		}),
+
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			th := material.NewTheme()
+			th.Palette.Fg = colorTextDim
+			return material.Label(th, unit.Sp(11), i18n.T("settings_llm_sampler_hint")).Layout(gtx)
+		}),
+
+		layout.Rigid(layout.Spacer{Height: unit.Dp(6)}.Layout),
+
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			selected := w.getLLMSampler()
+			samplers := config.LLMSamplers()
+			children := make([]layout.FlexChild, 0, len(samplers)*2)
+			for i, sampler := range samplers {
+				if i > 0 {
+					children = append(children, layout.Rigid(layout.Spacer{Width: unit.Dp(8)}.Layout))
+				}
+				children = append(children, layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+					return w.drawChoiceButton(gtx, w.getSamplerButton(sampler), i18n.T("sampler_"+string(sampler)), selected == sampler)
+				}))
+			}
+			return layout.Flex{Axis: layout.Horizontal, Alignment: layout.Middle}.Layout(gtx, children...)
+		}),
+	)
+}
+
+func (w *Window) drawMicTestSection(gtx layout.Context) layout.Dimensions {
+	testing, samples, remaining := w.getMicTestState()
+
+	return w.drawPanel(gtx, func(gtx layout.Context) layout.Dimensions {
+		return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+			// Section header
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				return w.drawSectionHeader(gtx, i18n.T("settings_mic_test"))
+			}),
+
+			layout.Rigid(layout.Spacer{Height: unit.Dp(12)}.Layout),
+
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				return layout.Flex{Axis: layout.Horizontal, Alignment: layout.Middle}.Layout(gtx,
+					// Level meter (same widget as in the recording window)
+					layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+						size := image.Pt(gtx.Dp(unit.Dp(12)), gtx.Dp(unit.Dp(40)))
+						gtx.Constraints = layout.Exact(size)
+						return waveform.DrawVolumeBar(gtx, samples, waveform.DefaultConfig())
+					}),
+
+					layout.Rigid(layout.Spacer{Width: unit.Dp(12)}.Layout),
+
+					// Status
+					layout.Flexed(1, func(gtx layout.Context) layout.Dimensions {
+						th := material.NewTheme()
+						th.Palette.Fg = colorTextDim
+						text := i18n.T("settings_mic_test_hint")
+						if testing {
+							th.Palette.Fg = colorText
+							secs := int(math.Ceil(remaining.Seconds()))
+							text = fmt.Sprintf("%s (%d %s)", i18n.T("settings_mic_test_speak"), secs, i18n.T("unit_sec"))
+						}
+						return material.Label(th, unit.Sp(12), text).Layout(gtx)
+					}),
+
+					layout.Rigid(layout.Spacer{Width: unit.Dp(12)}.Layout),
+
+					// Start/stop button
+					layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+						if testing {
+							return w.drawButton(gtx, &w.micTestBtn, i18n.T("settings_mic_test_stop"), colorWarning, colorText, true)
+						}
+						return w.drawButton(gtx, &w.micTestBtn, i18n.T("settings_mic_test_start"), colorAccent, colorText, true)
+					}),
+				)
+			}),
+		)
+	})
+}
+
+func (w *Window) drawAdvancedSection(gtx layout.Context) layout.Dimensions {
+	return w.drawPanel(gtx, func(gtx layout.Context) layout.Dimensions {
+		return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+			// Section header
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				return w.drawSectionHeader(gtx, i18n.T("settings_advanced"))
+			}),
+
+			layout.Rigid(layout.Spacer{Height: unit.Dp(12)}.Layout),
+
+			// Insert delay
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				value := fmt.Sprintf("%d %s", w.getInsertDelay(), i18n.T("unit_ms"))
+				return w.drawStepper(gtx, i18n.T("settings_insert_delay"), value, &w.delayDecBtn, &w.delayIncBtn)
+			}),
+
+			layout.Rigid(layout.Spacer{Height: unit.Dp(8)}.Layout),
+
+			// Minimum recording duration
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				value := fmt.Sprintf("%d %s", w.getMinRecording(), i18n.T("unit_ms"))
+				return w.drawStepper(gtx, i18n.T("settings_min_recording"), value, &w.minRecDecBtn, &w.minRecIncBtn)
+			}),
+
+			layout.Rigid(layout.Spacer{Height: unit.Dp(8)}.Layout),
+
+			// Recognition threads
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				value := i18n.T("settings_threads_auto")
+				if n := w.getThreads(); n > 0 {
+					value = fmt.Sprintf("%d", n)
+				}
+				return w.drawStepper(gtx, i18n.T("settings_threads"), value, &w.threadsDecBtn, &w.threadsIncBtn)
+			}),
+
+			layout.Rigid(layout.Spacer{Height: unit.Dp(12)}.Layout),
+
+			// Express: no recording window, no insert delay
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				return layout.Flex{Axis: layout.Horizontal, Alignment: layout.Middle}.Layout(gtx,
+					layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+						return w.drawToggle(gtx, &w.express)
+					}),
+
+					layout.Rigid(layout.Spacer{Width: unit.Dp(12)}.Layout),
+
+					layout.Flexed(1, func(gtx layout.Context) layout.Dimensions {
+						return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+							layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+								th := material.NewTheme()
+								th.Palette.Fg = colorText
+								return material.Label(th, unit.Sp(14), i18n.T("settings_express")).Layout(gtx)
+							}),
+							layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+								th := material.NewTheme()
+								th.Palette.Fg = colorTextDim
+								return material.Label(th, unit.Sp(11), i18n.T("settings_express_hint")).Layout(gtx)
+							}),
+						)
+					}),
+				)
+			}),
+
+			layout.Rigid(layout.Spacer{Height: unit.Dp(12)}.Layout),
+
+			// Dictation mode
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				return layout.Flex{Axis: layout.Horizontal, Alignment: layout.Middle}.Layout(gtx,
+					layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+						return w.drawToggle(gtx, &w.dictationMode)
+					}),
+
+					layout.Rigid(layout.Spacer{Width: unit.Dp(12)}.Layout),
+
+					layout.Flexed(1, func(gtx layout.Context) layout.Dimensions {
+						return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+							layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+								th := material.NewTheme()
+								th.Palette.Fg = colorText
+								return material.Label(th, unit.Sp(14), i18n.T("settings_dictation")).Layout(gtx)
+							}),
+							layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+								th := material.NewTheme()
+								th.Palette.Fg = colorTextDim
+								return material.Label(th, unit.Sp(11), i18n.T("settings_dictation_hint")).Layout(gtx)
+							}),
+						)
+					}),
+				)
+			}),
+
+			layout.Rigid(layout.Spacer{Height: unit.Dp(12)}.Layout),
+
+			// Sound cues
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				return layout.Flex{Axis: layout.Horizontal, Alignment: layout.Middle}.Layout(gtx,
+					layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+						return w.drawToggle(gtx, &w.soundCues)
+					}),
+
+					layout.Rigid(layout.Spacer{Width: unit.Dp(12)}.Layout),
+
+					layout.Flexed(1, func(gtx layout.Context) layout.Dimensions {
+						return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+							layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+								th := material.NewTheme()
+								th.Palette.Fg = colorText
+								return material.Label(th, unit.Sp(14), i18n.T("settings_sounds")).Layout(gtx)
+							}),
+							layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+								th := material.NewTheme()
+								th.Palette.Fg = colorTextDim
+								return material.Label(th, unit.Sp(11), i18n.T("settings_sounds_hint")).Layout(gtx)
+							}),
+						)
+					}),
+				)
+			}),
+
+			layout.Rigid(layout.Spacer{Height: unit.Dp(12)}.Layout),
+
+			// Trim silence before recognition
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				return layout.Flex{Axis: layout.Horizontal, Alignment: layout.Middle}.Layout(gtx,
+					layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+						return w.drawToggle(gtx, &w.trimSilence)
+					}),
+
+					layout.Rigid(layout.Spacer{Width: unit.Dp(12)}.Layout),
+
+					layout.Flexed(1, func(gtx layout.Context) layout.Dimensions {
+						return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+							layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+								th := material.NewTheme()
+								th.Palette.Fg = colorText
+								return material.Label(th, unit.Sp(14), i18n.T("settings_trim_silence")).Layout(gtx)
+							}),
+							layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+								th := material.NewTheme()
+								th.Palette.Fg = colorTextDim
+								return material.Label(th, unit.Sp(11), i18n.T("settings_trim_silence_hint")).Layout(gtx)
+							}),
+						)
+					}),
+				)
+			}),
+
+			layout.Rigid(layout.Spacer{Height: unit.Dp(12)}.Layout),
+
+			// Reduce background noise before recognition
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				return layout.Flex{Axis: layout.Horizontal, Alignment: layout.Middle}.Layout(gtx,
+					layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+						return w.drawToggle(gtx, &w.denoise)
+					}),
+
+					layout.Rigid(layout.Spacer{Width: unit.Dp(12)}.Layout),
+
+					layout.Flexed(1, func(gtx layout.Context) layout.Dimensions {
+						return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+							layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+								th := material.NewTheme()
+								th.Palette.Fg = colorText
+								return material.Label(th, unit.Sp(14), i18n.T("settings_denoise")).Layout(gtx)
+							}),
+							layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+								th := material.NewTheme()
+								th.Palette.Fg = colorTextDim
+								return material.Label(th, unit.Sp(11), i18n.T("settings_denoise_hint")).Layout(gtx)
+							}),
+						)
+					}),
+				)
+			}),
+
+			layout.Rigid(layout.Spacer{Height: unit.Dp(12)}.Layout),
+
+			// Retry empty recognition with the fallback language
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				return layout.Flex{Axis: layout.Horizontal, Alignment: layout.Middle}.Layout(gtx,
+					layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+						return w.drawToggle(gtx, &w.retryOnEmpty)
+					}),
+
+					layout.Rigid(layout.Spacer{Width: unit.Dp(12)}.Layout),
+
+					layout.Flexed(1, func(gtx layout.Context) layout.Dimensions {
+						return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+							layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+								th := material.NewTheme()
+								th.Palette.Fg = colorText
+								return material.Label(th, unit.Sp(14), i18n.T("settings_retry_empty")).Layout(gtx)
+							}),
+							layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+								th := material.NewTheme()
+								th.Palette.Fg = colorTextDim
+								return material.Label(th, unit.Sp(11), i18n.T("settings_retry_empty_hint")).Layout(gtx)
+							}),
+						)
+					}),
+				)
+			}),
+
+			layout.Rigid(layout.Spacer{Height: unit.Dp(12)}.Layout),
+
+			// Capitalization and final period for Vosk results
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				return layout.Flex{Axis: layout.Horizontal, Alignment: layout.Middle}.Layout(gtx,
+					layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+						return w.drawToggle(gtx, &w.voskPunct)
+					}),
+
+					layout.Rigid(layout.Spacer{Width: unit.Dp(12)}.Layout),
+
+					layout.Flexed(1, func(gtx layout.Context) layout.Dimensions {
+						return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+							layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+								th := material.NewTheme()
+								th.Palette.Fg = colorText
+								return material.Label(th, unit.Sp(14), i18n.T("settings_vosk_punct")).Layout(gtx)
+							}),
+							layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+								th := material.NewTheme()
+								th.Palette.Fg = colorTextDim
+								return material.Label(th, unit.Sp(11), i18n.T("settings_vosk_punct_hint")).Layout(gtx)
+							}),
+						)
+					}),
+				)
+			}),
+
+			layout.Rigid(layout.Spacer{Height: unit.Dp(12)}.Layout),
+
+			// Live partial text while recording (Vosk)
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				return layout.Flex{Axis: layout.Horizontal, Alignment: layout.Middle}.Layout(gtx,
+					layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+						return w.drawToggle(gtx, &w.partials)
+					}),
+
+					layout.Rigid(layout.Spacer{Width: unit.Dp(12)}.Layout),
+
+					layout.Flexed(1, func(gtx layout.Context) layout.Dimensions {
+						return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+							layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+								th := material.NewTheme()
+								th.Palette.Fg = colorText
+								return material.Label(th, unit.Sp(14), i18n.T("settings_partials")).Layout(gtx)
+							}),
+							layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+								th := material.NewTheme()
+								th.Palette.Fg = colorTextDim
+								return material.Label(th, unit.Sp(11), i18n.T("settings_partials_hint")).Layout(gtx)
+							}),
+						)
+					}),
+				)
+			}),
+
+			layout.Rigid(layout.Spacer{Height: unit.Dp(12)}.Layout),
+
+			// Click-through recording window
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				return layout.Flex{Axis: layout.Horizontal, Alignment: layout.Middle}.Layout(gtx,
+					layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+						return w.drawToggle(gtx, &w.clickThrough)
+					}),
+
+					layout.Rigid(layout.Spacer{Width: unit.Dp(12)}.Layout),
+
+					layout.Flexed(1, func(gtx layout.Context) layout.Dimensions {
+						return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+							layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+								th := material.NewTheme()
+								th.Palette.Fg = colorText
+								return material.Label(th, unit.Sp(14), i18n.T("settings_click_through")).Layout(gtx)
+							}),
+							layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+								th := material.NewTheme()
+								th.Palette.Fg = colorTextDim
+								return material.Label(th, unit.Sp(11), i18n.T("settings_click_through_hint")).Layout(gtx)
+							}),
+						)
+					}),
+				)
+			}),
+
+			layout.Rigid(layout.Spacer{Height: unit.Dp(12)}.Layout),
+
+			// Newline handling for insert and copy
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				th := material.NewTheme()
+				th.Palette.Fg = colorText
+				return material.Label(th, unit.Sp(14), i18n.T("settings_newlines")).Layout(gtx)
+			}),
+
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				th := material.NewTheme()
+				th.Palette.Fg = colorTextDim
+				return material.Label(th, unit.Sp(11), i18n.T("settings_newlines_hint")).Layout(gtx)
+			}),
+
+			layout.Rigid(layout.Spacer{Height: unit.Dp(6)}.Layout),
+
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				selected := w.getNewlineHandling()
+				modes := config.NewlineModes()
+				children := make([]layout.FlexChild, 0, len(modes)*2)
+				for i, mode := range modes {
+					if i > 0 {
+						children = append(children, layout.Rigid(layout.Spacer{Width: unit.Dp(8)}.Layout))
+					}
+					children = append(children, layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+						return w.drawChoiceButton(gtx, w.getNewlineButton(mode), i18n.T("newline_"+string(mode)), selected == mode)
+					}))
+				}
+				return layout.Flex{Axis: layout.Horizontal, Alignment: layout.Middle}.Layout(gtx, children...)
+			}),
+
+			layout.Rigid(layout.Spacer{Height: unit.Dp(12)}.Layout),
+
+			// Notification style
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				th := material.NewTheme()
+				th.Palette.Fg = colorText
+				return material.Label(th, unit.Sp(14), i18n.T("settings_notify_style")).Layout(gtx)
+			}),
+
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				th := material.NewTheme()
+				th.Palette.Fg = colorTextDim
+				return material.Label(th, unit.Sp(11), i18n.T("settings_notify_hint")).Layout(gtx)
+			}),
+
+			layout.Rigid(layout.Spacer{Height: unit.Dp(6)}.Layout),
+
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				selected := w.getNotifyStyle()
+				styles := config.NotificationStyles()
+				children := make([]layout.FlexChild, 0, len(styles)*2)
+				for i, style := range styles {
+					if i > 0 {
+						children = append(children, layout.Rigid(layout.Spacer{Width: unit.Dp(8)}.Layout))
+					}
+					children = append(children, layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+						return w.drawChoiceButton(gtx, w.getNotifyStyleButton(style), i18n.T("notify_style_"+string(style)), selected == style)
+					}))
+				}
+				return layout.Flex{Axis: layout.Horizontal, Alignment: layout.Middle}.Layout(gtx, children...)
+			}),
+
+			layout.Rigid(layout.Spacer{Height: unit.Dp(12)}.Layout),
+
+			// Whisper recognition preset
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				th := material.NewTheme()
+				th.Palette.Fg = colorText
+				return material.Label(th, unit.Sp(14), i18n.T("settings_preset")).Layout(gtx)
+			}),
+
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				th := material.NewTheme()
+				th.Palette.Fg = colorTextDim
+				return material.Label(th, unit.Sp(11), i18n.T("settings_preset_hint")).Layout(gtx)
+			}),
+
+			layout.Rigid(layout.Spacer{Height: unit.Dp(6)}.Layout),
+
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				selected := w.getRecPreset()
+				presets := config.RecognitionPresets()
+				children := make([]layout.FlexChild, 0, len(presets)*2)
+				for i, preset := range presets {
+					if i > 0 {
+						children = append(children, layout.Rigid(layout.Spacer{Width: unit.Dp(8)}.Layout))
+					}
+					children = append(children, layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+						return w.drawChoiceButton(gtx, w.getRecPresetButton(preset), i18n.T("preset_"+string(preset)), selected == preset)
+					}))
+				}
+				return layout.Flex{Axis: layout.Horizontal, Alignment: layout.Middle}.Layout(gtx, children...)
+			}),
+
+			layout.Rigid(layout.Spacer{Height: unit.Dp(12)}.Layout),
+
+			// Whisper initial prompt
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				th := material.NewTheme()
+				th.Palette.Fg = colorText
+				return material.Label(th, unit.Sp(14), i18n.T("settings_prompt")).Layout(gtx)
+			}),
+
+			layout.Rigid(layout.Spacer{Height: unit.Dp(6)}.Layout),
+
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				return w.drawTextField(gtx, &w.promptEditor, i18n.T("settings_prompt_hint"))
+			}),
 
-					layout.Rigid(layout.Spacer{Width: unit.Dp(12)}.Layout),
+			layout.Rigid(layout.Spacer{Height: unit.Dp(12)}.Layout),
 
-					// Edit button
-					layout.Rigid(func(gtx layout.Context) layout.Dimensions {
-						if isRecording {
-							return w.drawButton(gtx, &w.hotkeyEditBtn, i18n.T("settings_hotkey_cancel"), colorWarning, colorText, true)
-						}
-						return w.drawButton(gtx, &w.hotkeyEditBtn, i18n.T("settings_hotkey_edit"), colorAccent, colorText, true)
-					}),
-				)
+			// Vosk grammar file
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				th := material.NewTheme()
+				th.Palette.Fg = colorText
+				return material.Label(th, unit.Sp(14), i18n.T("settings_grammar")).Layout(gtx)
 			}),
-		)
-	})
-}
 
-func (w *Window) drawUILanguageSection(gtx layout.Context) layout.Dimensions {
-	selectedLang := w.getSelectedUILang()
+			layout.Rigid(layout.Spacer{Height: unit.Dp(6)}.Layout),
 
-	return w.drawPanel(gtx, func(gtx layout.Context) layout.Dimensions {
-		return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
-			// Section header
 			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
-				return w.drawSectionHeader(gtx, i18n.T("settings_ui_language"))
+				return w.drawTextField(gtx, &w.grammarEditor, i18n.T("settings_grammar_hint"))
 			}),
 
 			layout.Rigid(layout.Spacer{Height: unit.Dp(12)}.Layout),
 
-			// Language buttons
+			// Proxy for model downloads
 			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
-				return layout.Flex{Axis: layout.Horizontal, Alignment: layout.Middle}.Layout(gtx,
-					layout.Rigid(func(gtx layout.Context) layout.Dimensions {
-						return w.drawLangButton(gtx, i18n.RU, "Русский", selectedLang == i18n.RU)
-					}),
-					layout.Rigid(layout.Spacer{Width: unit.Dp(8)}.Layout),
-					layout.Rigid(func(gtx layout.Context) layout.Dimensions {
-						return w.drawLangButton(gtx, i18n.EN, "English", selectedLang == i18n.EN)
-					}),
-				)
+				th := material.NewTheme()
+				th.Palette.Fg = colorText
+				return material.Label(th, unit.Sp(14), i18n.T("settings_proxy")).Layout(gtx)
+			}),
+
+			layout.Rigid(layout.Spacer{Height: unit.Dp(6)}.Layout),
+
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				return w.drawTextField(gtx, &w.proxyEditor, i18n.T("settings_proxy_hint"))
 			}),
 		)
 	})
 }
 
-func (w *Window) drawLangButton(gtx layout.Context, lang i18n.Language, label string, selected bool) layout.Dimensions {
-	btn := w.getLangButton(lang)
-
-	bgColor := colorPanel
-	textColor := colorTextDim
-	if selected {
-		bgColor = colorAccent
-		textColor = colorText
-	}
+// drawStepper draws a labeled value with "−" and "+" buttons.
+func (w *Window) drawStepper(gtx layout.Context, label, value string, decBtn, incBtn *widget.Clickable) layout.Dimensions {
+	return layout.Flex{Axis: layout.Horizontal, Alignment: layout.Middle}.Layout(gtx,
+		layout.Flexed(1, func(gtx layout.Context) layout.Dimensions {
+			th := material.NewTheme()
+			th.Palette.Fg = colorText
+			lbl := material.Label(th, unit.Sp(14), label)
+			return lbl.Layout(gtx)
+		}),
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			return w.drawSmallButton(gtx, decBtn, "−")
+		}),
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			gtx.Constraints.Min.X = gtx.Dp(unit.Dp(72))
+			return layout.Center.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+				th := material.NewTheme()
+				th.Palette.Fg = colorAccent
+				lbl := material.Label(th, unit.Sp(14), value)
+				lbl.Font.Weight = font.Medium
+				return lbl.Layout(gtx)
+			})
+		}),
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			return w.drawSmallButton(gtx, incBtn, "+")
+		}),
+	)
+}
 
-	// Record content to measure size
+func (w *Window) drawSmallButton(gtx layout.Context, btn *widget.Clickable, text string) layout.Dimensions {
 	macro := op.Record(gtx.Ops)
 	dims := material.Clickable(gtx, btn, func(gtx layout.Context) layout.Dimensions {
 		return layout.Inset{
-			Top: unit.Dp(8), Bottom: unit.Dp(8),
-			Left: unit.Dp(16), Right: unit.Dp(16),
+			Top: unit.Dp(4), Bottom: unit.Dp(4),
+			Left: unit.Dp(10), Right: unit.Dp(10),
 		}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
 			th := material.NewTheme()
-			th.Palette.Fg = textColor
-			lbl := material.Label(th, unit.Sp(14), label)
-			lbl.Font.Weight = font.Medium
+			th.Palette.Fg = colorText
+			lbl := material.Label(th, unit.Sp(14), text)
+			lbl.Font.Weight = font.Bold
 			return lbl.Layout(gtx)
 		})
 	})
 	call := macro.Stop()
 
-	// Draw background
-	rr := gtx.Dp(unit.Dp(6))
+	rr := gtx.Dp(unit.Dp(4))
 	rect := clip.RRect{
 		Rect: image.Rectangle{Max: dims.Size},
 		NE:   rr, NW: rr, SE: rr, SW: rr,
 	}
-	paint.FillShape(gtx.Ops, bgColor, rect.Op(gtx.Ops))
+	paint.FillShape(gtx.Ops, colorPanelLight, rect.Op(gtx.Ops))
 
-	// Replay content
 	call.Add(gtx.Ops)
-
 	return dims
 }
 
-func (w *Window) drawLLMSection(gtx layout.Context) layout.Dimensions {
-	return w.drawPanel(gtx, func(gtx layout.Context) layout.Dimensions {
-		return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
-			// Section header
-			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
-				return w.drawSectionHeader(gtx, i18n.T("settings_llm"))
-			}),
-
-			layout.Rigid(layout.Spacer{Height: unit.Dp(12)}.Layout),
-
-			// Toggle and description
-			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
-				return layout.Flex{Axis: layout.Horizontal, Alignment: layout.Middle}.Layout(gtx,
-					// Toggle
-					layout.Rigid(func(gtx layout.Context) layout.Dimensions {
-						return w.drawToggle(gtx, &w.llmEnabled)
-					}),
-
-					layout.Rigid(layout.Spacer{Width: unit.Dp(12)}.Layout),
-
-					// Description
-					layout.Flexed(1, func(gtx layout.Context) layout.Dimensions {
-						return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
-							layout.Rigid(func(gtx layout.Context) layout.Dimensions {
-								th := material.NewTheme()
-								th.Palette.Fg = colorText
-								lbl := material.Label(th, unit.Sp(14), i18n.T("settings_llm_enable"))
-								lbl.Font.Weight = font.Medium
-								return lbl.Layout(gtx)
-							}),
-							layout.Rigid(func(gtx layout.Context) layout.Dimensions {
-								th := material.NewTheme()
-								th.Palette.Fg = colorTextDim
-								lbl := material.Label(th, unit.Sp(11), i18n.T("settings_llm_hint"))
-								return lbl.Layout(gtx)
-							}),
-						)
-					}),
-				)
-			}),
-
-			// LLM model list (if LLM enabled)
-			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
-				if !w.llmEnabled.Value {
-					return layout.Dimensions{}
-				}
-				return layout.Inset{Top: unit.Dp(12)}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
-					return w.drawLLMModelList(gtx)
-				})
-			}),
-		)
-	})
-}
-
 func (w *Window) drawLLMModelList(gtx layout.Context) layout.Dimensions {
 	llmModels := models.GetLLMModels()
 	selectedLLM := w.config.LLMModelID()
@@ -435,17 +1307,12 @@ func (w *Window) drawLLMModelItem(gtx layout.Context, m models.ModelInfo, select
 				layout.Flexed(1, func(gtx layout.Context) layout.Dimensions {
 					return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
 						layout.Rigid(func(gtx layout.Context) layout.Dimensions {
-							th := material.NewTheme()
-							th.Palette.Fg = colorText
-							lbl := material.Label(th, unit.Sp(13), m.Name)
-							lbl.Font.Weight = font.Medium
-							return lbl.Layout(gtx)
+							return w.drawModelName(gtx, m, unit.Sp(13))
 						}),
 						layout.Rigid(func(gtx layout.Context) layout.Dimensions {
 							th := material.NewTheme()
 							th.Palette.Fg = colorTextDim
-							size := formatSize(m.Size)
-							lbl := material.Label(th, unit.Sp(10), size)
+							lbl := material.Label(th, unit.Sp(10), modelDetails(m))
 							return lbl.Layout(gtx)
 						}),
 					)
@@ -453,10 +1320,13 @@ func (w *Window) drawLLMModelItem(gtx layout.Context, m models.ModelInfo, select
 
 				// Status badge or download button
 				layout.Rigid(func(gtx layout.Context) layout.Dimensions {
-					if isDownloaded {
-						return w.drawStatusBadge(gtx, "✓", colorSuccess)
+					if !isDownloaded {
+						return w.drawDownloadButton(gtx, downloadBtn, "↓")
+					}
+					if w.manager.IsOutdated(m.ID) {
+						return w.drawDownloadButton(gtx, downloadBtn, "↻")
 					}
-					return w.drawDownloadButton(gtx, downloadBtn)
+					return w.drawStatusBadge(gtx, "✓", colorSuccess)
 				}),
 			)
 		})
@@ -488,7 +1358,8 @@ func (w *Window) drawToggle(gtx layout.Context, toggle *widget.Bool) layout.Dime
 	return sw.Layout(gtx)
 }
 
-func (w *Window) drawHotkeyPreview(gtx layout.Context, isRecording bool) layout.Dimensions {
+// drawHotkeyPreview shows the combination being recorded, or mods+key when not recording.
+func (w *Window) drawHotkeyPreview(gtx layout.Context, isRecording bool, mods map[config.Modifier]bool, key config.Key) layout.Dimensions {
 	var hotkeyStr string
 	var textColor color.NRGBA
 	var bgColor color.NRGBA
@@ -510,10 +1381,9 @@ func (w *Window) drawHotkeyPreview(gtx layout.Context, isRecording bool) layout.
 			hotkeyStr = i18n.T("settings_hotkey_prompt")
 		}
 		textColor = colorWarning
-		bgColor = color.NRGBA{R: 80, G: 60, B: 20, A: 255}
+		bgColor = colorWarningBG
 	} else {
 		// Show current hotkey
-		mods, key := w.getHotkeyState()
 		parts := buildHotkeyParts(mods, key)
 
 		if len(parts) > 0 {
@@ -588,6 +1458,8 @@ func keyDisplayName(key config.Key) string {
 		return "Enter"
 	case config.KeyTab:
 		return "Tab"
+	case config.KeyEscape:
+		return "Esc"
 	case config.KeyF1:
 		return "F1"
 	case config.KeyF2:
@@ -598,7 +1470,26 @@ func keyDisplayName(key config.Key) string {
 		return "F4"
 	case config.KeyF5:
 		return "F5"
+	case config.KeyF6:
+		return "F6"
+	case config.KeyF7:
+		return "F7"
+	case config.KeyF8:
+		return "F8"
+	case config.KeyF9:
+		return "F9"
+	case config.KeyF10:
+		return "F10"
+	case config.KeyF11:
+		return "F11"
+	case config.KeyF12:
+		return "F12"
 	default:
+		for name, k := range punctuationKeys {
+			if k == key {
+				return string(name)
+			}
+		}
 		if key != "" {
 			return string(key)
 		}
@@ -633,6 +1524,27 @@ func (w *Window) drawKeySelector(gtx layout.Context) layout.Dimensions {
 		}{config.Key(c), string(c - 32)}) // uppercase display
 	}
 
+	// Add digit keys 0-9
+	for c := '0'; c <= '9'; c++ {
+		keys = append(keys, struct {
+			key   config.Key
+			label string
+		}{config.Key(c), string(c)})
+	}
+
+	// Add punctuation keys
+	for _, k := range []config.Key{
+		config.KeyGrave, config.KeyMinus, config.KeyEqual,
+		config.KeyBracketLeft, config.KeyBracketRight, config.KeySemicolon,
+		config.KeyApostrophe, config.KeyComma, config.KeyPeriod,
+		config.KeySlash, config.KeyBackslash,
+	} {
+		keys = append(keys, struct {
+			key   config.Key
+			label string
+		}{k, keyDisplayName(k)})
+	}
+
 	return layout.Flex{Axis: layout.Horizontal, Alignment: layout.Middle}.Layout(gtx,
 		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
 			lbl := material.Label(th, unit.Sp(14), i18n.T("settings_key"))
@@ -667,7 +1579,7 @@ func (w *Window) drawKeyButton(gtx layout.Context, key config.Key, label string)
 	}
 
 	// Button colors - improved contrast
-	bgColor := color.NRGBA{R: 70, G: 70, B: 78, A: 255}
+	bgColor := colorButton
 	textColor := colorText
 	if isSelected {
 		bgColor = colorAccent
@@ -740,10 +1652,7 @@ func (w *Window) drawEngineButton(gtx layout.Context, engine models.Engine, labe
 	if btn.Clicked(gtx) {
 		w.engineEnum.Value = string(engine)
 		w.mu.Lock()
-		if w.selectedEngine != engine {
-			w.selectedEngine = engine
-			w.selectedModel = ""
-		}
+		w.selectEngine(engine)
 		w.mu.Unlock()
 	}
 
@@ -794,6 +1703,98 @@ func (w *Window) getEngineButton(engine models.Engine) *widget.Clickable {
 	return w.engineButtons[engine]
 }
 
+// drawOnboarding draws the first run banner: a panel outlined in the accent
+// color with a button that downloads the recommended model.
+func (w *Window) drawOnboarding(gtx layout.Context, downloading bool) layout.Dimensions {
+	return w.drawOutlinedPanel(gtx, colorAccent, func(gtx layout.Context) layout.Dimensions {
+		return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				th := material.NewTheme()
+				th.Palette.Fg = colorText
+				label := material.Label(th, unit.Sp(16), i18n.T("onboarding_title"))
+				label.Font.Weight = font.Bold
+				return label.Layout(gtx)
+			}),
+
+			layout.Rigid(layout.Spacer{Height: unit.Dp(6)}.Layout),
+
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				th := material.NewTheme()
+				th.Palette.Fg = colorTextDim
+				info, _ := models.GetModel(w.getOnboardModel())
+				return material.Label(th, unit.Sp(12), fmt.Sprintf(i18n.T("onboarding_hint"), info.Name)).Layout(gtx)
+			}),
+
+			layout.Rigid(layout.Spacer{Height: unit.Dp(12)}.Layout),
+
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				if downloading {
+					return w.drawButton(gtx, &w.onboardingBtn, i18n.T("settings_downloading"), colorPanelLight, colorTextDim, false)
+				}
+				return w.drawButton(gtx, &w.onboardingBtn, i18n.T("onboarding_download"), colorAccent, colorText, true)
+			}),
+		)
+	})
+}
+
+// drawModelError shows why a model failed to load and offers to download it again.
+func (w *Window) drawModelError(gtx layout.Context, modelID, msg string, downloading bool) layout.Dimensions {
+	name := modelID
+	if info, ok := models.GetModel(modelID); ok {
+		name = info.Name
+	}
+
+	return w.drawOutlinedPanel(gtx, colorError, func(gtx layout.Context) layout.Dimensions {
+		return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				th := material.NewTheme()
+				th.Palette.Fg = colorError
+				label := material.Label(th, unit.Sp(14), fmt.Sprintf(i18n.T("settings_model_error"), name))
+				label.Font.Weight = font.Bold
+				return label.Layout(gtx)
+			}),
+
+			layout.Rigid(layout.Spacer{Height: unit.Dp(6)}.Layout),
+
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				th := material.NewTheme()
+				th.Palette.Fg = colorTextDim
+				label := material.Label(th, unit.Sp(12), msg)
+				label.MaxLines = 3
+				return label.Layout(gtx)
+			}),
+
+			layout.Rigid(layout.Spacer{Height: unit.Dp(12)}.Layout),
+
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				if downloading {
+					return w.drawButton(gtx, &w.redownloadBtn, i18n.T("settings_redownload"), colorPanelLight, colorTextDim, false)
+				}
+				return w.drawButton(gtx, &w.redownloadBtn, i18n.T("settings_redownload"), colorAccent, colorText, true)
+			}),
+		)
+	})
+}
+
+// drawOutlinedPanel is drawPanel with a 2dp outline to draw attention to it.
+func (w *Window) drawOutlinedPanel(gtx layout.Context, outline color.NRGBA, content layout.Widget) layout.Dimensions {
+	macro := op.Record(gtx.Ops)
+	dims := layout.UniformInset(unit.Dp(16)).Layout(gtx, content)
+	call := macro.Stop()
+
+	rr := gtx.Dp(unit.Dp(12))
+	rect := clip.RRect{
+		Rect: image.Rectangle{Max: dims.Size},
+		NE:   rr, NW: rr, SE: rr, SW: rr,
+	}
+	paint.FillShape(gtx.Ops, colorPanel, rect.Op(gtx.Ops))
+	paint.FillShape(gtx.Ops, outline, clip.Stroke{Path: rect.Path(gtx.Ops), Width: float32(gtx.Dp(unit.Dp(2)))}.Op())
+
+	call.Add(gtx.Ops)
+
+	return dims
+}
+
 func (w *Window) drawPanel(gtx layout.Context, content layout.Widget) layout.Dimensions {
 	// First layout content to get its size
 	macro := op.Record(gtx.Ops)
@@ -902,18 +1903,13 @@ func (w *Window) drawModelItem(gtx layout.Context, m models.ModelInfo, selected
 				layout.Flexed(1, func(gtx layout.Context) layout.Dimensions {
 					return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
 						layout.Rigid(func(gtx layout.Context) layout.Dimensions {
-							th := material.NewTheme()
-							th.Palette.Fg = colorText
-							lbl := material.Label(th, unit.Sp(14), m.Name)
-							lbl.Font.Weight = font.Medium
-							return lbl.Layout(gtx)
+							return w.drawModelName(gtx, m, unit.Sp(14))
 						}),
 						layout.Rigid(layout.Spacer{Height: unit.Dp(2)}.Layout),
 						layout.Rigid(func(gtx layout.Context) layout.Dimensions {
 							th := material.NewTheme()
 							th.Palette.Fg = colorTextDim
-							size := formatSize(m.Size)
-							lbl := material.Label(th, unit.Sp(11), size)
+							lbl := material.Label(th, unit.Sp(11), modelDetails(m))
 							return lbl.Layout(gtx)
 						}),
 					)
@@ -921,10 +1917,13 @@ func (w *Window) drawModelItem(gtx layout.Context, m models.ModelInfo, selected
 
 				// Status badge or download button
 				layout.Rigid(func(gtx layout.Context) layout.Dimensions {
-					if isDownloaded {
-						return w.drawStatusBadge(gtx, "✓", colorSuccess)
+					if !isDownloaded {
+						return w.drawDownloadButton(gtx, downloadBtn, "↓")
+					}
+					if w.manager.IsOutdated(m.ID) {
+						return w.drawDownloadButton(gtx, downloadBtn, "↻")
 					}
-					return w.drawDownloadButton(gtx, downloadBtn)
+					return w.drawStatusBadge(gtx, "✓", colorSuccess)
 				}),
 			)
 		})
@@ -987,6 +1986,37 @@ func (w *Window) drawRadioIndicator(gtx layout.Context, selected bool) layout.Di
 	return layout.Dimensions{Size: image.Pt(size, size)}
 }
 
+// drawModelName draws the model name, followed by badges for models
+// added by the user in custom_models.json and for downloaded models
+// with an update on the server.
+func (w *Window) drawModelName(gtx layout.Context, m models.ModelInfo, size unit.Sp) layout.Dimensions {
+	th := material.NewTheme()
+	th.Palette.Fg = colorText
+	name := material.Label(th, size, m.Name)
+	name.Font.Weight = font.Medium
+
+	children := []layout.FlexChild{layout.Rigid(name.Layout)}
+	badge := func(text string, col color.NRGBA) {
+		children = append(children,
+			layout.Rigid(layout.Spacer{Width: unit.Dp(6)}.Layout),
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				th := material.NewTheme()
+				th.Palette.Fg = col
+				lbl := material.Label(th, size-unit.Sp(3), text)
+				lbl.Font.Weight = font.Bold
+				return lbl.Layout(gtx)
+			}),
+		)
+	}
+	if m.Custom {
+		badge(i18n.T("settings_model_custom"), colorAccent)
+	}
+	if w.manager.IsOutdated(m.ID) && w.manager.IsDownloaded(m) {
+		badge(i18n.T("settings_model_update"), colorWarning)
+	}
+	return layout.Flex{Alignment: layout.Baseline}.Layout(gtx, children...)
+}
+
 func (w *Window) drawStatusBadge(gtx layout.Context, text string, col color.NRGBA) layout.Dimensions {
 	th := material.NewTheme()
 	th.Palette.Fg = col
@@ -995,7 +2025,9 @@ func (w *Window) drawStatusBadge(gtx layout.Context, text string, col color.NRGB
 	return lbl.Layout(gtx)
 }
 
-func (w *Window) drawDownloadButton(gtx layout.Context, btn *widget.Clickable) layout.Dimensions {
+// drawDownloadButton draws a small download button: "↓" for a new model,
+// "↻" for an update.
+func (w *Window) drawDownloadButton(gtx layout.Context, btn *widget.Clickable, label string) layout.Dimensions {
 	macro := op.Record(gtx.Ops)
 	dims := material.Clickable(gtx, btn, func(gtx layout.Context) layout.Dimensions {
 		return layout.Inset{
@@ -1004,7 +2036,7 @@ func (w *Window) drawDownloadButton(gtx layout.Context, btn *widget.Clickable) l
 		}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
 			th := material.NewTheme()
 			th.Palette.Fg = colorText
-			lbl := material.Label(th, unit.Sp(11), "↓")
+			lbl := material.Label(th, unit.Sp(11), label)
 			lbl.Font.Weight = font.Bold
 			return lbl.Layout(gtx)
 		})
@@ -1022,7 +2054,7 @@ func (w *Window) drawDownloadButton(gtx layout.Context, btn *widget.Clickable) l
 	return dims
 }
 
-func (w *Window) drawProgressBar(gtx layout.Context, progress float64, modelID string) layout.Dimensions {
+func (w *Window) drawProgressBar(gtx layout.Context, progress downloadProgress, modelID string) layout.Dimensions {
 	info, _ := models.GetModel(modelID)
 
 	return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
@@ -1038,7 +2070,7 @@ func (w *Window) drawProgressBar(gtx layout.Context, progress float64, modelID s
 			}
 			paint.FillShape(gtx.Ops, colorPanel, bgRect.Op(gtx.Ops))
 
-			fillWidth := int(float64(width) * progress)
+			fillWidth := int(float64(width) * progress.fraction)
 			if fillWidth > 0 {
 				fillRect := clip.RRect{
 					Rect: image.Rectangle{Max: image.Pt(fillWidth, height)},
@@ -1056,13 +2088,59 @@ func (w *Window) drawProgressBar(gtx layout.Context, progress float64, modelID s
 		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
 			th := material.NewTheme()
 			th.Palette.Fg = colorTextDim
-			text := fmt.Sprintf("%s %s... %.0f%%", i18n.T("settings_downloading"), info.Name, progress*100)
+			action := i18n.T("settings_downloading")
+			if progress.extracting {
+				action = i18n.T("settings_extracting")
+			}
+			text := fmt.Sprintf("%s %s... %.0f%%", action, info.Name, progress.fraction*100)
+			if progress.speed > 0 {
+				text += " · " + formatSpeed(progress.speed)
+				if progress.remaining > 0 {
+					text += " · " + i18n.T("settings_download_left") + " " + progress.remaining.Round(time.Second).String()
+				}
+			}
 			lbl := material.Label(th, unit.Sp(11), text)
 			return lbl.Layout(gtx)
 		}),
 	)
 }
 
+// drawDownloadError replaces the progress bar after a failed download
+// and offers to retry it.
+func (w *Window) drawDownloadError(gtx layout.Context, modelID, msg string) layout.Dimensions {
+	name := modelID
+	if info, ok := models.GetModel(modelID); ok {
+		name = info.Name
+	}
+
+	return layout.Flex{Axis: layout.Horizontal, Alignment: layout.Middle}.Layout(gtx,
+		layout.Flexed(1, func(gtx layout.Context) layout.Dimensions {
+			return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+				layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+					th := material.NewTheme()
+					th.Palette.Fg = colorError
+					label := material.Label(th, unit.Sp(13), fmt.Sprintf(i18n.T("settings_download_error"), name))
+					label.Font.Weight = font.Bold
+					return label.Layout(gtx)
+				}),
+				layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+					th := material.NewTheme()
+					th.Palette.Fg = colorTextDim
+					label := material.Label(th, unit.Sp(11), msg)
+					label.MaxLines = 2
+					return label.Layout(gtx)
+				}),
+			)
+		}),
+
+		layout.Rigid(layout.Spacer{Width: unit.Dp(12)}.Layout),
+
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			return w.drawButton(gtx, &w.retryBtn, i18n.T("settings_download_retry"), colorError, colorText, true)
+		}),
+	)
+}
+
 func (w *Window) drawButtons(gtx layout.Context, selectedModel string, downloading bool) layout.Dimensions {
 	return layout.Flex{
 		Axis:      layout.Horizontal,
@@ -1120,6 +2198,24 @@ func (w *Window) drawButton(gtx layout.Context, btn *widget.Clickable, label str
 	return dims
 }
 
+// formatSpeed formats a download speed in bytes per second.
+func formatSpeed(bytesPerSec float64) string {
+	const mb = 1024 * 1024
+	if bytesPerSec >= mb {
+		return fmt.Sprintf("%.1f MB/s", bytesPerSec/mb)
+	}
+	return fmt.Sprintf("%.0f KB/s", bytesPerSec/1024)
+}
+
+// modelDetails returns the secondary line of a model item: the size, or the
+// file name for local custom models whose size is unknown.
+func modelDetails(m models.ModelInfo) string {
+	if m.Size == 0 && m.Path != "" {
+		return filepath.Base(m.Path)
+	}
+	return formatSize(m.Size)
+}
+
 func formatSize(bytes int64) string {
 	const unit = 1024
 	if bytes < unit {
@@ -0,0 +1,121 @@
+package speech
+
+import (
+	"context"
+	"strings"
+)
+
+const (
+	// ChunkWindow - длина куска длинной записи (15 секунд при 16kHz).
+	ChunkWindow = 16000 * 15
+	// ChunkOverlap - перекрытие соседних кусков (1 секунда), чтобы слово
+	// на границе целиком попало хотя бы в один из них.
+	ChunkOverlap = 16000 * 1
+	// maxStitchWords - сколько слов на стыке сравнивается при склейке:
+	// за секунду перекрытия произносится заметно меньше.
+	maxStitchWords = 8
+)
+
+// ChunkOptions - параметры распознавания длинной записи по кускам.
+type ChunkOptions struct {
+	// Window и Overlap - длина куска и перекрытие в сэмплах
+	// (ноль - ChunkWindow и ChunkOverlap).
+	Window, Overlap int
+	// Drop возвращает true для текста куска, который нужно отбросить
+	// (например, галлюцинации на тишине). Может быть nil.
+	Drop func(text string) bool
+	// OnProgress получает склеенный текст после каждого куска. Может быть nil.
+	OnProgress func(text string)
+}
+
+// SplitChunks делит запись на куски длиной window, соседние куски
+// перекрываются на overlap сэмплов (не больше половины куска).
+// Последний кусок может быть короче. Куски - срезы исходного буфера.
+func SplitChunks(samples []float32, window, overlap int) [][]float32 {
+	if window <= 0 || len(samples) <= window {
+		return [][]float32{samples}
+	}
+	overlap = min(max(overlap, 0), window/2)
+	step := window - overlap
+
+	var chunks [][]float32
+	for start := 0; ; start += step {
+		end := min(start+window, len(samples))
+		chunks = append(chunks, samples[start:end:end])
+		if end == len(samples) {
+			return chunks
+		}
+	}
+}
+
+// StitchText склеивает текст соседних кусков: если последние слова prev
+// совпадают с первыми словами next (без учёта регистра и пунктуации),
+// повтор из next убирается.
+func StitchText(prev, next string) string {
+	prevWords := strings.Fields(prev)
+	nextWords := strings.Fields(next)
+	if len(prevWords) == 0 {
+		return strings.Join(nextWords, " ")
+	}
+	if len(nextWords) == 0 {
+		return strings.Join(prevWords, " ")
+	}
+
+	// Ищем самое длинное совпадение хвоста prev с началом next
+	skip := 0
+	for n := min(maxStitchWords, len(prevWords), len(nextWords)); n > 0; n-- {
+		if sameWords(prevWords[len(prevWords)-n:], nextWords[:n]) {
+			skip = n
+			break
+		}
+	}
+	return strings.Join(append(prevWords, nextWords[skip:]...), " ")
+}
+
+// sameWords сравнивает слова без учёта регистра и пунктуации.
+func sameWords(a, b []string) bool {
+	for i := range a {
+		wa, wb := normalizePhrase(a[i]), normalizePhrase(b[i])
+		if wa == "" || wa != wb {
+			return false
+		}
+	}
+	return true
+}
+
+// TranscribeChunked распознаёт длинную запись по перекрывающимся кускам
+// и склеивает результат: память движка ограничена длиной куска, а текст
+// появляется по мере распознавания (OnProgress). Уверенность - среднее по
+// кускам, где движок её сообщил, иначе NoConfidence.
+func TranscribeChunked(ctx context.Context, rec Recognizer, samples []float32, lang string, opts ChunkOptions) (string, float64, error) {
+	window, overlap := opts.Window, opts.Overlap
+	if window <= 0 {
+		window, overlap = ChunkWindow, ChunkOverlap
+	}
+
+	text := ""
+	var confSum float64
+	confCount := 0
+	for _, chunk := range SplitChunks(samples, window, overlap) {
+		part, conf, err := TranscribeWithConfidence(ctx, rec, chunk, lang)
+		if err != nil {
+			return "", NoConfidence, err
+		}
+		if opts.Drop != nil && opts.Drop(part) {
+			continue
+		}
+		if conf != NoConfidence {
+			confSum += conf
+			confCount++
+		}
+		text = StitchText(text, part)
+		if opts.OnProgress != nil {
+			opts.OnProgress(text)
+		}
+	}
+
+	if confCount == 0 {
+		return text, NoConfidence, nil
+	}
+	return text, confSum / float64(confCount), nil
+}
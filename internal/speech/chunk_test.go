@@ -0,0 +1,70 @@
+package speech
+
+import "testing"
+
+func TestSplitChunks(t *testing.T) {
+	samples := make([]float32, 25)
+	for i := range samples {
+		samples[i] = float32(i)
+	}
+
+	tests := []struct {
+		name    string
+		samples []float32
+		window  int
+		overlap int
+		want    [][2]int // начало и конец каждого куска
+	}{
+		{name: "short", samples: samples[:7], window: 10, overlap: 2, want: [][2]int{{0, 7}}},
+		{name: "exact window", samples: samples[:10], window: 10, overlap: 2, want: [][2]int{{0, 10}}},
+		{name: "overlap", samples: samples, window: 10, overlap: 2, want: [][2]int{{0, 10}, {8, 18}, {16, 25}}},
+		{name: "overlap capped", samples: samples[:20], window: 10, overlap: 8, want: [][2]int{{0, 10}, {5, 15}, {10, 20}}},
+		{name: "no window", samples: samples, window: 0, overlap: 2, want: [][2]int{{0, 25}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			chunks := SplitChunks(tt.samples, tt.window, tt.overlap)
+			if len(chunks) != len(tt.want) {
+				t.Fatalf("got %d chunks, want %d", len(chunks), len(tt.want))
+			}
+			for i, chunk := range chunks {
+				start, end := tt.want[i][0], tt.want[i][1]
+				if len(chunk) != end-start || chunk[0] != float32(start) {
+					t.Errorf("chunk %d = [%v..] len %d, want [%d:%d]", i, chunk[0], len(chunk), start, end)
+				}
+			}
+		})
+	}
+}
+
+// TestSplitChunksNoAlias проверяет, что append к куску не портит следующий.
+func TestSplitChunksNoAlias(t *testing.T) {
+	samples := make([]float32, 25)
+	chunks := SplitChunks(samples, 10, 2)
+	_ = append(chunks[0], 42)
+	if chunks[1][2] != 0 {
+		t.Error("append к куску изменил исходный буфер")
+	}
+}
+
+func TestStitchText(t *testing.T) {
+	tests := []struct {
+		name, prev, next, want string
+	}{
+		{name: "empty prev", prev: "", next: "привет мир", want: "привет мир"},
+		{name: "empty next", prev: "привет мир", next: "  ", want: "привет мир"},
+		{name: "overlap", prev: "раз два три", next: "два три четыре", want: "раз два три четыре"},
+		{name: "overlap case and punctuation", prev: "Встреча в пятницу.", next: "в пятницу, в десять", want: "Встреча в пятницу. в десять"},
+		{name: "longest overlap", prev: "да да да", next: "да да нет", want: "да да да нет"},
+		{name: "no common words", prev: "раз два", next: "три четыре", want: "раз два три четыре"},
+		{name: "common word not at the seam", prev: "раз два", next: "три раз", want: "раз два три раз"},
+		{name: "punctuation only", prev: "раз -", next: "- два", want: "раз - - два"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := StitchText(tt.prev, tt.next); got != tt.want {
+				t.Errorf("StitchText(%q, %q) = %q, want %q", tt.prev, tt.next, got, tt.want)
+			}
+		})
+	}
+}
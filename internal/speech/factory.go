@@ -12,6 +12,11 @@ type Factory struct {
 	manager *models.Manager
 	current Recognizer
 	modelID string
+	threads int    // число потоков для whisper, 0 - по умолчанию
+	prompt  string // начальная подсказка для whisper
+	params  WhisperParams
+	grammar []string // фразы грамматики vosk, nil - без ограничений
+	loading bool     // идёт Load
 	mu      sync.RWMutex
 }
 
@@ -39,11 +44,32 @@ func (f *Factory) Create(modelID string) (Recognizer, error) {
 	var rec Recognizer
 	var err error
 
+	f.mu.RLock()
+	threads := f.threads
+	prompt := f.prompt
+	params := f.params
+	grammar := f.grammar
+	f.mu.RUnlock()
+
 	switch info.Engine {
 	case models.EngineWhisper:
-		rec, err = NewWhisperFromFile(modelPath)
+		var w *WhisperRecognizer
+		w, err = NewWhisperFromFile(modelPath, threads)
+		if err == nil {
+			w.SetPrompt(prompt)
+			w.SetParams(params)
+			rec = w
+		}
 	case models.EngineVosk:
-		rec, err = NewVosk(modelPath)
+		var v *VoskRecognizer
+		v, err = NewVosk(modelPath)
+		if err == nil {
+			if err = v.SetGrammar(grammar); err != nil {
+				v.Close()
+			} else {
+				rec = v
+			}
+		}
 	default:
 		return nil, fmt.Errorf("неизвестный движок: %s", info.Engine)
 	}
@@ -57,12 +83,18 @@ func (f *Factory) Create(modelID string) (Recognizer, error) {
 
 // Load загружает модель и устанавливает её как текущую.
 func (f *Factory) Load(modelID string) error {
+	f.mu.Lock()
+	f.loading = true
+	f.mu.Unlock()
+
 	rec, err := f.Create(modelID)
+
+	f.mu.Lock()
+	f.loading = false
 	if err != nil {
+		f.mu.Unlock()
 		return err
 	}
-
-	f.mu.Lock()
 	old := f.current
 	f.current = rec
 	f.modelID = modelID
@@ -98,6 +130,60 @@ func (f *Factory) Swap(modelID string) error {
 	return nil
 }
 
+// SetThreads устанавливает число потоков распознавания (0 - по умолчанию).
+// Применяется к текущему и ко всем новым распознавателям whisper.
+func (f *Factory) SetThreads(threads int) {
+	f.mu.Lock()
+	f.threads = threads
+	current := f.current
+	f.mu.Unlock()
+
+	if w, ok := current.(*WhisperRecognizer); ok {
+		w.SetThreads(threads)
+	}
+}
+
+// SetPrompt устанавливает начальную подсказку whisper (пусто - без подсказки).
+// Применяется к текущему и ко всем новым распознавателям whisper.
+func (f *Factory) SetPrompt(prompt string) {
+	f.mu.Lock()
+	f.prompt = prompt
+	current := f.current
+	f.mu.Unlock()
+
+	if w, ok := current.(*WhisperRecognizer); ok {
+		w.SetPrompt(prompt)
+	}
+}
+
+// SetWhisperParams устанавливает параметры декодирования whisper.
+// Применяется к текущему и ко всем новым распознавателям whisper.
+func (f *Factory) SetWhisperParams(params WhisperParams) {
+	f.mu.Lock()
+	f.params = params
+	current := f.current
+	f.mu.Unlock()
+
+	if w, ok := current.(*WhisperRecognizer); ok {
+		w.SetParams(params)
+	}
+}
+
+// SetVoskGrammar ограничивает распознавание vosk фразами phrases
+// (nil - без ограничений). Применяется к текущему и ко всем новым
+// распознавателям vosk.
+func (f *Factory) SetVoskGrammar(phrases []string) error {
+	f.mu.Lock()
+	f.grammar = phrases
+	current := f.current
+	f.mu.Unlock()
+
+	if v, ok := current.(*VoskRecognizer); ok {
+		return v.SetGrammar(phrases)
+	}
+	return nil
+}
+
 // Current возвращает текущий распознаватель (thread-safe).
 func (f *Factory) Current() Recognizer {
 	f.mu.RLock()
@@ -119,6 +205,13 @@ func (f *Factory) IsLoaded() bool {
 	return f.current != nil
 }
 
+// IsLoading возвращает true, пока Load загружает модель.
+func (f *Factory) IsLoading() bool {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	return f.loading
+}
+
 // Close закрывает текущий распознаватель.
 func (f *Factory) Close() {
 	f.mu.Lock()
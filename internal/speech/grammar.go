@@ -0,0 +1,46 @@
+package speech
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// LoadGrammar читает грамматику Vosk из JSON файла - массива фраз, например
+// ["включи свет", "выключи свет", "[unk]"]. С грамматикой Vosk распознаёт
+// только эти фразы; "[unk]" разрешает всё остальное как неизвестное слово.
+// Пустой список или пустая фраза считаются ошибкой.
+func LoadGrammar(path string) ([]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var phrases []string
+	if err := json.Unmarshal(data, &phrases); err != nil {
+		return nil, fmt.Errorf("грамматика %s: ожидается JSON массив строк: %w", path, err)
+	}
+	if len(phrases) == 0 {
+		return nil, fmt.Errorf("грамматика %s: пустой список фраз", path)
+	}
+	for i, phrase := range phrases {
+		phrases[i] = strings.TrimSpace(phrase)
+		if phrases[i] == "" {
+			return nil, fmt.Errorf("грамматика %s: пустая фраза №%d", path, i+1)
+		}
+	}
+	return phrases, nil
+}
+
+// grammarJSON кодирует фразы в формат грамматики Vosk ("" - без грамматики).
+func grammarJSON(phrases []string) (string, error) {
+	if len(phrases) == 0 {
+		return "", nil
+	}
+	data, err := json.Marshal(phrases)
+	if err != nil {
+		return "", fmt.Errorf("грамматика Vosk: %w", err)
+	}
+	return string(data), nil
+}
@@ -0,0 +1,64 @@
+package speech
+
+import (
+	"strings"
+	"unicode"
+)
+
+// DefaultHallucinations - фразы, которые Whisper выдаёт на тишине или дыхании
+// (наследие субтитров из обучающих данных), по языкам.
+var DefaultHallucinations = map[string][]string{
+	"ru": {
+		"Продолжение следует...",
+		"Субтитры сделал DimaTorzok",
+		"Субтитры создавал DimaTorzok",
+		"Редактор субтитров А.Синецкая Корректор А.Егорова",
+		"Спасибо за просмотр!",
+		"Спасибо за внимание!",
+		"Подписывайтесь на канал!",
+		"Ставьте лайки и подписывайтесь на канал!",
+		"До новых встреч!",
+	},
+	"en": {
+		"Thanks for watching!",
+		"Thank you for watching!",
+		"Please subscribe to my channel.",
+		"Subtitles by the Amara.org community",
+	},
+}
+
+// IsHallucination проверяет, совпадает ли text (после нормализации) с одной
+// из фраз DefaultHallucinations для любого языка или из extra.
+// Язык не учитывается: при "auto" Whisper может выдать фразу на любом из них.
+func IsHallucination(text string, extra []string) bool {
+	normalized := normalizePhrase(text)
+	if normalized == "" {
+		return false
+	}
+
+	for _, phrases := range DefaultHallucinations {
+		for _, p := range phrases {
+			if normalizePhrase(p) == normalized {
+				return true
+			}
+		}
+	}
+	for _, p := range extra {
+		if normalizePhrase(p) == normalized {
+			return true
+		}
+	}
+	return false
+}
+
+// normalizePhrase приводит фразу к нижнему регистру, убирает пунктуацию
+// и схлопывает пробелы.
+func normalizePhrase(s string) string {
+	s = strings.Map(func(r rune) rune {
+		if unicode.IsPunct(r) || unicode.IsSymbol(r) {
+			return ' '
+		}
+		return unicode.ToLower(r)
+	}, s)
+	return strings.Join(strings.Fields(s), " ")
+}
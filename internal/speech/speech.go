@@ -1,6 +1,11 @@
 // Package speech предоставляет абстракцию для движков распознавания речи.
 package speech
 
+import (
+	"context"
+	"errors"
+)
+
 // Engine тип движка распознавания.
 type Engine string
 
@@ -19,6 +24,11 @@ type Recognizer interface {
 	// Возвращает распознанный текст или ошибку.
 	Transcribe(samples []float32, lang string) (string, error)
 
+	// TranscribeCtx работает как Transcribe, но прерывается при отмене ctx
+	// и тогда возвращает ctx.Err(). Насколько быстро работа остановится,
+	// зависит от движка.
+	TranscribeCtx(ctx context.Context, samples []float32, lang string) (string, error)
+
 	// Close освобождает ресурсы движка.
 	Close()
 
@@ -26,6 +36,51 @@ type Recognizer interface {
 	Name() string
 }
 
+// ErrClosed - распознаватель уже закрыт (например, заменён другой моделью),
+// распознавать им нельзя.
+var ErrClosed = errors.New("модель распознавания закрыта")
+
+// NoConfidence - уверенность распознавания неизвестна.
+const NoConfidence = -1.0
+
+// LowConfidenceThreshold - уверенность ниже этого значения считается низкой.
+const LowConfidenceThreshold = 0.6
+
+// ConfidenceRecognizer - распознаватель, умеющий оценивать уверенность результата.
+type ConfidenceRecognizer interface {
+	// TranscribeWithConfidence работает как Transcribe и дополнительно
+	// возвращает среднюю уверенность распознавания в диапазоне [0, 1].
+	TranscribeWithConfidence(ctx context.Context, samples []float32, lang string) (string, float64, error)
+}
+
+// TranscribeWithConfidence распознаёт речь через rec и возвращает уверенность,
+// если движок её поддерживает, иначе NoConfidence. Отмена ctx прерывает распознавание.
+func TranscribeWithConfidence(ctx context.Context, rec Recognizer, samples []float32, lang string) (string, float64, error) {
+	if cr, ok := rec.(ConfidenceRecognizer); ok {
+		return cr.TranscribeWithConfidence(ctx, samples, lang)
+	}
+	text, err := rec.TranscribeCtx(ctx, samples, lang)
+	return text, NoConfidence, err
+}
+
+// StreamingRecognizer - распознаватель, который выдаёт промежуточный текст
+// по ходу записи. Поддерживает только Vosk, Whisper распознаёт запись целиком.
+type StreamingRecognizer interface {
+	// NewStream начинает новый поток распознавания. Поток независим от
+	// Transcribe: итоговый результат по-прежнему даёт распознавание всей записи.
+	NewStream() (PartialStream, error)
+}
+
+// PartialStream принимает звук по мере записи.
+type PartialStream interface {
+	// Feed добавляет новые сэмплы (float32, 16kHz, mono) и возвращает
+	// текст, распознанный с начала потока.
+	Feed(samples []float32) (string, error)
+
+	// Close освобождает ресурсы потока.
+	Close()
+}
+
 // Config содержит общие настройки для создания распознавателя.
 type Config struct {
 	// Engine - тип движка (whisper, vosk).
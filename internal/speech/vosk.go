@@ -1,11 +1,13 @@
 package speech
 
 import (
+	"context"
 	"encoding/binary"
 	"encoding/json"
 	"fmt"
 	"math"
 	"os"
+	"strings"
 	"sync"
 
 	vosk "github.com/alphacep/vosk-api/go"
@@ -17,11 +19,32 @@ type VoskRecognizer struct {
 	model      *vosk.VoskModel
 	recognizer *vosk.VoskRecognizer
 	sampleRate float64
+	grammar    string // JSON список разрешённых фраз, "" - без ограничений
 }
 
 // voskResult структура для парсинга JSON результата от Vosk.
+// Words заполняется благодаря SetWords.
 type voskResult struct {
-	Text string `json:"text"`
+	Text  string     `json:"text"`
+	Words []voskWord `json:"result"`
+}
+
+// voskWord слово результата с уверенностью распознавания.
+type voskWord struct {
+	Word       string  `json:"word"`
+	Confidence float64 `json:"conf"`
+}
+
+// Confidence возвращает среднюю уверенность по словам или NoConfidence.
+func (r voskResult) Confidence() float64 {
+	if len(r.Words) == 0 {
+		return NoConfidence
+	}
+	var sum float64
+	for _, w := range r.Words {
+		sum += w.Confidence
+	}
+	return sum / float64(len(r.Words))
 }
 
 // NewVosk создаёт VoskRecognizer из пути к модели.
@@ -37,18 +60,63 @@ func NewVosk(modelPath string) (*VoskRecognizer, error) {
 	}
 
 	// 16000 Hz - стандартная частота для speech recognition
-	sampleRate := 16000.0
-	rec, err := vosk.NewRecognizer(model, sampleRate)
+	v := &VoskRecognizer{
+		model:      model,
+		sampleRate: 16000.0,
+	}
+	rec, err := v.newRecognizer(v.grammar)
 	if err != nil {
 		model.Free()
 		return nil, err
 	}
+	v.recognizer = rec
 
-	return &VoskRecognizer{
-		model:      model,
-		recognizer: rec,
-		sampleRate: sampleRate,
-	}, nil
+	return v, nil
+}
+
+// newRecognizer создаёт распознаватель модели с грамматикой grammar
+// ("" - без ограничений) и пословным результатом с уверенностью.
+func (v *VoskRecognizer) newRecognizer(grammar string) (*vosk.VoskRecognizer, error) {
+	var rec *vosk.VoskRecognizer
+	var err error
+	if grammar == "" {
+		rec, err = vosk.NewRecognizer(v.model, v.sampleRate)
+	} else {
+		rec, err = vosk.NewRecognizerGrm(v.model, v.sampleRate, grammar)
+	}
+	if err != nil {
+		return nil, err
+	}
+	rec.SetWords(1)
+	return rec, nil
+}
+
+// SetGrammar ограничивает распознавание фразами phrases (nil - снять
+// ограничение). Применяется к следующему распознаванию без перезагрузки
+// модели; уже начатые потоки промежуточного текста не меняются.
+func (v *VoskRecognizer) SetGrammar(phrases []string) error {
+	grammar, err := grammarJSON(phrases)
+	if err != nil {
+		return err
+	}
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	if v.model == nil {
+		return fmt.Errorf("модель Vosk закрыта")
+	}
+	if grammar == v.grammar {
+		return nil
+	}
+	rec, err := v.newRecognizer(grammar)
+	if err != nil {
+		return fmt.Errorf("грамматика Vosk не применена: %w", err)
+	}
+	v.recognizer.Free()
+	v.recognizer = rec
+	v.grammar = grammar
+	return nil
 }
 
 // Name возвращает название движка.
@@ -57,12 +125,55 @@ func (v *VoskRecognizer) Name() string {
 }
 
 // Transcribe распознаёт речь из аудио сэмплов.
-// Vosk принимает PCM16 данные, поэтому конвертируем float32 -> int16.
 func (v *VoskRecognizer) Transcribe(samples []float32, lang string) (string, error) {
+	return v.TranscribeCtx(context.Background(), samples, lang)
+}
+
+// TranscribeCtx распознаёт речь с возможностью отмены.
+func (v *VoskRecognizer) TranscribeCtx(ctx context.Context, samples []float32, lang string) (string, error) {
+	text, _, err := v.TranscribeWithConfidence(ctx, samples, lang)
+	return text, err
+}
+
+// TranscribeWithConfidence распознаёт речь и возвращает среднюю уверенность по словам.
+// Vosk принимает PCM16 данные, поэтому конвертируем float32 -> int16.
+// Vosk работает быстрее реального времени, поэтому отмена проверяется
+// только до и после обработки аудио.
+func (v *VoskRecognizer) TranscribeWithConfidence(ctx context.Context, samples []float32, lang string) (string, float64, error) {
 	v.mu.Lock()
 	defer v.mu.Unlock()
 
-	// Конвертируем float32 [-1, 1] в int16 [-32768, 32767]
+	if err := ctx.Err(); err != nil {
+		return "", NoConfidence, err
+	}
+	if v.recognizer == nil {
+		return "", NoConfidence, ErrClosed
+	}
+
+	// Обрабатываем аудио
+	v.recognizer.AcceptWaveform(toPCM16(samples))
+
+	// Получаем финальный результат
+	resultJSON := v.recognizer.FinalResult()
+
+	// Сбрасываем распознаватель для следующего использования
+	v.recognizer.Reset()
+
+	if err := ctx.Err(); err != nil {
+		return "", NoConfidence, err
+	}
+
+	// Парсим JSON результат
+	var result voskResult
+	if err := json.Unmarshal([]byte(resultJSON), &result); err != nil {
+		return "", NoConfidence, err
+	}
+
+	return result.Text, result.Confidence(), nil
+}
+
+// toPCM16 конвертирует float32 [-1, 1] в little-endian int16 [-32768, 32767].
+func toPCM16(samples []float32) []byte {
 	pcm16 := make([]byte, len(samples)*2)
 	for i, sample := range samples {
 		if sample > 1.0 {
@@ -73,23 +184,71 @@ func (v *VoskRecognizer) Transcribe(samples []float32, lang string) (string, err
 		val := int16(sample * math.MaxInt16)
 		binary.LittleEndian.PutUint16(pcm16[i*2:], uint16(val))
 	}
+	return pcm16
+}
 
-	// Обрабатываем аудио
-	v.recognizer.AcceptWaveform(pcm16)
+// voskStream - поток промежуточного распознавания со своим распознавателем
+// Vosk, чтобы не мешать итоговому Transcribe. Модель Vosk считает ссылки,
+// поэтому поток переживает закрытие VoskRecognizer.
+type voskStream struct {
+	recognizer *vosk.VoskRecognizer
+	final      []string // законченные фразы
+}
 
-	// Получаем финальный результат
-	resultJSON := v.recognizer.FinalResult()
+// voskPartial структура промежуточного результата Vosk.
+type voskPartial struct {
+	Partial string `json:"partial"`
+}
 
-	// Сбрасываем распознаватель для следующего использования
-	v.recognizer.Reset()
+// NewStream начинает поток промежуточного распознавания.
+func (v *VoskRecognizer) NewStream() (PartialStream, error) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
 
-	// Парсим JSON результат
-	var result voskResult
-	if err := json.Unmarshal([]byte(resultJSON), &result); err != nil {
+	if v.model == nil {
+		return nil, fmt.Errorf("модель Vosk закрыта")
+	}
+	rec, err := v.newRecognizer(v.grammar)
+	if err != nil {
+		return nil, err
+	}
+	return &voskStream{recognizer: rec}, nil
+}
+
+// Feed распознаёт новые сэмплы. Законченные Vosk фразы запоминаются,
+// к ним добавляется текущая незаконченная.
+func (s *voskStream) Feed(samples []float32) (string, error) {
+	if s.recognizer == nil {
+		return "", fmt.Errorf("поток закрыт")
+	}
+
+	if s.recognizer.AcceptWaveform(toPCM16(samples)) > 0 {
+		var result voskResult
+		if err := json.Unmarshal([]byte(s.recognizer.Result()), &result); err != nil {
+			return "", err
+		}
+		if result.Text != "" {
+			s.final = append(s.final, result.Text)
+		}
+		return strings.Join(s.final, " "), nil
+	}
+
+	var partial voskPartial
+	if err := json.Unmarshal([]byte(s.recognizer.PartialResult()), &partial); err != nil {
 		return "", err
 	}
+	if partial.Partial == "" {
+		return strings.Join(s.final, " "), nil
+	}
+	return strings.Join(append(s.final[:len(s.final):len(s.final)], partial.Partial), " "), nil
+}
 
-	return result.Text, nil
+// Close освобождает распознаватель потока.
+func (s *voskStream) Close() {
+	if s.recognizer != nil {
+		s.recognizer.Free()
+		s.recognizer = nil
+	}
 }
 
 // Close освобождает ресурсы.
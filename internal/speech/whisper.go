@@ -1,29 +1,101 @@
 package speech
 
 import (
-	"io"
+	"context"
+	"fmt"
+	"runtime"
 	"strings"
 	"sync"
+	"sync/atomic"
 
-	whisper "github.com/ggerganov/whisper.cpp/bindings/go/pkg/whisper"
+	whisper "github.com/ggerganov/whisper.cpp/bindings/go"
 )
 
-// WhisperRecognizer реализует Recognizer через whisper.cpp.
+// WhisperRecognizer реализует Recognizer через whisper.cpp. Используется
+// низкоуровневая привязка: высокоуровневая pkg/whisper создаёт контекст
+// только с жадной стратегией декодирования.
 type WhisperRecognizer struct {
-	mu    sync.Mutex
-	model whisper.Model
+	mu      sync.Mutex
+	model   *whisper.Context
+	threads atomic.Int32 // 0 - значение по умолчанию whisper.cpp
+	prompt  atomic.Value // string, начальная подсказка для каждого распознавания
+	params  atomic.Value // WhisperParams, параметры декодирования
+}
+
+// WhisperParams - параметры декодирования whisper.cpp. Нулевые значения
+// оставляют значения по умолчанию whisper.cpp.
+type WhisperParams struct {
+	// BeamSize - ширина лучевого поиска. Больше 1 - декодирование лучевым
+	// поиском (точнее, но медленнее), иначе жадное.
+	BeamSize int
+	// TemperatureFallback - шаг повышения температуры при повторном
+	// декодировании неуверенных окон. Отрицательное значение отключает
+	// повторы: быстрее, но хуже на коротких и шумных фразах.
+	TemperatureFallback float32
+	// EntropyThreshold - порог энтропии, выше которого окно декодируется
+	// повторно с повышенной температурой.
+	EntropyThreshold float32
+}
+
+// WhisperPreset - именованный набор параметров декодирования.
+type WhisperPreset string
+
+const (
+	// WhisperPresetFast - без повторного декодирования: быстрее всего.
+	WhisperPresetFast WhisperPreset = "fast"
+	// WhisperPresetAccurate - повторное декодирование неуверенных окон.
+	WhisperPresetAccurate WhisperPreset = "accurate"
+)
+
+// Params возвращает параметры декодирования пресета.
+// Неизвестный пресет считается точным.
+func (p WhisperPreset) Params() WhisperParams {
+	switch p {
+	case WhisperPresetFast:
+		return WhisperParams{
+			BeamSize:            1,
+			TemperatureFallback: -1,
+		}
+	default:
+		return WhisperParams{
+			BeamSize:            5,
+			TemperatureFallback: 0.2,
+			EntropyThreshold:    2.4,
+		}
+	}
 }
 
 // NewWhisperFromFile создаёт WhisperRecognizer из файла модели.
-func NewWhisperFromFile(modelPath string) (*WhisperRecognizer, error) {
-	model, err := whisper.New(modelPath)
-	if err != nil {
-		return nil, err
+// threads - число потоков распознавания, 0 - по умолчанию.
+func NewWhisperFromFile(modelPath string, threads int) (*WhisperRecognizer, error) {
+	model := whisper.Whisper_init(modelPath)
+	if model == nil {
+		return nil, fmt.Errorf("whisper: не удалось загрузить модель %s", modelPath)
 	}
 
-	return &WhisperRecognizer{
+	w := &WhisperRecognizer{
 		model: model,
-	}, nil
+	}
+	w.threads.Store(int32(threads))
+	return w, nil
+}
+
+// SetThreads устанавливает число потоков для следующих распознаваний.
+// Не блокируется на время текущего распознавания.
+func (w *WhisperRecognizer) SetThreads(threads int) {
+	w.threads.Store(int32(threads))
+}
+
+// SetPrompt устанавливает начальную подсказку (initial prompt) для следующих
+// распознаваний. Подсказка задаёт контекст каждого отдельного распознавания:
+// термины и имена из неё модель будет предпочитать. Пустая строка - без подсказки.
+func (w *WhisperRecognizer) SetPrompt(prompt string) {
+	w.prompt.Store(prompt)
+}
+
+// SetParams устанавливает параметры декодирования для следующих распознаваний.
+func (w *WhisperRecognizer) SetParams(params WhisperParams) {
+	w.params.Store(params)
 }
 
 // Name возвращает название движка.
@@ -33,38 +105,93 @@ func (w *WhisperRecognizer) Name() string {
 
 // Transcribe распознаёт речь из аудио сэмплов.
 func (w *WhisperRecognizer) Transcribe(samples []float32, lang string) (string, error) {
+	return w.TranscribeCtx(context.Background(), samples, lang)
+}
+
+// TranscribeCtx распознаёт речь с возможностью отмены. whisper.cpp проверяет
+// отмену перед кодированием каждого 30-секундного окна аудио и между шагами
+// вычислений при декодировании; после отмены возвращается ctx.Err().
+func (w *WhisperRecognizer) TranscribeCtx(cancelCtx context.Context, samples []float32, lang string) (string, error) {
 	w.mu.Lock()
 	defer w.mu.Unlock()
 
-	ctx, err := w.model.NewContext()
-	if err != nil {
+	if err := cancelCtx.Err(); err != nil {
 		return "", err
 	}
+	// Распознаватель могли закрыть после того, как его взяли для записи
+	if w.model == nil {
+		return "", ErrClosed
+	}
 
-	// Отключаем перевод - только транскрипция
-	ctx.SetTranslate(false)
+	if len(samples) == 0 {
+		return "", nil
+	}
+
+	decode, _ := w.params.Load().(WhisperParams)
+	strategy := whisper.SAMPLING_GREEDY
+	if decode.BeamSize > 1 {
+		strategy = whisper.SAMPLING_BEAM_SEARCH
+	}
+	params := w.model.Whisper_full_default_params(strategy)
+
+	// Только транскрипция, без перевода и вывода в консоль
+	params.SetTranslate(false)
+	params.SetPrintSpecial(false)
+	params.SetPrintProgress(false)
+	params.SetPrintRealtime(false)
+	params.SetPrintTimestamps(false)
+	params.SetNoContext(true)
+
+	params.SetThreads(runtime.NumCPU())
+	if threads := w.threads.Load(); threads > 0 {
+		params.SetThreads(int(threads))
+	}
+
+	if prompt, _ := w.prompt.Load().(string); prompt != "" {
+		params.SetInitialPrompt(prompt)
+	}
 
-	// Устанавливаем язык (для "auto" включится автодетект)
-	if lang != "" {
-		ctx.SetLanguage(lang)
+	if decode.BeamSize > 1 {
+		params.SetBeamSize(decode.BeamSize)
+	}
+	if decode.TemperatureFallback != 0 {
+		params.SetTemperatureFallback(decode.TemperatureFallback)
+	}
+	if decode.EntropyThreshold > 0 {
+		params.SetEntropyThold(decode.EntropyThreshold)
 	}
 
-	// Обрабатываем аудио
-	if err := ctx.Process(samples, nil, nil, nil); err != nil {
+	// Язык задаётся только многоязычной модели; "auto" включает автодетект.
+	// Неизвестный язык оставляет язык по умолчанию
+	if lang != "" && w.model.Whisper_is_multilingual() != 0 {
+		if lang == "auto" {
+			params.SetLanguage(-1)
+		} else if id := w.model.Whisper_lang_id(lang); id >= 0 {
+			params.SetLanguage(id)
+		}
+	}
+
+	// Обрабатываем аудио; false из колбэка кодировщика прерывает вычисления
+	// перед очередным окном, а abort_callback - посреди декодирования
+	encoderBegin := func() bool {
+		return cancelCtx.Err() == nil
+	}
+	release := setAbortCallback(&params, cancelCtx)
+	defer release()
+	if err := w.model.Whisper_full(params, samples, encoderBegin, nil, nil); err != nil {
+		if cancelCtx.Err() != nil {
+			return "", cancelCtx.Err()
+		}
+		return "", err
+	}
+	if err := cancelCtx.Err(); err != nil {
 		return "", err
 	}
 
 	// Собираем результат из сегментов
 	var result strings.Builder
-	for {
-		segment, err := ctx.NextSegment()
-		if err == io.EOF {
-			break
-		}
-		if err != nil {
-			return "", err
-		}
-		result.WriteString(segment.Text)
+	for i := range w.model.Whisper_full_n_segments() {
+		result.WriteString(w.model.Whisper_full_get_segment_text(i))
 	}
 
 	return strings.TrimSpace(result.String()), nil
@@ -76,7 +203,7 @@ func (w *WhisperRecognizer) Close() {
 	defer w.mu.Unlock()
 
 	if w.model != nil {
-		w.model.Close()
+		w.model.Whisper_free()
 		w.model = nil
 	}
 }
@@ -0,0 +1,34 @@
+package speech
+
+/*
+#include <stdint.h>
+#include <whisper.h>
+
+// Реализован на Go (whisper_callback.go)
+extern bool goWhisperAbort(void * user_data);
+
+// set_abort_callback подключает к параметрам проверку отмены, которую
+// whisper.cpp вызывает между шагами вычислений, в том числе при декодировании.
+static void set_abort_callback(struct whisper_full_params * params, uintptr_t handle) {
+    params->abort_callback = goWhisperAbort;
+    params->abort_callback_user_data = (void *) handle;
+}
+*/
+import "C"
+import (
+	"context"
+	"runtime/cgo"
+	"unsafe"
+
+	whisper "github.com/ggerganov/whisper.cpp/bindings/go"
+)
+
+// setAbortCallback прерывает распознавание с params, как только отменён ctx.
+// Колбэк кодировщика проверяет отмену только перед каждым 30-секундным
+// окном, а декодирование окна (особенно лучевым поиском) идёт заметно
+// дольше. Возвращённую функцию нужно вызвать после распознавания.
+func setAbortCallback(params *whisper.Params, ctx context.Context) (release func()) {
+	handle := cgo.NewHandle(ctx)
+	C.set_abort_callback((*C.struct_whisper_full_params)(unsafe.Pointer(params)), C.uintptr_t(handle))
+	return handle.Delete
+}
@@ -0,0 +1,20 @@
+package speech
+
+/*
+#include <stdbool.h>
+*/
+import "C"
+import (
+	"context"
+	"runtime/cgo"
+	"unsafe"
+)
+
+// goWhisperAbort - ggml_abort_callback распознавания whisper.cpp.
+// Возвращает true, когда контекст распознавания отменён.
+//
+//export goWhisperAbort
+func goWhisperAbort(userData unsafe.Pointer) C.bool {
+	ctx := cgo.Handle(uintptr(userData)).Value().(context.Context)
+	return ctx.Err() != nil
+}
@@ -2,6 +2,7 @@
 package startup
 
 import (
+	"fmt"
 	"image"
 	"image/color"
 	"math"
@@ -16,16 +17,11 @@ import (
 	"gioui.org/op/clip"
 	"gioui.org/op/paint"
 	"gioui.org/unit"
+	"gioui.org/widget"
 	"gioui.org/widget/material"
 
 	"shofar/internal/i18n"
-)
-
-var (
-	colorBG     = color.NRGBA{R: 30, G: 30, B: 34, A: 255}
-	colorText   = color.NRGBA{R: 240, G: 240, B: 245, A: 255}
-	colorDim    = color.NRGBA{R: 140, G: 140, B: 150, A: 255}
-	colorAccent = color.NRGBA{R: 88, G: 166, B: 255, A: 255}
+	"shofar/internal/theme"
 )
 
 // Window represents the startup loading window.
@@ -39,12 +35,18 @@ type Window struct {
 	// Loading state
 	status    string
 	substatus string
+	progress  float32 // 0..1, negative when the progress is unknown
+
+	// Cancel button, shown while onCancel is set
+	onCancel  func()
+	cancelBtn widget.Clickable
 }
 
 // New creates a new startup window.
 func New() *Window {
 	return &Window{
-		status: i18n.T("startup_status"),
+		status:   i18n.T("startup_status"),
+		progress: -1,
 	}
 }
 
@@ -52,6 +54,11 @@ func New() *Window {
 func (w *Window) Show() {
 	w.mu.Lock()
 	if w.running {
+		// Already open: bring it to the front
+		if w.window != nil {
+			// Not under w.mu: Perform waits for the window event loop
+			go w.window.Perform(system.ActionRaise)
+		}
 		w.mu.Unlock()
 		return
 	}
@@ -65,10 +72,23 @@ func (w *Window) Show() {
 
 // Hide closes the loading window.
 func (w *Window) Hide() {
+	doneCh := w.release()
+	if doneCh != nil {
+		select {
+		case <-doneCh:
+		case <-time.After(time.Second):
+		}
+	}
+}
+
+// release marks the window as closed and stops its redraw goroutine.
+// Returns the channel closed when the event loop exits, nil if the
+// window was not running.
+func (w *Window) release() chan struct{} {
 	w.mu.Lock()
 	if !w.running {
 		w.mu.Unlock()
-		return
+		return nil
 	}
 	w.running = false
 	stopCh := w.stopCh
@@ -79,13 +99,7 @@ func (w *Window) Hide() {
 	if stopCh != nil {
 		close(stopCh)
 	}
-
-	if doneCh != nil {
-		select {
-		case <-doneCh:
-		case <-time.After(time.Second):
-		}
-	}
+	return doneCh
 }
 
 // SetStatus updates the loading status text.
@@ -96,10 +110,40 @@ func (w *Window) SetStatus(status, substatus string) {
 	w.substatus = substatus
 }
 
+// SetProgress updates the loading progress (0..1) shown under the status.
+// A negative value hides it.
+func (w *Window) SetProgress(progress float32) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.progress = progress
+}
+
+// SetCancel shows a cancel button that calls fn when clicked.
+// nil hides the button.
+func (w *Window) SetCancel(fn func()) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.onCancel = fn
+}
+
 func (w *Window) getStatus() (string, string) {
 	w.mu.Lock()
 	defer w.mu.Unlock()
-	return w.status, w.substatus
+	substatus := w.substatus
+	if w.progress >= 0 {
+		percent := fmt.Sprintf("%d%%", int(w.progress*100))
+		if substatus != "" {
+			percent = substatus + " · " + percent
+		}
+		substatus = percent
+	}
+	return w.status, substatus
+}
+
+func (w *Window) getCancel() func() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.onCancel
 }
 
 func (w *Window) runEventLoop() {
@@ -108,9 +152,9 @@ func (w *Window) runEventLoop() {
 	w.window = new(app.Window)
 	w.window.Option(
 		app.Title("Shofar"),
-		app.Size(unit.Dp(300), unit.Dp(150)),
-		app.MinSize(unit.Dp(300), unit.Dp(150)),
-		app.MaxSize(unit.Dp(300), unit.Dp(150)),
+		app.Size(unit.Dp(300), unit.Dp(190)),
+		app.MinSize(unit.Dp(300), unit.Dp(190)),
+		app.MaxSize(unit.Dp(300), unit.Dp(190)),
 	)
 
 	var ops op.Ops
@@ -137,6 +181,9 @@ func (w *Window) runEventLoop() {
 	for {
 		switch e := w.window.Event().(type) {
 		case app.DestroyEvent:
+			// Closed by the user rather than Hide: the next Show
+			// must open a new window
+			w.release()
 			return
 		case app.FrameEvent:
 			gtx := app.NewContext(&ops, e)
@@ -147,11 +194,17 @@ func (w *Window) runEventLoop() {
 }
 
 func (w *Window) draw(gtx layout.Context) layout.Dimensions {
+	palette := theme.Current()
+
 	// Fill background
 	rect := clip.Rect{Max: gtx.Constraints.Max}
-	paint.FillShape(gtx.Ops, colorBG, rect.Op())
+	paint.FillShape(gtx.Ops, palette.BG, rect.Op())
 
 	status, substatus := w.getStatus()
+	onCancel := w.getCancel()
+	if onCancel != nil && w.cancelBtn.Clicked(gtx) {
+		onCancel()
+	}
 
 	// Center content
 	return layout.Center.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
@@ -166,7 +219,7 @@ func (w *Window) draw(gtx layout.Context) layout.Dimensions {
 			// Status text
 			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
 				th := material.NewTheme()
-				th.Palette.Fg = colorText
+				th.Palette.Fg = palette.Text
 				lbl := material.Label(th, unit.Sp(14), status)
 				lbl.Font.Weight = font.Medium
 				lbl.Alignment = 1 // Center
@@ -180,16 +233,51 @@ func (w *Window) draw(gtx layout.Context) layout.Dimensions {
 				}
 				return layout.Inset{Top: unit.Dp(4)}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
 					th := material.NewTheme()
-					th.Palette.Fg = colorDim
+					th.Palette.Fg = palette.TextDim
 					lbl := material.Label(th, unit.Sp(11), substatus)
 					lbl.Alignment = 1 // Center
 					return lbl.Layout(gtx)
 				})
 			}),
+
+			// Cancel button
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				if onCancel == nil {
+					return layout.Dimensions{}
+				}
+				return layout.Inset{Top: unit.Dp(12)}.Layout(gtx, w.drawCancelButton)
+			}),
 		)
 	})
 }
 
+func (w *Window) drawCancelButton(gtx layout.Context) layout.Dimensions {
+	palette := theme.Current()
+
+	macro := op.Record(gtx.Ops)
+	dims := material.Clickable(gtx, &w.cancelBtn, func(gtx layout.Context) layout.Dimensions {
+		return layout.Inset{
+			Top: unit.Dp(6), Bottom: unit.Dp(6),
+			Left: unit.Dp(16), Right: unit.Dp(16),
+		}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+			th := material.NewTheme()
+			th.Palette.Fg = palette.Text
+			return material.Label(th, unit.Sp(12), i18n.T("startup_cancel")).Layout(gtx)
+		})
+	})
+	call := macro.Stop()
+
+	rr := gtx.Dp(unit.Dp(6))
+	rect := clip.RRect{
+		Rect: image.Rectangle{Max: dims.Size},
+		NE:   rr, NW: rr, SE: rr, SW: rr,
+	}
+	paint.FillShape(gtx.Ops, palette.PanelLight, rect.Op(gtx.Ops))
+	call.Add(gtx.Ops)
+
+	return dims
+}
+
 func (w *Window) drawSpinner(gtx layout.Context) layout.Dimensions {
 	size := gtx.Dp(unit.Dp(40))
 	thickness := gtx.Dp(unit.Dp(3))
@@ -201,6 +289,8 @@ func (w *Window) drawSpinner(gtx layout.Context) layout.Dimensions {
 	center := image.Pt(size/2, size/2)
 	radius := size/2 - thickness
 
+	accent := theme.Current().Accent
+
 	// Draw spinner arc segments
 	numSegments := 12
 	for i := 0; i < numSegments; i++ {
@@ -215,7 +305,7 @@ func (w *Window) drawSpinner(gtx layout.Context) layout.Dimensions {
 			Min: image.Pt(x-dotRadius, y-dotRadius),
 			Max: image.Pt(x+dotRadius, y+dotRadius),
 		}
-		col := color.NRGBA{R: colorAccent.R, G: colorAccent.G, B: colorAccent.B, A: alpha}
+		col := color.NRGBA{R: accent.R, G: accent.G, B: accent.B, A: alpha}
 		paint.FillShape(gtx.Ops, col, dot.Op(gtx.Ops))
 	}
 
@@ -0,0 +1,112 @@
+// Package stats ведёт локальную статистику диктовки: сколько слов
+// распознано и сколько времени это сэкономило по сравнению с набором.
+// Статистика хранится в файле рядом с бинарником и никуда не отправляется.
+package stats
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// FileName - файл статистики рядом с бинарником (как config.json).
+const FileName = "stats.json"
+
+// TypingWPM - средняя скорость набора (слов в минуту), с которой
+// сравнивается диктовка.
+const TypingWPM = 40
+
+// Stats - накопленная статистика.
+type Stats struct {
+	Words      int       `json:"words"`       // распознано слов
+	Recordings int       `json:"recordings"`  // успешных записей
+	SpeakingMs int64     `json:"speaking_ms"` // общая длительность этих записей
+	Since      time.Time `json:"since"`       // начало подсчёта или последний сброс
+}
+
+// TimeSaved возвращает, насколько набор тех же слов со скоростью TypingWPM
+// дольше, чем их диктовка. Не бывает отрицательным.
+func (s Stats) TimeSaved() time.Duration {
+	typing := time.Duration(s.Words) * time.Minute / TypingWPM
+	speaking := time.Duration(s.SpeakingMs) * time.Millisecond
+	return max(typing-speaking, 0)
+}
+
+// Store хранит статистику и сохраняет её в файл после каждого изменения.
+type Store struct {
+	mu    sync.Mutex
+	path  string // "" - только в памяти
+	stats Stats
+}
+
+// Open читает статистику из файла path. Отсутствующий или повреждённый
+// файл означает подсчёт с нуля.
+func Open(path string) *Store {
+	s := &Store{path: path, stats: Stats{Since: time.Now()}}
+	if path == "" {
+		return s
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return s
+	}
+	var stats Stats
+	if err := json.Unmarshal(data, &stats); err == nil {
+		s.stats = stats
+	}
+	return s
+}
+
+// DefaultPath возвращает путь к файлу статистики рядом с бинарником
+// или "", если путь к бинарнику не определить.
+func DefaultPath() string {
+	execPath, err := os.Executable()
+	if err != nil {
+		return ""
+	}
+	execPath, err = filepath.EvalSymlinks(execPath)
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(filepath.Dir(execPath), FileName)
+}
+
+// Get возвращает текущую статистику.
+func (s *Store) Get() Stats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.stats
+}
+
+// Add учитывает успешную запись длительностью speaking, в которой
+// распознано words слов, и возвращает обновлённую статистику.
+func (s *Store) Add(words int, speaking time.Duration) (Stats, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.stats.Words += words
+	s.stats.Recordings++
+	s.stats.SpeakingMs += speaking.Milliseconds()
+	return s.stats, s.save()
+}
+
+// Reset обнуляет статистику.
+func (s *Store) Reset() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.stats = Stats{Since: time.Now()}
+	return s.save()
+}
+
+// save записывает статистику в файл. Вызывается под s.mu.
+func (s *Store) save() error {
+	if s.path == "" {
+		return nil
+	}
+	data, err := json.MarshalIndent(s.stats, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(s.path, data, 0644)
+}
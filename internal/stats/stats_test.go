@@ -0,0 +1,79 @@
+package stats
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestTimeSaved(t *testing.T) {
+	tests := []struct {
+		name  string
+		stats Stats
+		want  time.Duration
+	}{
+		{"ничего не распознано", Stats{}, 0},
+		// 80 слов набираются 2 минуты, диктуются 30 секунд
+		{"диктовка быстрее", Stats{Words: 80, SpeakingMs: 30000}, 90 * time.Second},
+		{"диктовка медленнее", Stats{Words: 10, SpeakingMs: 60000}, 0},
+	}
+	for _, tt := range tests {
+		if got := tt.stats.TimeSaved(); got != tt.want {
+			t.Errorf("%s: TimeSaved() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestStorePersists(t *testing.T) {
+	path := filepath.Join(t.TempDir(), FileName)
+	s := Open(path)
+	if _, err := s.Add(5, 2*time.Second); err != nil {
+		t.Fatal(err)
+	}
+	got, err := s.Add(3, 1500*time.Millisecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Words != 8 || got.Recordings != 2 || got.SpeakingMs != 3500 {
+		t.Fatalf("Add = %+v", got)
+	}
+
+	reopened := Open(path).Get()
+	if reopened.Words != 8 || reopened.Recordings != 2 || reopened.SpeakingMs != 3500 {
+		t.Errorf("после повторного открытия %+v", reopened)
+	}
+	if !reopened.Since.Equal(got.Since) {
+		t.Errorf("Since = %v, want %v", reopened.Since, got.Since)
+	}
+}
+
+func TestStoreReset(t *testing.T) {
+	path := filepath.Join(t.TempDir(), FileName)
+	s := Open(path)
+	before := s.Get().Since
+	if _, err := s.Add(5, time.Second); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Reset(); err != nil {
+		t.Fatal(err)
+	}
+
+	reopened := Open(path).Get()
+	if reopened.Words != 0 || reopened.Recordings != 0 || reopened.SpeakingMs != 0 {
+		t.Errorf("после сброса %+v", reopened)
+	}
+	if reopened.Since.Before(before) {
+		t.Errorf("Since после сброса %v раньше начала подсчёта %v", reopened.Since, before)
+	}
+}
+
+func TestOpenCorrupted(t *testing.T) {
+	path := filepath.Join(t.TempDir(), FileName)
+	if err := os.WriteFile(path, []byte("{not json"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if got := Open(path).Get(); got.Words != 0 || got.Since.IsZero() {
+		t.Errorf("повреждённый файл прочитан как %+v", got)
+	}
+}
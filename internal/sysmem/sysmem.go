@@ -0,0 +1,9 @@
+// Package sysmem сообщает, сколько оперативной памяти доступно для загрузки
+// модели. Значение приблизительное и нужно только для предупреждения.
+package sysmem
+
+// AvailableMB возвращает объём памяти в мегабайтах, который можно занять
+// без вытеснения других программ. ok=false, если узнать его не удалось.
+func AvailableMB() (mb int, ok bool) {
+	return availableMB()
+}
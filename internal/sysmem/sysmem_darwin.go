@@ -0,0 +1,20 @@
+//go:build darwin
+
+package sysmem
+
+import "golang.org/x/sys/unix"
+
+// availableMB оценивает доступную память по kern.memorystatus_level - доле
+// свободной памяти в процентах, по которой система считает давление памяти.
+// Свободных страниц на macOS обычно мало: кэш отдаётся по требованию.
+func availableMB() (int, bool) {
+	total, err := unix.SysctlUint64("hw.memsize")
+	if err != nil {
+		return 0, false
+	}
+	level, err := unix.SysctlUint32("kern.memorystatus_level")
+	if err != nil || level > 100 {
+		return 0, false
+	}
+	return int(total / 100 * uint64(level) / (1024 * 1024)), true
+}
@@ -0,0 +1,8 @@
+//go:build !linux && !windows && !darwin
+
+package sysmem
+
+// availableMB не реализован для этой ОС: проверка памяти пропускается.
+func availableMB() (int, bool) {
+	return 0, false
+}
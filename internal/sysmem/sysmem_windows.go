@@ -0,0 +1,37 @@
+//go:build windows
+
+package sysmem
+
+import (
+	"syscall"
+	"unsafe"
+)
+
+var (
+	kernel32                 = syscall.NewLazyDLL("kernel32.dll")
+	procGlobalMemoryStatusEx = kernel32.NewProc("GlobalMemoryStatusEx")
+)
+
+// memoryStatusEx - структура MEMORYSTATUSEX.
+type memoryStatusEx struct {
+	length               uint32
+	memoryLoad           uint32
+	totalPhys            uint64
+	availPhys            uint64
+	totalPageFile        uint64
+	availPageFile        uint64
+	totalVirtual         uint64
+	availVirtual         uint64
+	availExtendedVirtual uint64
+}
+
+// availableMB возвращает свободную физическую память из GlobalMemoryStatusEx.
+func availableMB() (int, bool) {
+	status := memoryStatusEx{}
+	status.length = uint32(unsafe.Sizeof(status))
+	ret, _, _ := procGlobalMemoryStatusEx.Call(uintptr(unsafe.Pointer(&status)))
+	if ret == 0 {
+		return 0, false
+	}
+	return int(status.availPhys / (1024 * 1024)), true
+}
@@ -0,0 +1,166 @@
+// Package textproc применяет к распознанному тексту простые детерминированные
+// правки: замены по словарю, схлопывание пробелов, обрезку, заглавную букву
+// и простую пунктуацию для движков, которые её не расставляют.
+package textproc
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Rules - набор правок текста. Структура совпадает с config.TextRules,
+// поэтому значение из конфигурации приводится к Rules напрямую.
+type Rules struct {
+	Capitalize     bool              `json:"capitalize,omitempty"`      // заглавная первая буква
+	CollapseSpaces bool              `json:"collapse_spaces,omitempty"` // несколько пробелов подряд -> один
+	Trim           bool              `json:"trim,omitempty"`            // убрать пробелы по краям
+	Replace        map[string]string `json:"replace,omitempty"`         // фраза -> замена, без учёта регистра
+}
+
+var multiSpace = regexp.MustCompile(`[ \t]{2,}`)
+
+// Transform применяет правила к тексту всегда в одном порядке:
+// замены, схлопывание пробелов, обрезка, заглавная буква.
+// Замены выполняются от длинных фраз к коротким (при равной длине - по алфавиту),
+// так что "нью лайн" срабатывает раньше, чем "лайн", независимо от порядка в JSON.
+// Фраза заменяется только целыми словами: "лайн" не трогает "онлайн".
+func Transform(text string, rules Rules) string {
+	if text == "" {
+		return text
+	}
+
+	for _, from := range replaceOrder(rules.Replace) {
+		re := regexp.MustCompile(`(?i)` + regexp.QuoteMeta(from))
+		text = replaceWords(text, re, rules.Replace[from])
+	}
+
+	if rules.CollapseSpaces {
+		text = multiSpace.ReplaceAllString(text, " ")
+	}
+
+	if rules.Trim {
+		text = strings.TrimSpace(text)
+	}
+
+	if rules.Capitalize {
+		text = capitalize(text)
+	}
+
+	return text
+}
+
+// Punctuate расставляет простую пунктуацию в тексте без неё (результат Vosk):
+// заглавная буква в начале и после конца предложения, точка в конце,
+// если текст не заканчивается знаком препинания.
+func Punctuate(text string) string {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return text
+	}
+
+	var b strings.Builder
+	b.Grow(len(text) + 1)
+	upper := true
+	for _, r := range text {
+		if upper && unicode.IsLetter(r) {
+			r = unicode.ToUpper(r)
+			upper = false
+		} else if upper && unicode.IsDigit(r) {
+			upper = false
+		}
+		if r == '.' || r == '!' || r == '?' || r == '…' {
+			upper = true
+		}
+		b.WriteRune(r)
+	}
+
+	last, _ := utf8.DecodeLastRuneInString(text)
+	if !unicode.IsPunct(last) {
+		b.WriteByte('.')
+	}
+	return b.String()
+}
+
+// replaceWords заменяет на repl совпадения re, которые не продолжают
+// соседние слова: буква или цифра на краю совпадения не должна
+// соседствовать с буквой или цифрой снаружи.
+func replaceWords(text string, re *regexp.Regexp, repl string) string {
+	var b strings.Builder
+	last := 0
+	for pos := 0; pos < len(text); {
+		loc := re.FindStringIndex(text[pos:])
+		if loc == nil || loc[0] == loc[1] {
+			break
+		}
+		start, end := pos+loc[0], pos+loc[1]
+		if !wholeWords(text, start, end) {
+			// Совпадение может начинаться и внутри отвергнутого
+			_, size := utf8.DecodeRuneInString(text[start:])
+			pos = start + size
+			continue
+		}
+		b.WriteString(text[last:start])
+		b.WriteString(repl)
+		last, pos = end, end
+	}
+	if last == 0 {
+		return text
+	}
+	b.WriteString(text[last:])
+	return b.String()
+}
+
+// wholeWords проверяет, что text[start:end] не разрывает слово.
+func wholeWords(text string, start, end int) bool {
+	first, _ := utf8.DecodeRuneInString(text[start:end])
+	before, _ := utf8.DecodeLastRuneInString(text[:start])
+	if start > 0 && isWordRune(first) && isWordRune(before) {
+		return false
+	}
+	lastRune, _ := utf8.DecodeLastRuneInString(text[start:end])
+	after, _ := utf8.DecodeRuneInString(text[end:])
+	return end == len(text) || !isWordRune(lastRune) || !isWordRune(after)
+}
+
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsNumber(r)
+}
+
+// replaceOrder возвращает ключи замен в детерминированном порядке.
+// Пустые ключи пропускаются.
+func replaceOrder(replace map[string]string) []string {
+	keys := make([]string, 0, len(replace))
+	for k := range replace {
+		if k != "" {
+			keys = append(keys, k)
+		}
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		li, lj := utf8.RuneCountInString(keys[i]), utf8.RuneCountInString(keys[j])
+		if li != lj {
+			return li > lj
+		}
+		return keys[i] < keys[j]
+	})
+	return keys
+}
+
+// capitalize делает заглавной первую букву текста, пропуская ведущие
+// пробелы и знаки препинания.
+func capitalize(text string) string {
+	for i, r := range text {
+		if unicode.IsLetter(r) {
+			if unicode.IsUpper(r) {
+				return text
+			}
+			return text[:i] + string(unicode.ToUpper(r)) + text[i+utf8.RuneLen(r):]
+		}
+		if unicode.IsDigit(r) {
+			return text
+		}
+	}
+	return text
+}
@@ -0,0 +1,57 @@
+package textproc
+
+import "testing"
+
+func TestTransformReplaceWholeWords(t *testing.T) {
+	rules := Rules{Replace: map[string]string{
+		"лайн":     "line",
+		"нью лайн": "\n",
+		"т.е.":     "то есть",
+		"доллар":   "$1",
+	}}
+	tests := []struct {
+		text, want string
+	}{
+		{"лайн", "line"},
+		{"Лайн, лайн!", "line, line!"},
+		{"онлайн", "онлайн"},
+		{"лайнер", "лайнер"},
+		{"онлайн лайн", "онлайн line"},
+		{"нью лайн", "\n"},
+		{"абзац нью лайн дальше", "абзац \n дальше"},
+		{"т.е. так", "то есть так"},
+		{"(т.е.)", "(то есть)"},
+		{"нет.е.", "нет.е."},
+		{"доллар", "$1"},
+		{"лайн2", "лайн2"},
+	}
+	for _, tt := range tests {
+		if got := Transform(tt.text, rules); got != tt.want {
+			t.Errorf("Transform(%q) = %q, want %q", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestPunctuate(t *testing.T) {
+	tests := []struct {
+		text, want string
+	}{
+		{"", ""},
+		{"   ", ""},
+		{"привет мир", "Привет мир."},
+		{"  hello world  ", "Hello world."},
+		{"первое. второе", "Первое. Второе."},
+		{"как дела? хорошо! ну… ладно", "Как дела? Хорошо! Ну… Ладно."},
+		{"уже с точкой.", "Уже с точкой."},
+		{"вопрос?", "Вопрос?"},
+		{"перечисление,", "Перечисление,"},
+		{"Уже Заглавная", "Уже Заглавная."},
+		{"42 попугая", "42 попугая."},
+		{"версия 1.5 вышла", "Версия 1.5 вышла."},
+	}
+	for _, tt := range tests {
+		if got := Punctuate(tt.text); got != tt.want {
+			t.Errorf("Punctuate(%q) = %q, want %q", tt.text, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,15 @@
+package theme
+
+import (
+	"os/exec"
+	"strings"
+)
+
+// systemPrefersDark checks AppleInterfaceStyle, which is only set in dark mode.
+func systemPrefersDark() bool {
+	out, err := exec.Command("defaults", "read", "-g", "AppleInterfaceStyle").Output()
+	if err != nil {
+		return false
+	}
+	return strings.Contains(strings.ToLower(string(out)), "dark")
+}
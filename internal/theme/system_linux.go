@@ -0,0 +1,29 @@
+package theme
+
+import (
+	"os/exec"
+	"strings"
+)
+
+// systemPrefersDark reads the GNOME color scheme, falling back to the
+// GTK theme name. Without gsettings the dark theme is assumed.
+func systemPrefersDark() bool {
+	out, err := exec.Command("gsettings", "get", "org.gnome.desktop.interface", "color-scheme").Output()
+	if err != nil {
+		return true
+	}
+	scheme := strings.ToLower(string(out))
+	if strings.Contains(scheme, "dark") {
+		return true
+	}
+	if strings.Contains(scheme, "light") {
+		return false
+	}
+
+	// "default" - older desktops encode the preference in the theme name
+	out, err = exec.Command("gsettings", "get", "org.gnome.desktop.interface", "gtk-theme").Output()
+	if err != nil {
+		return true
+	}
+	return strings.Contains(strings.ToLower(string(out)), "dark")
+}
@@ -0,0 +1,20 @@
+package theme
+
+import (
+	"os/exec"
+	"strings"
+	"syscall"
+)
+
+// systemPrefersDark reads the AppsUseLightTheme registry value.
+func systemPrefersDark() bool {
+	cmd := exec.Command("reg", "query",
+		`HKCU\Software\Microsoft\Windows\CurrentVersion\Themes\Personalize`,
+		"/v", "AppsUseLightTheme")
+	cmd.SysProcAttr = &syscall.SysProcAttr{HideWindow: true}
+	out, err := cmd.Output()
+	if err != nil {
+		return true
+	}
+	return strings.Contains(string(out), "0x0")
+}
@@ -0,0 +1,114 @@
+// Package theme holds the color palettes shared by all application windows.
+package theme
+
+import (
+	"image/color"
+	"sync"
+)
+
+// Theme names as stored in the config.
+const (
+	Dark   = "dark"
+	Light  = "light"
+	System = "system" // follow the OS light/dark preference
+)
+
+// Palette is the set of colors used by the UI windows.
+type Palette struct {
+	BG         color.NRGBA // Window background
+	Panel      color.NRGBA // Section panels
+	PanelLight color.NRGBA // Input fields, nested panels
+	Button     color.NRGBA // Neutral buttons
+	Overlay    color.NRGBA // Semi-transparent overlay over content
+	Track      color.NRGBA // Empty part of meters and bars
+	Text       color.NRGBA // Primary text
+	TextDim    color.NRGBA // Secondary text and hints
+	Accent     color.NRGBA // Primary actions, selection, spinners
+	Selected   color.NRGBA // Selected list items
+	Success    color.NRGBA // Success state, waveform
+	Warning    color.NRGBA // Warning text
+	WarningBG  color.NRGBA // Background behind warning text
+	Error      color.NRGBA // Errors, volume peaks
+}
+
+// DarkPalette is the default dark theme.
+var DarkPalette = Palette{
+	BG:         color.NRGBA{R: 30, G: 30, B: 34, A: 255},
+	Panel:      color.NRGBA{R: 45, G: 45, B: 50, A: 255},
+	PanelLight: color.NRGBA{R: 55, G: 55, B: 62, A: 255},
+	Button:     color.NRGBA{R: 70, G: 70, B: 78, A: 255},
+	Overlay:    color.NRGBA{R: 20, G: 20, B: 24, A: 220},
+	Track:      color.NRGBA{R: 35, G: 35, B: 40, A: 255},
+	Text:       color.NRGBA{R: 240, G: 240, B: 245, A: 255},
+	TextDim:    color.NRGBA{R: 140, G: 140, B: 150, A: 255},
+	Accent:     color.NRGBA{R: 88, G: 166, B: 255, A: 255},
+	Selected:   color.NRGBA{R: 60, G: 100, B: 160, A: 255},
+	Success:    color.NRGBA{R: 80, G: 200, B: 120, A: 255},
+	Warning:    color.NRGBA{R: 255, G: 180, B: 0, A: 255},
+	WarningBG:  color.NRGBA{R: 80, G: 60, B: 20, A: 255},
+	Error:      color.NRGBA{R: 255, G: 100, B: 100, A: 255},
+}
+
+// LightPalette is the light theme.
+var LightPalette = Palette{
+	BG:         color.NRGBA{R: 245, G: 245, B: 247, A: 255},
+	Panel:      color.NRGBA{R: 255, G: 255, B: 255, A: 255},
+	PanelLight: color.NRGBA{R: 232, G: 232, B: 237, A: 255},
+	Button:     color.NRGBA{R: 218, G: 218, B: 224, A: 255},
+	Overlay:    color.NRGBA{R: 245, G: 245, B: 247, A: 220},
+	Track:      color.NRGBA{R: 225, G: 225, B: 230, A: 255},
+	Text:       color.NRGBA{R: 28, G: 28, B: 32, A: 255},
+	TextDim:    color.NRGBA{R: 110, G: 110, B: 120, A: 255},
+	Accent:     color.NRGBA{R: 0, G: 122, B: 255, A: 255},
+	Selected:   color.NRGBA{R: 190, G: 215, B: 250, A: 255},
+	Success:    color.NRGBA{R: 40, G: 160, B: 80, A: 255},
+	Warning:    color.NRGBA{R: 190, G: 120, B: 0, A: 255},
+	WarningBG:  color.NRGBA{R: 255, G: 236, B: 200, A: 255},
+	Error:      color.NRGBA{R: 220, G: 60, B: 60, A: 255},
+}
+
+var (
+	mu      sync.RWMutex
+	name    = Dark
+	current = DarkPalette
+)
+
+// Names returns the selectable theme names in display order.
+func Names() []string {
+	return []string{Dark, Light, System}
+}
+
+// Set activates the named theme. "system" is resolved against the OS
+// preference at call time; unknown names fall back to dark.
+func Set(themeName string) {
+	p := DarkPalette
+	switch themeName {
+	case Light:
+		p = LightPalette
+	case System:
+		if !systemPrefersDark() {
+			p = LightPalette
+		}
+	default:
+		themeName = Dark
+	}
+
+	mu.Lock()
+	name = themeName
+	current = p
+	mu.Unlock()
+}
+
+// Name returns the active theme name.
+func Name() string {
+	mu.RLock()
+	defer mu.RUnlock()
+	return name
+}
+
+// Current returns the active palette.
+func Current() Palette {
+	mu.RLock()
+	defer mu.RUnlock()
+	return current
+}
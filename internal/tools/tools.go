@@ -0,0 +1,146 @@
+// Package tools проверяет внешние программы, через которые приложение
+// в Linux вводит текст, копирует в буфер обмена и управляет окнами.
+package tools
+
+import (
+	"fmt"
+	"os/exec"
+	"strings"
+	"sync"
+)
+
+// Feature - возможность приложения, которая зависит от внешней программы.
+type Feature string
+
+const (
+	FeatureType   Feature = "type"   // ввод текста в активное окно
+	FeatureCopy   Feature = "copy"   // копирование в буфер обмена
+	FeatureWindow Feature = "window" // положение окна записи и «поверх всех окон»
+)
+
+// Tool - внешняя программа. Если Names несколько, достаточно любой из них;
+// они перечислены в порядке предпочтения.
+type Tool struct {
+	Names   []string
+	Feature Feature
+}
+
+// Name возвращает имена программы для сообщения: "wmctrl/xprop".
+func (t Tool) Name() string {
+	return strings.Join(t.Names, "/")
+}
+
+// available возвращает true, если в PATH есть хотя бы одна из программ.
+func (t Tool) available() bool {
+	return t.first() != ""
+}
+
+// first возвращает первую из программ, которая есть в PATH, или "".
+func (t Tool) first() string {
+	for _, name := range t.Names {
+		if _, err := exec.LookPath(name); err == nil {
+			return name
+		}
+	}
+	return ""
+}
+
+// MissingError - нужная программа не установлена.
+type MissingError struct {
+	Name string
+}
+
+func (e *MissingError) Error() string {
+	return "установите " + e.Name
+}
+
+// Check возвращает *MissingError, если программы name нет в PATH.
+// Вызывается перед запуском, чтобы вместо ошибки exec пользователь увидел,
+// что нужно установить.
+func Check(name string) error {
+	if _, err := exec.LookPath(name); err != nil {
+		return &MissingError{Name: name}
+	}
+	return nil
+}
+
+// Missing возвращает программы текущего сеанса (X11 или Wayland),
+// которых нет в системе. На других платформах пусто.
+func Missing() []Tool {
+	var missing []Tool
+	for _, t := range required() {
+		if !t.available() {
+			missing = append(missing, t)
+		}
+	}
+	return missing
+}
+
+// Strategy - программы, выбранные для каждой возможности в текущем сеансе.
+// Возможности выбираются независимо, поэтому работают и смешанные
+// установки: например, wl-copy для буфера обмена и ydotool для ввода,
+// если wtype не установлен.
+type Strategy struct {
+	Session  string             // "wayland", "x11" или "" на других платформах
+	tools    []Tool             // нужные программы сеанса
+	programs map[Feature]string // выбранная программа, "" - ни одной нет
+}
+
+var (
+	strategyOnce sync.Once
+	strategy     Strategy
+)
+
+// Current возвращает стратегию текущего сеанса. Программы ищутся в PATH
+// один раз, при первом вызове.
+func Current() Strategy {
+	strategyOnce.Do(func() {
+		strategy = Strategy{
+			Session:  session(),
+			tools:    required(),
+			programs: make(map[Feature]string),
+		}
+		for _, t := range strategy.tools {
+			strategy.programs[t.Feature] = t.first()
+		}
+	})
+	return strategy
+}
+
+// Program возвращает программу, выбранную для возможности f, или "".
+func (s Strategy) Program(f Feature) string {
+	return s.programs[f]
+}
+
+// Require возвращает программу для возможности f или *MissingError
+// со всеми подходящими программами, если не установлена ни одна.
+// На платформах без внешних программ возвращает "" без ошибки.
+func (s Strategy) Require(f Feature) (string, error) {
+	for _, t := range s.tools {
+		if t.Feature != f {
+			continue
+		}
+		if name := s.programs[f]; name != "" {
+			return name, nil
+		}
+		return "", &MissingError{Name: t.Name()}
+	}
+	return "", nil
+}
+
+// String описывает стратегию одной строкой для журнала:
+// "session=wayland type=ydotool copy=wl-copy". Ненайденная программа - "-".
+func (s Strategy) String() string {
+	parts := make([]string, 0, len(s.tools)+1)
+	if s.Session != "" {
+		parts = append(parts, "session="+s.Session)
+	}
+	for _, t := range s.tools {
+		name := s.programs[t.Feature]
+		if name == "" {
+			name = "-"
+		}
+		parts = append(parts, fmt.Sprintf("%s=%s", t.Feature, name))
+	}
+	return strings.Join(parts, " ")
+}
@@ -0,0 +1,31 @@
+//go:build linux
+
+package tools
+
+import "os"
+
+// session возвращает тип графического сеанса: "wayland" или "x11".
+func session() string {
+	if os.Getenv("WAYLAND_DISPLAY") != "" {
+		return "wayland"
+	}
+	return "x11"
+}
+
+// required возвращает программы, нужные в текущем сеансе, в порядке
+// предпочтения. ydotool работает через uinput и в X11, и в Wayland, но
+// требует запущенного ydotoold, поэтому он запасной вариант. Под Wayland
+// xdotool и xclip работают только с окнами XWayland.
+func required() []Tool {
+	if session() == "wayland" {
+		return []Tool{
+			{Names: []string{"wtype", "ydotool", "xdotool"}, Feature: FeatureType},
+			{Names: []string{"wl-copy", "xclip"}, Feature: FeatureCopy},
+		}
+	}
+	return []Tool{
+		{Names: []string{"xdotool", "ydotool"}, Feature: FeatureType},
+		{Names: []string{"xclip", "xsel"}, Feature: FeatureCopy},
+		{Names: []string{"wmctrl", "xprop"}, Feature: FeatureWindow},
+	}
+}
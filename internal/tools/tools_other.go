@@ -0,0 +1,14 @@
+//go:build !linux
+
+package tools
+
+// session пуст: графический сеанс различается только в Linux.
+func session() string {
+	return ""
+}
+
+// required пуст: в Windows и macOS ввод и буфер обмена работают через
+// системные API без внешних программ.
+func required() []Tool {
+	return nil
+}
@@ -2,6 +2,8 @@
 package tray
 
 import (
+	"sync"
+
 	"github.com/getlantern/systray"
 	"shofar/embedded"
 	"shofar/internal/i18n"
@@ -14,12 +16,41 @@ const (
 	StateIdle State = iota
 	StateRecording
 	StateProcessing
+	StateLoading // загружается модель распознавания
+	StateError   // модель не загрузилась, распознавание недоступно
+	StatePaused  // горячие клавиши отключены; в ожидании так же показывает SetPaused
 )
 
+// IconStyle - набор иконок трея.
+type IconStyle string
+
+const (
+	// IconColor - цветные иконки, состояние видно по цвету.
+	IconColor IconStyle = "color"
+	// IconTemplate - монохромные иконки: macOS перекрашивает их под светлую
+	// и тёмную строку меню, состояние видно по форме.
+	IconTemplate IconStyle = "template"
+)
+
+// ModelItem - модель распознавания в подменю "Модель".
+type ModelItem struct {
+	ID   string
+	Name string
+}
+
 // Callbacks содержит обработчики событий меню.
 type Callbacks struct {
 	OnNotificationsToggle func() bool
+	OnPauseToggle         func() bool // возвращает true если пауза включена
+	OnModelSelect         func(modelID string)
+	OnAutostartToggle     func() bool // возвращает true если автозапуск включён
 	OnSettingsClick       func()
+	OnResetPosition       func()
+	OnSaveRecording       func() // nil - пункт сохранения записи не показывается
+	OnExport              func()
+	OnStats               func() // nil - пункт статистики не показывается
+	OnOpenLog             func()
+	OnReloadConfig        func()
 	OnQuit                func()
 }
 
@@ -27,15 +58,37 @@ type Callbacks struct {
 type Tray struct {
 	callbacks   Callbacks
 	notifyOn    *systray.MenuItem
+	pauseBtn    *systray.MenuItem
+	modelMenu   *systray.MenuItem
+	autostart   *systray.MenuItem
 	status      *systray.MenuItem
 	settingsBtn *systray.MenuItem
+	resetPosBtn *systray.MenuItem
+	saveRecBtn  *systray.MenuItem
+	exportBtn   *systray.MenuItem
+	statsBtn    *systray.MenuItem
+	statsText   string // последняя строка из SetStats
+	openLogBtn  *systray.MenuItem
+	reloadBtn   *systray.MenuItem
 	quitBtn     *systray.MenuItem
+
+	mu     sync.Mutex
+	state  State
+	paused bool // горячие клавиши отключены, в ожидании показывается IconPaused
+	icons  IconStyle
+
+	// Пункты подменю моделей. systray не умеет удалять пункты, поэтому
+	// пункты моделей, которых больше нет в списке, только скрываются
+	modelItems   map[string]*systray.MenuItem
+	models       []ModelItem // последний список из SetModels
+	currentModel string
 }
 
 // New создаёт новый Tray.
 func New(callbacks Callbacks) *Tray {
 	return &Tray{
 		callbacks: callbacks,
+		icons:     IconColor,
 	}
 }
 
@@ -50,7 +103,7 @@ func (t *Tray) Run(onReady func()) {
 }
 
 func (t *Tray) onReady() {
-	systray.SetIcon(embedded.IconIdle)
+	t.setIcon(embedded.IconIdle, embedded.IconIdleTemplate)
 	systray.SetTitle("Shofar")
 	systray.SetTooltip(i18n.T("app_tooltip"))
 
@@ -63,9 +116,45 @@ func (t *Tray) onReady() {
 	// Уведомления
 	t.notifyOn = systray.AddMenuItemCheckbox(i18n.T("tray_notifications"), i18n.T("tray_notifications_hint"), true)
 
+	// Пауза горячих клавиш
+	t.pauseBtn = systray.AddMenuItemCheckbox(i18n.T("tray_pause"), i18n.T("tray_pause_hint"), false)
+
+	// Быстрое переключение модели
+	t.modelMenu = systray.AddMenuItem(i18n.T("tray_model"), i18n.T("tray_model_hint"))
+	t.mu.Lock()
+	t.renderModels()
+	t.mu.Unlock()
+
+	// Автозапуск при входе в систему
+	t.autostart = systray.AddMenuItemCheckbox(i18n.T("tray_autostart"), i18n.T("tray_autostart_hint"), false)
+
 	// Настройки
 	t.settingsBtn = systray.AddMenuItem(i18n.T("tray_settings"), i18n.T("tray_settings_hint"))
 
+	// Сброс позиции окна записи
+	t.resetPosBtn = systray.AddMenuItem(i18n.T("tray_reset_position"), i18n.T("tray_reset_position_hint"))
+
+	// Сохранение последней записи (только в режиме отладки)
+	if t.callbacks.OnSaveRecording != nil {
+		t.saveRecBtn = systray.AddMenuItem(i18n.T("tray_save_recording"), i18n.T("tray_save_recording_hint"))
+	}
+
+	// Экспорт распознанного за сессию текста
+	t.exportBtn = systray.AddMenuItem(i18n.T("tray_export"), i18n.T("tray_export_hint"))
+
+	// Статистика диктовки (только если включена)
+	if t.callbacks.OnStats != nil {
+		t.mu.Lock()
+		t.statsBtn = systray.AddMenuItem(t.statsTitle(), i18n.T("tray_stats_hint"))
+		t.mu.Unlock()
+	}
+
+	// Журнал
+	t.openLogBtn = systray.AddMenuItem(i18n.T("tray_open_log"), i18n.T("tray_open_log_hint"))
+
+	// Перечитать config.json после ручной правки
+	t.reloadBtn = systray.AddMenuItem(i18n.T("tray_reload_config"), i18n.T("tray_reload_config_hint"))
+
 	systray.AddSeparator()
 
 	// Выход
@@ -76,6 +165,15 @@ func (t *Tray) onReady() {
 }
 
 func (t *Tray) handleMenuEvents() {
+	// Пункта сохранения может не быть: nil канал никогда не сработает
+	var saveRecCh, statsCh <-chan struct{}
+	if t.saveRecBtn != nil {
+		saveRecCh = t.saveRecBtn.ClickedCh
+	}
+	if t.statsBtn != nil {
+		statsCh = t.statsBtn.ClickedCh
+	}
+
 	for {
 		select {
 		// Уведомления
@@ -89,12 +187,56 @@ func (t *Tray) handleMenuEvents() {
 				}
 			}
 
+		// Пауза горячих клавиш
+		case <-t.pauseBtn.ClickedCh:
+			if t.callbacks.OnPauseToggle != nil {
+				t.SetPaused(t.callbacks.OnPauseToggle())
+			}
+
+		// Автозапуск
+		case <-t.autostart.ClickedCh:
+			if t.callbacks.OnAutostartToggle != nil {
+				t.SetAutostart(t.callbacks.OnAutostartToggle())
+			}
+
 		// Настройки
 		case <-t.settingsBtn.ClickedCh:
 			if t.callbacks.OnSettingsClick != nil {
 				t.callbacks.OnSettingsClick()
 			}
 
+		// Сброс позиции окна записи
+		case <-t.resetPosBtn.ClickedCh:
+			if t.callbacks.OnResetPosition != nil {
+				t.callbacks.OnResetPosition()
+			}
+
+		// Сохранение последней записи
+		case <-saveRecCh:
+			t.callbacks.OnSaveRecording()
+
+		// Журнал
+		case <-t.openLogBtn.ClickedCh:
+			if t.callbacks.OnOpenLog != nil {
+				t.callbacks.OnOpenLog()
+			}
+
+		// Экспорт
+		case <-t.exportBtn.ClickedCh:
+			if t.callbacks.OnExport != nil {
+				t.callbacks.OnExport()
+			}
+
+		// Статистика
+		case <-statsCh:
+			t.callbacks.OnStats()
+
+		// Перечитать настройки
+		case <-t.reloadBtn.ClickedCh:
+			if t.callbacks.OnReloadConfig != nil {
+				t.callbacks.OnReloadConfig()
+			}
+
 		// Выход
 		case <-t.quitBtn.ClickedCh:
 			if t.callbacks.OnQuit != nil {
@@ -105,28 +247,190 @@ func (t *Tray) handleMenuEvents() {
 	}
 }
 
+// SetStats показывает строку статистики в пункте меню.
+func (t *Tray) SetStats(text string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.statsText = text
+	if t.statsBtn != nil {
+		t.statsBtn.SetTitle(t.statsTitle())
+	}
+}
+
+// statsTitle возвращает заголовок пункта статистики. Вызывается под t.mu.
+func (t *Tray) statsTitle() string {
+	if t.statsText == "" {
+		return i18n.T("tray_stats")
+	}
+	return i18n.T("tray_stats") + ": " + t.statsText
+}
+
+// SetAutostart отмечает пункт автозапуска.
+func (t *Tray) SetAutostart(enabled bool) {
+	if t.autostart == nil {
+		return
+	}
+	if enabled {
+		t.autostart.Check()
+	} else {
+		t.autostart.Uncheck()
+	}
+}
+
+// SetModels обновляет подменю моделей: items - скачанные модели
+// распознавания, current - ID текущей, она отмечается галочкой.
+// Можно вызывать до запуска трея, список применится при его готовности.
+func (t *Tray) SetModels(items []ModelItem, current string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.models = items
+	t.currentModel = current
+	t.renderModels()
+}
+
+// renderModels приводит подменю моделей к последнему списку. Вызывается под t.mu.
+func (t *Tray) renderModels() {
+	if t.modelMenu == nil {
+		return
+	}
+	if t.modelItems == nil {
+		t.modelItems = make(map[string]*systray.MenuItem)
+	}
+
+	listed := make(map[string]bool, len(t.models))
+	for _, m := range t.models {
+		listed[m.ID] = true
+		item := t.modelItems[m.ID]
+		if item == nil {
+			item = t.modelMenu.AddSubMenuItemCheckbox(m.Name, "", false)
+			t.modelItems[m.ID] = item
+			go t.handleModelClicks(m.ID, item)
+		}
+		item.SetTitle(m.Name)
+		item.Show()
+		if m.ID == t.currentModel {
+			item.Check()
+		} else {
+			item.Uncheck()
+		}
+	}
+	for id, item := range t.modelItems {
+		if !listed[id] {
+			item.Hide()
+		}
+	}
+
+	if len(t.models) == 0 {
+		t.modelMenu.Disable()
+	} else {
+		t.modelMenu.Enable()
+	}
+}
+
+// handleModelClicks обрабатывает выбор модели в подменю. systray снимает
+// и ставит галочку сам, поэтому отметку восстанавливает SetModels после
+// переключения.
+func (t *Tray) handleModelClicks(modelID string, item *systray.MenuItem) {
+	for range item.ClickedCh {
+		if t.callbacks.OnModelSelect != nil {
+			t.callbacks.OnModelSelect(modelID)
+		}
+	}
+}
+
+// SetNotifications отмечает пункт уведомлений по фактическому состоянию.
+func (t *Tray) SetNotifications(enabled bool) {
+	if t.notifyOn == nil {
+		return
+	}
+	if enabled {
+		t.notifyOn.Check()
+	} else {
+		t.notifyOn.Uncheck()
+	}
+}
 
 // SetState устанавливает состояние приложения и обновляет иконку.
 func (t *Tray) SetState(state State) {
+	t.mu.Lock()
+	t.state = state
+	paused := t.paused
+	t.mu.Unlock()
+
+	t.render(state, paused)
+}
+
+// SetPaused отмечает паузу горячих клавиш: пункт меню и иконка в ожидании.
+func (t *Tray) SetPaused(paused bool) {
+	t.mu.Lock()
+	t.paused = paused
+	state := t.state
+	t.mu.Unlock()
+
+	if t.pauseBtn != nil {
+		if paused {
+			t.pauseBtn.Check()
+		} else {
+			t.pauseBtn.Uncheck()
+		}
+	}
+	t.render(state, paused)
+}
+
+// SetIconStyle выбирает набор иконок. Можно вызывать и до Run.
+func (t *Tray) SetIconStyle(style IconStyle) {
+	t.mu.Lock()
+	t.icons = style
+	state, paused := t.state, t.paused
+	t.mu.Unlock()
+
+	// До onReady иконку покажет сам onReady
+	if t.status != nil {
+		t.render(state, paused)
+	}
+}
+
+// setIcon показывает цветную иконку или её template-вариант.
+func (t *Tray) setIcon(color, template []byte) {
+	t.mu.Lock()
+	style := t.icons
+	t.mu.Unlock()
+
+	if style == IconTemplate {
+		systray.SetTemplateIcon(template, template)
+		return
+	}
+	systray.SetIcon(color)
+}
+
+func (t *Tray) render(state State, paused bool) {
+	// Пауза видна только в ожидании: запись и загрузка важнее
+	if state == StateIdle && paused {
+		state = StatePaused
+	}
+
 	switch state {
 	case StateIdle:
-		systray.SetIcon(embedded.IconIdle)
-		systray.SetTooltip("Shofar - " + i18n.T("tray_ready"))
-		if t.status != nil {
-			t.status.SetTitle(i18n.T("tray_ready"))
-		}
+		t.setStatus(embedded.IconIdle, embedded.IconIdleTemplate, "tray_ready")
 	case StateRecording:
-		systray.SetIcon(embedded.IconRecording)
-		systray.SetTooltip("Shofar - " + i18n.T("tray_recording"))
-		if t.status != nil {
-			t.status.SetTitle(i18n.T("tray_recording"))
-		}
+		t.setStatus(embedded.IconRecording, embedded.IconRecordingTemplate, "tray_recording")
 	case StateProcessing:
-		systray.SetIcon(embedded.IconProcessing)
-		systray.SetTooltip("Shofar - " + i18n.T("tray_processing"))
-		if t.status != nil {
-			t.status.SetTitle(i18n.T("tray_processing"))
-		}
+		t.setStatus(embedded.IconProcessing, embedded.IconProcessingTemplate, "tray_processing")
+	case StateLoading:
+		t.setStatus(embedded.IconLoading, embedded.IconLoadingTemplate, "tray_loading")
+	case StateError:
+		t.setStatus(embedded.IconError, embedded.IconErrorTemplate, "tray_error")
+	case StatePaused:
+		t.setStatus(embedded.IconPaused, embedded.IconPausedTemplate, "tray_paused")
+	}
+}
+
+// setStatus показывает иконку состояния, подсказку и строку статуса в меню.
+func (t *Tray) setStatus(color, template []byte, key string) {
+	t.setIcon(color, template)
+	systray.SetTooltip("Shofar - " + i18n.T(key))
+	if t.status != nil {
+		t.status.SetTitle(i18n.T(key))
 	}
 }
 
@@ -141,19 +445,55 @@ func (t *Tray) Quit() {
 
 // RefreshUI обновляет все тексты меню на текущем языке.
 func (t *Tray) RefreshUI() {
-	systray.SetTooltip(i18n.T("app_tooltip"))
-
+	// Подсказка и строка статуса зависят от состояния
+	t.mu.Lock()
+	state, paused := t.state, t.paused
+	t.mu.Unlock()
 	if t.status != nil {
-		t.status.SetTitle(i18n.T("tray_ready"))
+		t.render(state, paused)
 	}
 	if t.notifyOn != nil {
 		t.notifyOn.SetTitle(i18n.T("tray_notifications"))
 		t.notifyOn.SetTooltip(i18n.T("tray_notifications_hint"))
 	}
+	if t.pauseBtn != nil {
+		t.pauseBtn.SetTitle(i18n.T("tray_pause"))
+		t.pauseBtn.SetTooltip(i18n.T("tray_pause_hint"))
+	}
+	if t.modelMenu != nil {
+		t.modelMenu.SetTitle(i18n.T("tray_model"))
+		t.modelMenu.SetTooltip(i18n.T("tray_model_hint"))
+	}
+	if t.autostart != nil {
+		t.autostart.SetTitle(i18n.T("tray_autostart"))
+		t.autostart.SetTooltip(i18n.T("tray_autostart_hint"))
+	}
 	if t.settingsBtn != nil {
 		t.settingsBtn.SetTitle(i18n.T("tray_settings"))
 		t.settingsBtn.SetTooltip(i18n.T("tray_settings_hint"))
 	}
+	if t.resetPosBtn != nil {
+		t.resetPosBtn.SetTitle(i18n.T("tray_reset_position"))
+		t.resetPosBtn.SetTooltip(i18n.T("tray_reset_position_hint"))
+	}
+	if t.saveRecBtn != nil {
+		t.saveRecBtn.SetTitle(i18n.T("tray_save_recording"))
+		t.saveRecBtn.SetTooltip(i18n.T("tray_save_recording_hint"))
+	}
+	if t.exportBtn != nil {
+		t.exportBtn.SetTitle(i18n.T("tray_export"))
+		t.exportBtn.SetTooltip(i18n.T("tray_export_hint"))
+	}
+	if t.statsBtn != nil {
+		t.mu.Lock()
+		t.statsBtn.SetTitle(t.statsTitle())
+		t.mu.Unlock()
+		t.statsBtn.SetTooltip(i18n.T("tray_stats_hint"))
+	}
+	if t.reloadBtn != nil {
+		t.reloadBtn.SetTitle(i18n.T("tray_reload_config"))
+		t.reloadBtn.SetTooltip(i18n.T("tray_reload_config_hint"))
+	}
 	if t.quitBtn != nil {
 		t.quitBtn.SetTitle(i18n.T("tray_quit"))
 		t.quitBtn.SetTooltip(i18n.T("tray_quit_hint"))
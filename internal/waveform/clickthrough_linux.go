@@ -0,0 +1,54 @@
+//go:build linux
+
+package waveform
+
+/*
+#cgo linux pkg-config: x11 xfixes
+#include <X11/Xlib.h>
+#include <X11/extensions/Xfixes.h>
+#include <X11/extensions/shape.h>
+
+// set_input_passthrough replaces the input shape of the window with an empty
+// region (pointer events fall through to windows below) or resets it to the
+// default (the whole window).
+static int set_input_passthrough(unsigned long win, int enabled) {
+	Display *dpy = XOpenDisplay(NULL);
+	if (dpy == NULL) {
+		return 0;
+	}
+	XserverRegion region = None;
+	if (enabled) {
+		region = XFixesCreateRegion(dpy, NULL, 0);
+	}
+	XFixesSetWindowShapeRegion(dpy, (Window)win, ShapeInput, 0, 0, region);
+	if (region != None) {
+		XFixesDestroyRegion(dpy, region);
+	}
+	XSync(dpy, False);
+	XCloseDisplay(dpy);
+	return 1;
+}
+*/
+import "C"
+
+import "strconv"
+
+// setClickThrough makes the window transparent to mouse input (or restores
+// normal input) by changing its X11 input shape. Keyboard focus is not
+// affected. Does nothing if the window can't be found, e.g. on Wayland.
+func setClickThrough(windowTitle string, enabled bool) {
+	windowID := findWindowID(windowTitle)
+	if windowID == "" {
+		return
+	}
+	id, err := strconv.ParseUint(windowID, 10, 64)
+	if err != nil {
+		return
+	}
+
+	var on C.int
+	if enabled {
+		on = 1
+	}
+	C.set_input_passthrough(C.ulong(id), on)
+}
@@ -0,0 +1,10 @@
+//go:build linux && nowayland
+
+package waveform
+
+// waylandSession always returns false: built with the nowayland tag, Gio
+// opens X11 windows (XWayland under a Wayland compositor), which the X11
+// tools can position.
+func waylandSession() bool {
+	return false
+}
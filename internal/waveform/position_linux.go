@@ -3,43 +3,47 @@
 package waveform
 
 import (
+	"image"
 	"os/exec"
 	"strconv"
 	"strings"
 	"time"
 )
 
-// positionWindow positions the window in the bottom-right corner of the screen
-// and sets it to always-on-top. This function should be called after the window
-// is created and visible.
-func positionWindow(windowTitle string, width, height int) {
-	// Give the window time to appear
-	time.Sleep(100 * time.Millisecond)
-
-	// Get screen dimensions using xdotool
-	screenWidth, screenHeight := getScreenSize()
-	if screenWidth == 0 || screenHeight == 0 {
+// positionWindow positions the window at the saved position, or in the
+// bottom-right corner of the screen if pos is nil, and sets it to
+// always-on-top. This function should be called after the window is created
+// and visible. On Wayland the compositor places the window and this does
+// nothing.
+func positionWindow(windowTitle string, width, height int, pos *image.Point) {
+	if waylandSession() {
 		return
 	}
 
-	// Calculate position (bottom-right corner with padding)
-	x := screenWidth - width - 20
-	y := screenHeight - height - 60 // Account for taskbar
+	// Give the window time to appear
+	time.Sleep(100 * time.Millisecond)
 
-	// Find window by title and move it
-	cmd := exec.Command("xdotool", "search", "--name", windowTitle)
-	output, err := cmd.Output()
-	if err != nil {
-		return
+	var x, y int
+	if pos != nil {
+		x, y = pos.X, pos.Y
+	} else {
+		// Get screen dimensions using xdotool
+		screenWidth, screenHeight := getScreenSize()
+		if screenWidth == 0 || screenHeight == 0 {
+			return
+		}
+
+		// Calculate position (bottom-right corner with padding)
+		x = screenWidth - width - 20
+		y = screenHeight - height - 60 // Account for taskbar
 	}
 
-	windowIDs := strings.Fields(string(output))
-	if len(windowIDs) == 0 {
+	// Find window by title and move it
+	windowID := findWindowID(windowTitle)
+	if windowID == "" {
 		return
 	}
 
-	windowID := windowIDs[0]
-
 	// Move window to position
 	moveCmd := exec.Command("xdotool", "windowmove", windowID, strconv.Itoa(x), strconv.Itoa(y))
 	moveCmd.Run()
@@ -54,6 +58,62 @@ func positionWindow(windowTitle string, width, height int) {
 	}
 }
 
+// windowPosition returns the current top-left corner of the window
+// using xdotool getwindowgeometry.
+func windowPosition(windowTitle string) (image.Point, bool) {
+	windowID := findWindowID(windowTitle)
+	if windowID == "" {
+		return image.Point{}, false
+	}
+
+	cmd := exec.Command("xdotool", "getwindowgeometry", "--shell", windowID)
+	output, err := cmd.Output()
+	if err != nil {
+		return image.Point{}, false
+	}
+
+	// Output format: X=100\nY=200\nWIDTH=360\nHEIGHT=100\nSCREEN=0
+	var pos image.Point
+	var hasX, hasY bool
+	for _, line := range strings.Fields(string(output)) {
+		name, value, ok := strings.Cut(line, "=")
+		if !ok {
+			continue
+		}
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			continue
+		}
+		switch name {
+		case "X":
+			pos.X, hasX = n, true
+		case "Y":
+			pos.Y, hasY = n, true
+		}
+	}
+	return pos, hasX && hasY
+}
+
+// findWindowID returns the X11 window ID for the window with the given title,
+// or "" on Wayland, where there is no X11 window to find.
+func findWindowID(windowTitle string) string {
+	if waylandSession() {
+		return ""
+	}
+
+	cmd := exec.Command("xdotool", "search", "--name", windowTitle)
+	output, err := cmd.Output()
+	if err != nil {
+		return ""
+	}
+
+	windowIDs := strings.Fields(string(output))
+	if len(windowIDs) == 0 {
+		return ""
+	}
+	return windowIDs[0]
+}
+
 // getScreenSize returns the screen dimensions using xdotool.
 func getScreenSize() (width, height int) {
 	cmd := exec.Command("xdotool", "getdisplaygeometry")
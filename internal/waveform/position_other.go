@@ -2,8 +2,18 @@
 
 package waveform
 
+import "image"
+
 // positionWindow is a stub for non-Linux platforms.
 // Window positioning is platform-specific and not yet implemented for this OS.
-func positionWindow(windowTitle string, width, height int) {
+func positionWindow(windowTitle string, width, height int, pos *image.Point) {
 	// TODO: Implement for Windows/macOS
 }
+
+// windowPosition is a stub for non-Linux platforms.
+func windowPosition(windowTitle string) (image.Point, bool) {
+	return image.Point{}, false
+}
+
+// setClickThrough is a stub for non-Linux platforms.
+func setClickThrough(windowTitle string, enabled bool) {}
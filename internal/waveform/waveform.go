@@ -2,6 +2,7 @@
 package waveform
 
 import (
+	"fmt"
 	"image"
 	"image/color"
 	"sync"
@@ -15,7 +16,9 @@ import (
 	"gioui.org/unit"
 	"gioui.org/widget"
 
+	"shofar/internal/focus"
 	"shofar/internal/i18n"
+	"shofar/internal/theme"
 )
 
 // State represents the window display state.
@@ -26,12 +29,21 @@ const (
 	StateSpeechProcess              // Speech-to-text processing
 	StateLLMProcess                 // LLM text correction
 	StateResult                     // Show recognition result
+	StateModelLoading               // Recording is queued until the model loads
+)
+
+// Result view dimensions in dp.
+const (
+	resultWidth  = 450
+	resultHeight = 220
 )
 
 // SampleProvider provides audio samples for visualization.
 type SampleProvider interface {
 	GetSamples() []float32
 	IsRecording() bool
+	// ClipRatio returns the share of clipped samples in the current recording.
+	ClipRatio() float64
 }
 
 // Config holds window configuration.
@@ -46,22 +58,89 @@ type Config struct {
 	TextDimColor color.NRGBA   // Dim text color
 	AccentColor  color.NRGBA   // Accent color (for spinners)
 	PanelColor   color.NRGBA   // Panel background
+	TrackColor   color.NRGBA   // Empty part of the volume bar
 }
 
-// DefaultConfig returns default configuration.
+// Size is a preset of the recording window dimensions.
+type Size string
+
+const (
+	SizeCompact Size = "compact" // Small pill for small screens
+	SizeNormal  Size = "normal"
+	SizeLarge   Size = "large" // Bigger waveform for large monitors
+)
+
+// Dimensions returns the window width and height in dp.
+// Unknown sizes are treated as SizeNormal.
+func (s Size) Dimensions() (width, height int) {
+	switch s {
+	case SizeCompact:
+		return 240, 64
+	case SizeLarge:
+		return 520, 150
+	default:
+		return 360, 100
+	}
+}
+
+// Next returns the size Tab switches to: compact → normal → large → compact.
+func (s Size) Next() Size {
+	switch s {
+	case SizeCompact:
+		return SizeNormal
+	case SizeLarge:
+		return SizeCompact
+	default:
+		return SizeLarge
+	}
+}
+
+// DefaultConfig returns default configuration with colors from the active theme.
 func DefaultConfig() Config {
-	return Config{
-		Width:        360,
-		Height:       100,
-		RefreshRate:  33 * time.Millisecond, // ~30fps
-		BGColor:      color.NRGBA{R: 30, G: 30, B: 34, A: 245},
-		WaveColor:    color.NRGBA{R: 80, G: 200, B: 120, A: 255},
-		VolumeColor:  color.NRGBA{R: 255, G: 100, B: 100, A: 255},
-		TextColor:    color.NRGBA{R: 240, G: 240, B: 245, A: 255},
-		TextDimColor: color.NRGBA{R: 140, G: 140, B: 150, A: 255},
-		AccentColor:  color.NRGBA{R: 88, G: 166, B: 255, A: 255},
-		PanelColor:   color.NRGBA{R: 45, G: 45, B: 50, A: 255},
+	cfg := Config{
+		RefreshRate: 33 * time.Millisecond, // ~30fps
+	}
+	cfg.ApplySize(SizeNormal)
+	cfg.ApplyPalette(theme.Current())
+	return cfg
+}
+
+// ApplySize sets the recording window dimensions from a size preset.
+func (c *Config) ApplySize(s Size) {
+	c.Width, c.Height = s.Dimensions()
+}
+
+// ApplyPalette sets the config colors from a theme palette.
+func (c *Config) ApplyPalette(p theme.Palette) {
+	c.BGColor = p.BG
+	c.BGColor.A = 245 // Slightly translucent
+	c.WaveColor = p.Success
+	c.VolumeColor = p.Error
+	c.TextColor = p.Text
+	c.TextDimColor = p.TextDim
+	c.AccentColor = p.Accent
+	c.PanelColor = p.Panel
+	c.TrackColor = p.Track
+}
+
+// Timings holds how long each phase of the last recording took.
+type Timings struct {
+	Recording   time.Duration
+	Recognition time.Duration
+	Correction  time.Duration // zero if LLM correction did not run
+}
+
+// String formats the timings as "rec 3.2s · asr 1.1s · llm 2.4s".
+// Zero Timings format as an empty string.
+func (t Timings) String() string {
+	if t == (Timings{}) {
+		return ""
+	}
+	s := fmt.Sprintf("rec %.1fs · asr %.1fs", t.Recording.Seconds(), t.Recognition.Seconds())
+	if t.Correction > 0 {
+		s += fmt.Sprintf(" · llm %.1fs", t.Correction.Seconds())
 	}
+	return s
 }
 
 // Window manages the floating waveform visualization.
@@ -73,14 +152,40 @@ type Window struct {
 	state     State
 
 	// Result display
-	resultText string
-	editor     widget.Editor
-	insertBtn  widget.Clickable
-	copyBtn    widget.Clickable
-	closeBtn   widget.Clickable
-	onInsert   func(text string) // callback when insert is clicked (or Enter)
-	onCopy     func(text string) // callback when copy is clicked
-	onCancel   func()            // callback when cancelled (ESC or close button)
+	originalText  string  // recognized text (with user edits)
+	correctedText string  // LLM-corrected text (with user edits), empty if LLM is off
+	showOriginal  bool    // editor shows originalText instead of correctedText
+	lowConfidence bool    // recognizer was unsure, hint to re-record
+	timings       Timings // phase durations shown under the result, zero hides them
+	selectAll     bool    // the result opens focused with all text selected
+	focusEditor   bool    // focus the editor on the next frame
+	partialText   string  // live text while recording or recognizing in chunks
+	originalTab   widget.Clickable
+	correctedTab  widget.Clickable
+	editor        widget.Editor
+	insertBtn     widget.Clickable
+	copyBtn       widget.Clickable
+	closeBtn      widget.Clickable
+	redoBtn       widget.Clickable
+	onInsert      func(text string) // callback when insert is clicked (or Enter)
+	onCopy        func(text string) // callback when copy is clicked
+	onCancel      func()            // callback when cancelled (ESC or close button)
+	onRedo        func()            // callback to discard the result and record again
+
+	// Window position
+	position         *image.Point      // saved position; nil means bottom-right corner
+	onPositionChange func(image.Point) // callback with the position captured on hide
+
+	// Recording window size, cycled with Tab
+	size         Size
+	onSizeChange func(Size)
+
+	// The window opens without taking keyboard focus (X11)
+	noStealFocus bool
+
+	// Mouse input passes through the window while recording
+	clickThrough bool
+	clickMu      sync.Mutex // serializes input shape updates
 
 	window  *app.Window
 	running bool
@@ -110,6 +215,9 @@ func (w *Window) Show() {
 			w.window.Option(app.Size(unit.Dp(w.config.Width), unit.Dp(w.config.Height)))
 			w.window.Invalidate()
 		}
+		if w.clickThrough {
+			go w.updateClickThrough()
+		}
 		return
 	}
 
@@ -122,12 +230,60 @@ func (w *Window) Show() {
 	go w.runEventLoop()
 }
 
+// ShowResult opens the window straight in the result state. Used when the
+// recording itself was not shown and the result still needs the user.
+// If the window is already open, it just switches to the result.
+func (w *Window) ShowResult(original, corrected string) {
+	w.SetResult(original, corrected)
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.running {
+		return
+	}
+	w.running = true
+	w.stopCh = make(chan struct{})
+	w.doneCh = make(chan struct{})
+	w.startTime = time.Now()
+
+	go w.runEventLoop()
+}
+
 // Hide closes the waveform window.
 func (w *Window) Hide() {
+	if !w.IsVisible() {
+		return
+	}
+
+	// Capture the position before the window disappears so it reopens
+	// where the user dragged it.
+	if pos, ok := windowPosition(windowTitle); ok {
+		w.mu.Lock()
+		w.position = &pos
+		positionFn := w.onPositionChange
+		w.mu.Unlock()
+		if positionFn != nil {
+			positionFn(pos)
+		}
+	}
+
+	// Wait for window to close
+	if doneCh := w.release(); doneCh != nil {
+		select {
+		case <-doneCh:
+		case <-time.After(time.Second):
+		}
+	}
+}
+
+// release marks the window as closed and stops its redraw goroutine.
+// Returns the channel closed when the event loop exits, nil if the
+// window was not running.
+func (w *Window) release() chan struct{} {
 	w.mu.Lock()
 	if !w.running {
 		w.mu.Unlock()
-		return
+		return nil
 	}
 	w.running = false
 	stopCh := w.stopCh
@@ -138,14 +294,7 @@ func (w *Window) Hide() {
 	if stopCh != nil {
 		close(stopCh)
 	}
-
-	// Wait for window to close
-	if doneCh != nil {
-		select {
-		case <-doneCh:
-		case <-time.After(time.Second):
-		}
-	}
+	return doneCh
 }
 
 // SetStartTime updates the recording start time for the timer display.
@@ -165,40 +314,120 @@ func (w *Window) SetState(state State) {
 	}
 }
 
+// SetLowConfidence marks the next result as low-confidence: the result view
+// is tinted to hint the user to re-record.
+func (w *Window) SetLowConfidence(low bool) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.lowConfidence = low
+}
+
+// SetSelectAll makes the next result open with the editor focused and all
+// text selected, so the first keypress replaces it and Enter inserts.
+// Otherwise the editor is not focused and the caret is at the end.
+func (w *Window) SetSelectAll(on bool) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.selectAll = on
+}
+
+// SetTimings sets the phase durations shown under the next result.
+// Zero Timings hide the line.
+func (w *Window) SetTimings(t Timings) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.timings = t
+}
+
 // SetResult sets the recognition result and switches to result state.
 func (w *Window) SetResult(original, corrected string) {
 	w.mu.Lock()
 	defer w.mu.Unlock()
 
+	w.originalText = original
+	w.correctedText = corrected
+	w.showOriginal = false
+
 	// Use corrected text if available, otherwise original
 	result := corrected
 	if result == "" {
 		result = original
 	}
-	w.resultText = result
 
-	// Initialize editor with result text
+	// Initialize editor with result text. A focused editor would take Enter
+	// as a newline, so with select-all it submits instead (Shift+Enter
+	// still breaks the line)
 	w.editor = widget.Editor{
 		SingleLine: false,
-		Submit:     false,
+		Submit:     w.selectAll,
 	}
 	w.editor.SetText(result)
+	if w.selectAll {
+		w.editor.SetCaret(w.editor.Len(), 0)
+		w.focusEditor = true
+	} else {
+		w.editor.SetCaret(w.editor.Len(), w.editor.Len())
+	}
 
 	w.state = StateResult
 	if w.window != nil {
-		w.window.Option(app.Size(unit.Dp(450), unit.Dp(220)))
+		w.window.Option(app.Size(unit.Dp(resultWidth), unit.Dp(resultHeight)))
 		w.window.Invalidate()
 	}
+	// The result needs clicks (tabs, editor, buttons)
+	if w.clickThrough {
+		go w.updateClickThrough()
+	}
 }
 
 // ClearResult clears the stored result text.
 func (w *Window) ClearResult() {
 	w.mu.Lock()
 	defer w.mu.Unlock()
-	w.resultText = ""
+	w.originalText = ""
+	w.correctedText = ""
+	w.showOriginal = false
+	w.lowConfidence = false
+	w.timings = Timings{}
+	w.partialText = ""
 	w.editor.SetText("")
 }
 
+// SetPartial sets the live text shown under the waveform while recording
+// and under the spinner during chunked recognition.
+// The window redraws on its own ticker, so no invalidation is needed.
+func (w *Window) SetPartial(text string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.partialText = text
+}
+
+// hasVariants reports whether both original and corrected text can be shown.
+// Must be called with w.mu held.
+func (w *Window) hasVariants() bool {
+	return w.correctedText != "" && w.correctedText != w.originalText
+}
+
+// selectVariant switches the editor between original and corrected text,
+// keeping edits made to the variant being left.
+func (w *Window) selectVariant(original bool) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if !w.hasVariants() || w.showOriginal == original {
+		return
+	}
+
+	if w.showOriginal {
+		w.originalText = w.editor.Text()
+		w.editor.SetText(w.correctedText)
+	} else {
+		w.correctedText = w.editor.Text()
+		w.editor.SetText(w.originalText)
+	}
+	w.showOriginal = original
+}
+
 // OnInsert sets the callback for when insert button is clicked (or Enter pressed).
 func (w *Window) OnInsert(fn func(text string)) {
 	w.mu.Lock()
@@ -220,6 +449,104 @@ func (w *Window) OnCancel(fn func()) {
 	w.onCancel = fn
 }
 
+// OnRedo sets the callback for the "Record again" button and Ctrl+Backspace
+// (Cmd+Backspace on macOS) in the result view: the result is discarded and
+// recording starts again. The window stays open; the callback is expected to
+// call Show to switch it back to recording. While the editor has focus the
+// keys delete a word as usual, so the button is the way to redo then.
+func (w *Window) OnRedo(fn func()) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.onRedo = fn
+}
+
+// SetPosition sets the position the window is shown at.
+func (w *Window) SetPosition(pos image.Point) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.position = &pos
+}
+
+// ResetPosition makes the window appear in the bottom-right corner again.
+func (w *Window) ResetPosition() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.position = nil
+}
+
+// OnPositionChange sets the callback for when the window position is captured on hide.
+func (w *Window) OnPositionChange(fn func(pos image.Point)) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.onPositionChange = fn
+}
+
+// SetSize sets the recording window size. A visible window is resized
+// right away unless it shows the result, which has its own size.
+func (w *Window) SetSize(s Size) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.size = s
+	w.config.ApplySize(s)
+	if w.window != nil && w.state != StateResult {
+		w.window.Option(app.Size(unit.Dp(w.config.Width), unit.Dp(w.config.Height)))
+		w.window.Invalidate()
+	}
+}
+
+// OnSizeChange sets the callback for when the user cycles the window size
+// with Tab, so the choice can be persisted.
+func (w *Window) OnSizeChange(fn func(Size)) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.onSizeChange = fn
+}
+
+// SetNoStealFocus makes the window open without taking keyboard focus from
+// the window the user works in; it gets focus once clicked, and only then
+// Enter and Esc reach it. Applies to the next opened window. X11 only.
+func (w *Window) SetNoStealFocus(enabled bool) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.noStealFocus = enabled
+}
+
+// SetClickThrough makes the window transparent to mouse clicks while
+// recording, so it never steals clicks from the window below. Normal input
+// is restored automatically when the result is shown. Linux (X11) only.
+func (w *Window) SetClickThrough(enabled bool) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.clickThrough == enabled {
+		return
+	}
+	w.clickThrough = enabled
+	if w.running {
+		go w.updateClickThrough()
+	}
+}
+
+// updateClickThrough applies the input mode matching the current state.
+func (w *Window) updateClickThrough() {
+	w.clickMu.Lock()
+	defer w.clickMu.Unlock()
+
+	w.mu.Lock()
+	enabled := w.clickThrough && w.state != StateResult
+	w.mu.Unlock()
+	setClickThrough(windowTitle, enabled)
+}
+
+// SetPalette switches the window colors to the given theme palette.
+func (w *Window) SetPalette(p theme.Palette) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.config.ApplyPalette(p)
+	if w.window != nil {
+		w.window.Invalidate()
+	}
+}
+
 // IsVisible returns true if window is currently shown.
 func (w *Window) IsVisible() bool {
 	w.mu.Lock()
@@ -232,18 +559,38 @@ const windowTitle = "Shofar - Запись"
 func (w *Window) runEventLoop() {
 	defer close(w.doneCh)
 
+	// Remember the focused window before ours appears
+	restoreFocus := func(string) {}
+	w.mu.Lock()
+	if w.noStealFocus {
+		restoreFocus = focus.Keep()
+	}
+	// ShowResult opens the window with the result already set
+	width, height := w.config.Width, w.config.Height
+	if w.state == StateResult {
+		width, height = resultWidth, resultHeight
+	}
+	w.mu.Unlock()
+
 	// Create window with options
 	w.window = new(app.Window)
 	w.window.Option(
 		app.Title(windowTitle),
-		app.Size(unit.Dp(w.config.Width), unit.Dp(w.config.Height)),
+		app.Size(unit.Dp(width), unit.Dp(height)),
 		app.Decorated(false), // Borderless
 	)
 
 	var ops op.Ops
 
 	// Position window after it appears
-	go positionWindow(windowTitle, w.config.Width, w.config.Height)
+	w.mu.Lock()
+	pos := w.position
+	w.mu.Unlock()
+	go func() {
+		positionWindow(windowTitle, width, height, pos)
+		restoreFocus(windowTitle)
+		w.updateClickThrough()
+	}()
 
 	// Timer for periodic redraws
 	ticker := time.NewTicker(w.config.RefreshRate)
@@ -270,6 +617,9 @@ func (w *Window) runEventLoop() {
 	for {
 		switch e := w.window.Event().(type) {
 		case app.DestroyEvent:
+			// Closed by the window manager (e.g. Alt+F4) rather than
+			// Hide: the next Show must open a new window
+			w.release()
 			return
 		case app.FrameEvent:
 			gtx := app.NewContext(&ops, e)
@@ -278,16 +628,17 @@ func (w *Window) runEventLoop() {
 			w.mu.Lock()
 			startTime := w.startTime
 			state := w.state
+			cfg := w.config
 			w.mu.Unlock()
 
 			// Draw the visualization
-			w.draw(gtx, startTime, state)
+			w.draw(gtx, startTime, state, cfg)
 			e.Frame(gtx.Ops)
 		}
 	}
 }
 
-func (w *Window) draw(gtx layout.Context, startTime time.Time, state State) image.Point {
+func (w *Window) draw(gtx layout.Context, startTime time.Time, state State, cfg Config) image.Point {
 	// Handle ESC key to cancel and close window
 	for {
 		event, ok := gtx.Event(key.Filter{Name: key.NameEscape})
@@ -310,16 +661,62 @@ func (w *Window) draw(gtx layout.Context, startTime time.Time, state State) imag
 
 	switch state {
 	case StateSpeechProcess:
-		return drawProcessingStage(gtx, elapsed, w.config, i18n.T("waveform_speech_processing"), i18n.T("waveform_speech_hint"))
+		// Text recognized so far (chunked recognition) replaces the hint
+		subtitle := i18n.T("waveform_speech_hint")
+		w.mu.Lock()
+		if w.partialText != "" {
+			subtitle = partialTail(w.partialText, maxPartialRunes)
+		}
+		w.mu.Unlock()
+		return drawProcessingStage(gtx, elapsed, cfg, i18n.T("waveform_speech_processing"), subtitle)
 	case StateLLMProcess:
-		return drawProcessingStage(gtx, elapsed, w.config, i18n.T("waveform_llm_processing"), i18n.T("waveform_llm_hint"))
+		return drawProcessingStage(gtx, elapsed, cfg, i18n.T("waveform_llm_processing"), i18n.T("waveform_llm_hint"))
+	case StateModelLoading:
+		return drawProcessingStage(gtx, elapsed, cfg, i18n.T("waveform_loading"), i18n.T("waveform_loading_hint"))
 	case StateResult:
 		w.mu.Lock()
 		insertCallback := w.onInsert
 		copyCallback := w.onCopy
 		cancelCallback := w.onCancel
+		redoCallback := w.onRedo
+		focusEditor := w.focusEditor
+		w.focusEditor = false
 		w.mu.Unlock()
 
+		if focusEditor {
+			gtx.Execute(key.FocusCmd{Tag: &w.editor})
+		}
+
+		// Handle Ctrl+Backspace to discard the result and record again
+		for {
+			event, ok := gtx.Event(key.Filter{Name: key.NameDeleteBackward, Required: key.ModShortcut})
+			if !ok {
+				break
+			}
+			if e, ok := event.(key.Event); ok && e.State == key.Press && redoCallback != nil {
+				go redoCallback()
+				return gtx.Constraints.Max
+			}
+		}
+
+		// Enter in the focused editor arrives as a submit (select-all mode)
+		for {
+			event, ok := w.editor.Update(gtx)
+			if !ok {
+				break
+			}
+			if _, ok := event.(widget.SubmitEvent); ok {
+				if insertCallback != nil {
+					text := w.editor.Text()
+					go func() {
+						insertCallback(text)
+					}()
+				}
+				go w.Hide()
+				return gtx.Constraints.Max
+			}
+		}
+
 		// Handle Enter key for insert
 		for {
 			event, ok := gtx.Event(key.Filter{Name: key.NameReturn})
@@ -349,6 +746,10 @@ func (w *Window) draw(gtx layout.Context, startTime time.Time, state State) imag
 			copyCallback(w.editor.Text())
 			go w.Hide()
 		}
+		if w.redoBtn.Clicked(gtx) && redoCallback != nil {
+			go redoCallback()
+			return gtx.Constraints.Max
+		}
 		if w.closeBtn.Clicked(gtx) {
 			if cancelCallback != nil {
 				go cancelCallback()
@@ -356,14 +757,59 @@ func (w *Window) draw(gtx layout.Context, startTime time.Time, state State) imag
 			go w.Hide()
 		}
 
-		return drawResultView(gtx, w.config, &w.editor, &w.insertBtn, &w.copyBtn, &w.closeBtn)
+		// Handle original/corrected toggle
+		if w.originalTab.Clicked(gtx) {
+			w.selectVariant(true)
+		}
+		if w.correctedTab.Clicked(gtx) {
+			w.selectVariant(false)
+		}
+
+		w.mu.Lock()
+		lowConfidence := w.lowConfidence
+		timings := w.timings.String()
+		variants := resultVariants{
+			Available:    w.hasVariants(),
+			ShowOriginal: w.showOriginal,
+			OriginalTab:  &w.originalTab,
+			CorrectedTab: &w.correctedTab,
+		}
+		w.mu.Unlock()
+
+		var redoBtn *widget.Clickable
+		if redoCallback != nil {
+			redoBtn = &w.redoBtn
+		}
+		return drawResultView(gtx, cfg, &w.editor, variants, lowConfidence, timings, &w.insertBtn, &w.copyBtn, redoBtn, &w.closeBtn)
 	default:
+		// Handle Tab to cycle the window size
+		for {
+			event, ok := gtx.Event(key.Filter{Name: key.NameTab})
+			if !ok {
+				break
+			}
+			if e, ok := event.(key.Event); ok && e.State == key.Press {
+				w.mu.Lock()
+				next := w.size.Next()
+				sizeFn := w.onSizeChange
+				w.mu.Unlock()
+				w.SetSize(next)
+				if sizeFn != nil {
+					go sizeFn(next)
+				}
+			}
+		}
+
 		// Get samples from provider
 		var samples []float32
 		if w.provider != nil {
 			samples = w.provider.GetSamples()
 		}
+		tooLoud := w.provider != nil && w.provider.ClipRatio() > clipWarnRatio
+		w.mu.Lock()
+		partial := w.partialText
+		w.mu.Unlock()
 		// Draw recording visualization
-		return drawVisualization(gtx, samples, elapsed, w.config)
+		return drawVisualization(gtx, samples, partial, tooLoud, elapsed, cfg)
 	}
 }
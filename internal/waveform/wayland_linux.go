@@ -0,0 +1,13 @@
+//go:build linux && !nowayland
+
+package waveform
+
+import "os"
+
+// waylandSession reports whether the window is a native Wayland surface.
+// Gio prefers Wayland whenever a compositor is available, and Wayland
+// clients can neither place their own windows nor keep them on top, so
+// the X11 tools used for positioning have nothing to act on.
+func waylandSession() bool {
+	return os.Getenv("WAYLAND_DISPLAY") != ""
+}
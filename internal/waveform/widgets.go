@@ -5,6 +5,7 @@ import (
 	"image"
 	"image/color"
 	"math"
+	"strings"
 	"time"
 
 	"gioui.org/f32"
@@ -21,7 +22,11 @@ import (
 )
 
 // drawVisualization draws the complete visualization during recording.
-func drawVisualization(gtx layout.Context, samples []float32, elapsed time.Duration, cfg Config) image.Point {
+// clipWarnRatio is the share of clipped samples above which the recording
+// window warns that the microphone is too loud.
+const clipWarnRatio = 0.002
+
+func drawVisualization(gtx layout.Context, samples []float32, partial string, tooLoud bool, elapsed time.Duration, cfg Config) image.Point {
 	// Fill background
 	drawBackground(gtx, cfg.BGColor)
 
@@ -48,6 +53,15 @@ func drawVisualization(gtx layout.Context, samples []float32, elapsed time.Durat
 					layout.Flexed(1, func(gtx layout.Context) layout.Dimensions {
 						return layout.Dimensions{}
 					}),
+					// Clipping warning
+					layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+						if !tooLoud {
+							return layout.Dimensions{}
+						}
+						return layout.Inset{Right: unit.Dp(6)}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+							return drawClipBadge(gtx, cfg)
+						})
+					}),
 					// Timer
 					layout.Rigid(func(gtx layout.Context) layout.Dimensions {
 						return drawTimerBadge(gtx, elapsed, cfg)
@@ -61,12 +75,43 @@ func drawVisualization(gtx layout.Context, samples []float32, elapsed time.Durat
 			layout.Flexed(1, func(gtx layout.Context) layout.Dimensions {
 				return drawWaveformPanel(gtx, samples, cfg)
 			}),
+
+			// Live recognition text, newest words visible
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				if partial == "" {
+					return layout.Dimensions{}
+				}
+				return layout.Inset{Top: unit.Dp(6)}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+					th := material.NewTheme()
+					th.Palette.Fg = cfg.TextDimColor
+					lbl := material.Label(th, unit.Sp(12), partialTail(partial, maxPartialRunes))
+					lbl.MaxLines = 1
+					return lbl.Layout(gtx)
+				})
+			}),
 		)
 	})
 
 	return gtx.Constraints.Max
 }
 
+// maxPartialRunes is how much of the live text fits the recording window.
+const maxPartialRunes = 50
+
+// partialTail returns the last n runes of text, cut at a word boundary
+// and prefixed with an ellipsis when shortened.
+func partialTail(text string, n int) string {
+	runes := []rune(text)
+	if len(runes) <= n {
+		return text
+	}
+	tail := string(runes[len(runes)-n:])
+	if i := strings.IndexByte(tail, ' '); i >= 0 && i < len(tail)-1 {
+		tail = tail[i+1:]
+	}
+	return "…" + tail
+}
+
 // drawBackground draws a rectangle background.
 func drawBackground(gtx layout.Context, col color.NRGBA) {
 	rect := clip.Rect{Max: gtx.Constraints.Max}
@@ -93,6 +138,32 @@ func drawRecordingDot(gtx layout.Context, elapsed time.Duration, col color.NRGBA
 	return layout.Dimensions{Size: image.Pt(size, size+center/2)}
 }
 
+// drawClipBadge draws the "too loud" warning shown while the input clips.
+func drawClipBadge(gtx layout.Context, cfg Config) layout.Dimensions {
+	macro := op.Record(gtx.Ops)
+	dims := layout.Inset{
+		Top: unit.Dp(4), Bottom: unit.Dp(4),
+		Left: unit.Dp(8), Right: unit.Dp(8),
+	}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+		th := material.NewTheme()
+		th.Palette.Fg = cfg.VolumeColor
+		lbl := material.Label(th, unit.Sp(11), i18n.T("waveform_too_loud"))
+		lbl.Font.Weight = font.Medium
+		return lbl.Layout(gtx)
+	})
+	call := macro.Stop()
+
+	rr := gtx.Dp(unit.Dp(6))
+	rect := clip.RRect{
+		Rect: image.Rectangle{Max: dims.Size},
+		NE:   rr, NW: rr, SE: rr, SW: rr,
+	}
+	paint.FillShape(gtx.Ops, cfg.PanelColor, rect.Op(gtx.Ops))
+
+	call.Add(gtx.Ops)
+	return dims
+}
+
 // drawTimerBadge draws the elapsed time in a badge.
 func drawTimerBadge(gtx layout.Context, elapsed time.Duration, cfg Config) layout.Dimensions {
 	seconds := int(elapsed.Seconds())
@@ -182,6 +253,12 @@ func calculateRMS(samples []float32) float32 {
 	return level
 }
 
+// DrawVolumeBar renders the vertical volume indicator used by the waveform
+// window, so other windows can show the same microphone level.
+func DrawVolumeBar(gtx layout.Context, samples []float32, cfg Config) layout.Dimensions {
+	return drawVolumeBar(gtx, samples, cfg)
+}
+
 // drawVolumeBar renders vertical volume indicator.
 func drawVolumeBar(gtx layout.Context, samples []float32, cfg Config) layout.Dimensions {
 	level := calculateRMS(samples)
@@ -194,7 +271,7 @@ func drawVolumeBar(gtx layout.Context, samples []float32, cfg Config) layout.Dim
 		Rect: image.Rectangle{Max: image.Pt(width, height)},
 		NE:   rr, NW: rr, SE: rr, SW: rr,
 	}
-	paint.FillShape(gtx.Ops, color.NRGBA{R: 35, G: 35, B: 40, A: 255}, bgRect.Op(gtx.Ops))
+	paint.FillShape(gtx.Ops, cfg.TrackColor, bgRect.Op(gtx.Ops))
 
 	// Active bar (from bottom)
 	barHeight := int(level * float32(height))
@@ -302,6 +379,7 @@ func drawProcessingStage(gtx layout.Context, elapsed time.Duration, cfg Config,
 						th := material.NewTheme()
 						th.Palette.Fg = cfg.TextDimColor
 						lbl := material.Label(th, unit.Sp(11), subtitle)
+						lbl.MaxLines = 1
 						return lbl.Layout(gtx)
 					}),
 				)
@@ -349,14 +427,31 @@ func drawModernSpinner(gtx layout.Context, elapsed time.Duration, col color.NRGB
 	return layout.Dimensions{Size: image.Pt(size, size)}
 }
 
+// resultVariants describes the original/corrected toggle of the result view.
+type resultVariants struct {
+	Available    bool // both original and LLM-corrected text exist
+	ShowOriginal bool // editor currently shows the original text
+	OriginalTab  *widget.Clickable
+	CorrectedTab *widget.Clickable
+}
+
 // drawResultView draws the recognition result with editable text and action buttons.
-func drawResultView(gtx layout.Context, cfg Config, editor *widget.Editor, insertBtn, copyBtn, closeBtn *widget.Clickable) image.Point {
+// With lowConfidence the indicator and title warn that the result may be wrong.
+// A non-empty timings line is shown dimmed under the editor. A nil redoBtn
+// hides the "Record again" button.
+func drawResultView(gtx layout.Context, cfg Config, editor *widget.Editor, variants resultVariants, lowConfidence bool, timings string, insertBtn, copyBtn, redoBtn, closeBtn *widget.Clickable) image.Point {
 	// Fill background
 	drawBackground(gtx, cfg.BGColor)
 
 	// Colors
 	successColor := color.NRGBA{R: 80, G: 200, B: 120, A: 255}
 	secondaryColor := cfg.AccentColor
+	indicatorColor := successColor
+	title := i18n.T("waveform_result")
+	if lowConfidence {
+		indicatorColor = color.NRGBA{R: 255, G: 180, B: 0, A: 255}
+		title = i18n.T("waveform_low_confidence")
+	}
 
 	// Main content with padding
 	layout.UniformInset(unit.Dp(16)).Layout(gtx, func(gtx layout.Context) layout.Dimensions {
@@ -366,14 +461,14 @@ func drawResultView(gtx layout.Context, cfg Config, editor *widget.Editor, inser
 				return layout.Flex{Axis: layout.Horizontal, Alignment: layout.Middle}.Layout(gtx,
 					// Success indicator
 					layout.Rigid(func(gtx layout.Context) layout.Dimensions {
-						return drawSuccessIcon(gtx, successColor)
+						return drawSuccessIcon(gtx, indicatorColor)
 					}),
 					layout.Rigid(layout.Spacer{Width: unit.Dp(10)}.Layout),
 					// Title
 					layout.Rigid(func(gtx layout.Context) layout.Dimensions {
 						th := material.NewTheme()
 						th.Palette.Fg = cfg.TextColor
-						lbl := material.Label(th, unit.Sp(18), i18n.T("waveform_result"))
+						lbl := material.Label(th, unit.Sp(18), title)
 						lbl.Font.Weight = font.Medium
 						return lbl.Layout(gtx)
 					}),
@@ -381,6 +476,22 @@ func drawResultView(gtx layout.Context, cfg Config, editor *widget.Editor, inser
 					layout.Flexed(1, func(gtx layout.Context) layout.Dimensions {
 						return layout.Dimensions{}
 					}),
+					// Original/corrected toggle
+					layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+						if !variants.Available {
+							return layout.Dimensions{}
+						}
+						return layout.Flex{Axis: layout.Horizontal, Alignment: layout.Middle}.Layout(gtx,
+							layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+								return drawVariantTab(gtx, variants.OriginalTab, cfg, i18n.T("waveform_original"), variants.ShowOriginal)
+							}),
+							layout.Rigid(layout.Spacer{Width: unit.Dp(4)}.Layout),
+							layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+								return drawVariantTab(gtx, variants.CorrectedTab, cfg, i18n.T("waveform_corrected"), !variants.ShowOriginal)
+							}),
+							layout.Rigid(layout.Spacer{Width: unit.Dp(10)}.Layout),
+						)
+					}),
 					// Close button
 					layout.Rigid(func(gtx layout.Context) layout.Dimensions {
 						return drawCloseButton(gtx, closeBtn, cfg.TextDimColor)
@@ -395,11 +506,23 @@ func drawResultView(gtx layout.Context, cfg Config, editor *widget.Editor, inser
 				return drawEditorPanel(gtx, cfg, editor)
 			}),
 
+			// Phase durations
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				if timings == "" {
+					return layout.Dimensions{}
+				}
+				return layout.Inset{Top: unit.Dp(6)}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+					th := material.NewTheme()
+					th.Palette.Fg = cfg.TextDimColor
+					return material.Label(th, unit.Sp(11), timings).Layout(gtx)
+				})
+			}),
+
 			layout.Rigid(layout.Spacer{Height: unit.Dp(12)}.Layout),
 
-			// Two buttons row
+			// Buttons row
 			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
-				return layout.Flex{Axis: layout.Horizontal, Spacing: layout.SpaceEvenly}.Layout(gtx,
+				buttons := []layout.FlexChild{
 					// Insert button (primary)
 					layout.Flexed(1, func(gtx layout.Context) layout.Dimensions {
 						return drawActionButton(gtx, insertBtn, cfg, successColor, i18n.T("waveform_insert"), true)
@@ -409,7 +532,17 @@ func drawResultView(gtx layout.Context, cfg Config, editor *widget.Editor, inser
 					layout.Flexed(1, func(gtx layout.Context) layout.Dimensions {
 						return drawActionButton(gtx, copyBtn, cfg, secondaryColor, i18n.T("waveform_copy"), false)
 					}),
-				)
+				}
+				if redoBtn != nil {
+					buttons = append(buttons,
+						layout.Rigid(layout.Spacer{Width: unit.Dp(10)}.Layout),
+						// Record again button (secondary)
+						layout.Flexed(1, func(gtx layout.Context) layout.Dimensions {
+							return drawActionButton(gtx, redoBtn, cfg, secondaryColor, i18n.T("waveform_redo"), false)
+						}),
+					)
+				}
+				return layout.Flex{Axis: layout.Horizontal, Spacing: layout.SpaceEvenly}.Layout(gtx, buttons...)
 			}),
 		)
 	})
@@ -417,6 +550,41 @@ func drawResultView(gtx layout.Context, cfg Config, editor *widget.Editor, inser
 	return gtx.Constraints.Max
 }
 
+// drawVariantTab draws a small pill button of the original/corrected toggle.
+func drawVariantTab(gtx layout.Context, btn *widget.Clickable, cfg Config, text string, selected bool) layout.Dimensions {
+	return btn.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+		bgColor := cfg.PanelColor
+		textColor := cfg.TextDimColor
+		if selected {
+			bgColor = cfg.AccentColor
+			textColor = cfg.TextColor
+		} else if btn.Hovered() {
+			textColor = cfg.TextColor
+		}
+
+		macro := op.Record(gtx.Ops)
+		dims := layout.Inset{
+			Top: unit.Dp(4), Bottom: unit.Dp(4),
+			Left: unit.Dp(8), Right: unit.Dp(8),
+		}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+			th := material.NewTheme()
+			th.Palette.Fg = textColor
+			return material.Label(th, unit.Sp(12), text).Layout(gtx)
+		})
+		call := macro.Stop()
+
+		rr := gtx.Dp(unit.Dp(10))
+		rect := clip.RRect{
+			Rect: image.Rectangle{Max: dims.Size},
+			NE:   rr, NW: rr, SE: rr, SW: rr,
+		}
+		paint.FillShape(gtx.Ops, bgColor, rect.Op(gtx.Ops))
+
+		call.Add(gtx.Ops)
+		return dims
+	})
+}
+
 // drawSuccessIcon draws a checkmark icon.
 func drawSuccessIcon(gtx layout.Context, col color.NRGBA) layout.Dimensions {
 	size := gtx.Dp(unit.Dp(20))
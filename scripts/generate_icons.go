@@ -2,6 +2,10 @@
 
 // Скрипт для генерации иконок трея.
 // Запуск: go run scripts/generate_icons.go
+//
+// Создаёт два набора: цветной и монохромный (template) для строки меню
+// macOS - система сама перекрашивает его под светлое и тёмное оформление,
+// поэтому состояния в нём различаются формой, а не цветом.
 package main
 
 import (
@@ -13,6 +17,23 @@ import (
 	"path/filepath"
 )
 
+const (
+	size    = 64
+	centerX = size / 2
+	centerY = size / 2
+	radius  = 20
+)
+
+var (
+	black = color.RGBA{0, 0, 0, 255}
+	blue  = color.RGBA{90, 150, 255, 255}
+	red   = color.RGBA{220, 50, 50, 255}
+	gray  = color.RGBA{128, 128, 128, 255}
+	// Приглушённые цвета: 40% непрозрачности
+	dimGray  = color.NRGBA{128, 128, 128, 102}
+	dimBlack = color.NRGBA{0, 0, 0, 102}
+)
+
 func main() {
 	dir := "embedded"
 	if len(os.Args) > 1 {
@@ -24,55 +45,152 @@ func main() {
 	}
 
 	icons := []struct {
-		name  string
-		color color.RGBA
+		name string
+		draw func(img *image.NRGBA)
 	}{
-		{"icon_idle.png", color.RGBA{128, 128, 128, 255}},      // Серый
-		{"icon_recording.png", color.RGBA{220, 50, 50, 255}},   // Красный
-		{"icon_processing.png", color.RGBA{230, 160, 50, 255}}, // Оранжевый
+		// Цветной набор
+		{"icon_idle.png", mic(color.RGBA{128, 128, 128, 255})},      // Серый
+		{"icon_recording.png", mic(color.RGBA{220, 50, 50, 255})},   // Красный
+		{"icon_processing.png", mic(color.RGBA{230, 160, 50, 255})}, // Оранжевый
+		{"icon_paused.png", paused(dimGray, blue)},                  // Приглушённый, синяя пауза
+		{"icon_loading.png", loading(dimGray, blue)},                // Приглушённый, синие точки
+		{"icon_error.png", failed(gray, red)},                       // Серый, красный знак "!"
+
+		// Template-набор
+		{"icon_idle_template.png", mic(black)},                  // Микрофон
+		{"icon_recording_template.png", recording(black)},       // Микрофон в кольце
+		{"icon_processing_template.png", outline(black)},        // Контур микрофона
+		{"icon_paused_template.png", paused(dimBlack, black)},   // Приглушённый, с паузой
+		{"icon_loading_template.png", loading(dimBlack, black)}, // Приглушённый, с точками
+		{"icon_error_template.png", failed(dimBlack, black)},    // Приглушённый, со знаком "!"
 	}
 
 	for _, icon := range icons {
 		path := filepath.Join(dir, icon.name)
-		if err := generateIcon(path, icon.color); err != nil {
+		if err := generateIcon(path, icon.draw); err != nil {
 			log.Fatalf("Ошибка генерации %s: %v", icon.name, err)
 		}
 		log.Printf("Создан: %s", path)
 	}
 }
 
-func generateIcon(path string, c color.RGBA) error {
-	const size = 64
-	img := image.NewRGBA(image.Rect(0, 0, size, size))
+func generateIcon(path string, draw func(img *image.NRGBA)) error {
+	img := image.NewNRGBA(image.Rect(0, 0, size, size))
+	draw(img)
 
-	// Рисуем круг (микрофон упрощённо)
-	centerX, centerY := size/2, size/2
-	radius := 20.0
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	return png.Encode(f, img)
+}
 
+// mic рисует упрощённый микрофон: круг и ножку.
+func mic(c color.Color) func(img *image.NRGBA) {
+	return func(img *image.NRGBA) {
+		ring(img, 0, radius, c)
+		stem(img, c)
+	}
+}
+
+// recording рисует микрофон, обведённый кольцом.
+func recording(c color.Color) func(img *image.NRGBA) {
+	return func(img *image.NRGBA) {
+		mic(c)(img)
+		ring(img, 25, 29, c)
+	}
+}
+
+// outline рисует только контур круга микрофона с ножкой.
+func outline(c color.Color) func(img *image.NRGBA) {
+	return func(img *image.NRGBA) {
+		ring(img, radius-5, radius, c)
+		stem(img, c)
+	}
+}
+
+// paused рисует приглушённый микрофон со знаком паузы в правом нижнем углу.
+func paused(micColor, pauseColor color.Color) func(img *image.NRGBA) {
+	return func(img *image.NRGBA) {
+		mic(micColor)(img)
+		for _, x0 := range []int{36, 52} {
+			for y := 32; y < 60; y++ {
+				for x := x0; x < x0+8; x++ {
+					img.Set(x, y, pauseColor)
+				}
+			}
+		}
+	}
+}
+
+// loading рисует приглушённый микрофон с тремя точками внизу,
+// как у индикатора ожидания.
+func loading(micColor, dotColor color.Color) func(img *image.NRGBA) {
+	return func(img *image.NRGBA) {
+		mic(micColor)(img)
+		for _, cx := range []int{12, 32, 52} {
+			for y := 52; y < 64; y++ {
+				for x := cx - 6; x < cx+6; x++ {
+					dx, dy := x-cx, y-58
+					if dx*dx+dy*dy <= 36 {
+						img.Set(x, y, dotColor)
+					}
+				}
+			}
+		}
+	}
+}
+
+// failed рисует микрофон с кругом в правом нижнем углу, в котором
+// прорезан знак "!".
+func failed(micColor, markColor color.Color) func(img *image.NRGBA) {
+	return func(img *image.NRGBA) {
+		mic(micColor)(img)
+		const cx, cy, r = 47, 47, 16
+		for y := cy - r; y <= cy+r && y < size; y++ {
+			for x := cx - r; x <= cx+r && x < size; x++ {
+				dx, dy := x-cx, y-cy
+				if dx*dx+dy*dy <= r*r {
+					img.Set(x, y, markColor)
+				}
+			}
+		}
+		// Знак прорезается насквозь, чтобы читался и в template-варианте
+		transparent := color.NRGBA{}
+		for y := cy - 10; y <= cy+10; y++ {
+			if y > cy+3 && y < cy+7 {
+				continue
+			}
+			for x := cx - 2; x <= cx+2; x++ {
+				img.Set(x, y, transparent)
+			}
+		}
+	}
+}
+
+// ring закрашивает кольцо между радиусами inner и outer (inner=0 - круг).
+func ring(img *image.NRGBA, inner, outer float64, c color.Color) {
 	for y := 0; y < size; y++ {
 		for x := 0; x < size; x++ {
 			dx := float64(x - centerX)
 			dy := float64(y - centerY)
-			if dx*dx+dy*dy <= radius*radius {
+			d := dx*dx + dy*dy
+			if d <= outer*outer && (inner == 0 || d > inner*inner) {
 				img.Set(x, y, c)
 			}
 		}
 	}
+}
 
-	// Рисуем ножку микрофона
-	for y := centerY + int(radius); y < centerY+int(radius)+10; y++ {
+// stem рисует ножку микрофона.
+func stem(img *image.NRGBA, c color.Color) {
+	for y := centerY + radius; y < centerY+radius+10; y++ {
 		for x := centerX - 3; x <= centerX+3; x++ {
 			if y < size {
 				img.Set(x, y, c)
 			}
 		}
 	}
-
-	f, err := os.Create(path)
-	if err != nil {
-		return err
-	}
-	defer f.Close()
-
-	return png.Encode(f, img)
 }